@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"meal-compass/backend/internal/adapter/recipeio"
+	"meal-compass/backend/internal/adapter/schemaorg"
+	"meal-compass/backend/internal/usecase"
+)
+
+// runImport は、import サブコマンドを実行します。
+// ファイル名に "-" を指定すると標準入力から読み込みます。
+func runImport(ctx context.Context, catalogUsecase usecase.CatalogUsecase, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	formatFlag := fs.String("format", "", "ドキュメントの形式 (yaml, json, csv)。省略時はファイルの拡張子から判定します")
+	strict := fs.Bool("strict", false, "未登録の食材を作成せず、エラーとして報告します")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("取り込むファイルを1つ指定してください")
+	}
+	path := fs.Arg(0)
+
+	format, err := cliFormat(*formatFlag, path)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	catalog, err := recipeio.Decode(r, format)
+	if err != nil {
+		return err
+	}
+
+	output, err := catalogUsecase.ImportRecipes(ctx, usecase.ImportRecipesInput{Catalog: catalog, Strict: *strict})
+	if err != nil {
+		return err
+	}
+	if !output.Applied {
+		for _, rowErr := range output.Errors {
+			fmt.Fprintf(os.Stderr, "%d行目 %s %s: %s\n", rowErr.Row, rowErr.Menu, rowErr.Ingredient, rowErr.Message)
+		}
+		return fmt.Errorf("%d件のエラーがあったため、取り込みを中止しました", len(output.Errors))
+	}
+
+	fmt.Printf("メニュー: 作成 %d件 / 更新 %d件, 食材: 作成 %d件\n", output.CreatedMenus, output.UpdatedMenus, len(output.CreatedIngredients))
+	return nil
+}
+
+// runExport は、export サブコマンドを実行します。
+func runExport(ctx context.Context, catalogUsecase usecase.CatalogUsecase, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	formatFlag := fs.String("format", "", "ドキュメントの形式 (yaml, json, csv)。省略時は出力先の拡張子、それもなければYAML")
+	out := fs.String("o", "-", "出力先のファイル。\"-\" の場合は標準出力")
+	_ = fs.Parse(args)
+
+	format, err := cliFormat(*formatFlag, *out)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := catalogUsecase.ExportRecipes(ctx)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return recipeio.Encode(w, format, catalog)
+}
+
+// runDraft は、draft サブコマンドを実行します。
+// 保存したレシピページから下書きをYAMLで書き出し、対応付けられなかった材料行は標準エラー出力に表示します。
+// 書き出した内容を確認・修正したうえで import サブコマンドで登録します。
+func runDraft(ctx context.Context, catalogUsecase usecase.CatalogUsecase, args []string) error {
+	fs := flag.NewFlagSet("draft", flag.ExitOnError)
+	name := fs.String("name", "", "メニュー名。省略時はレシピの name を使います")
+	out := fs.String("o", "-", "出力先のファイル。\"-\" の場合は標準出力")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("HTMLまたはJSON-LDのファイルを1つ指定してください")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	recipe, err := schemaorg.ExtractRecipe(data)
+	if err != nil {
+		return err
+	}
+	if *name != "" {
+		recipe.Name = *name
+	}
+
+	draft, err := catalogUsecase.DraftRecipe(ctx, usecase.DraftRecipeInput{
+		Name:            recipe.Name,
+		SourceURL:       recipe.URL,
+		Yield:           recipe.Yield,
+		IngredientLines: recipe.Ingredients,
+		Steps:           recipe.Instructions,
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range draft.Unmatched {
+		fmt.Fprintf(os.Stderr, "未対応: %s (%s)\n", line.Raw, line.Reason)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return recipeio.Encode(w, recipeio.FormatYAML, draft.Catalog())
+}
+
+// cliFormat は、-format フラグ、またはファイルの拡張子から形式を決定します。
+func cliFormat(flagValue, path string) (recipeio.Format, error) {
+	if flagValue != "" {
+		return recipeio.ParseFormat(flagValue)
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+		return recipeio.ParseFormat(ext)
+	}
+	return recipeio.FormatYAML, nil
+}
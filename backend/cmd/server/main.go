@@ -1,19 +1,34 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"meal-compass/backend/internal/adapter/handler"
+	"meal-compass/backend/internal/adapter/icalendar"
+	"meal-compass/backend/internal/adapter/openapi"
 	"meal-compass/backend/internal/adapter/repository"
 	"meal-compass/backend/internal/config"
 	"meal-compass/backend/internal/seeder"
 	"meal-compass/backend/internal/usecase"
 )
 
+// main は、第1引数のサブコマンドに応じて処理を振り分けます。
+// サブコマンドが省略された場合は "serve" として APIサーバーを起動します。
+//
+//	server [serve]
+//	server import [-format yaml|json|csv] [-strict] <file>
+//	server export [-format yaml|json|csv] [-o file]
+//	server draft [-name menu] [-o file] <html|jsonld file>
+//	server seed [-dry-run]
+//	server retention [-archive-after-days N] [-delete-after-days N] [-dry-run]
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -26,22 +41,96 @@ func main() {
 	}
 	log.Println("データベースへの接続に成功しました。")
 
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	if command == "serve" {
+		runServer(cfg, db)
+		return
+	}
+
+	// CLIでは標準出力に結果を書き出すため、SQLログは出力しない
+	db = db.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+	catalogUsecase := usecase.NewCatalogUsecase(repository.NewMenuRepository(db), repository.NewIngredientRepository(db))
+
+	ctx := context.Background()
+	switch command {
+	case "import":
+		err = runImport(ctx, catalogUsecase, os.Args[2:])
+	case "export":
+		err = runExport(ctx, catalogUsecase, os.Args[2:])
+	case "draft":
+		err = runDraft(ctx, catalogUsecase, os.Args[2:])
+	case "seed":
+		err = runSeed(ctx, db, os.Args[2:])
+	case "retention":
+		planUsecase := usecase.NewPlanUsecase(repository.NewPlanRepository(db), repository.NewMenuRepository(db), repository.NewIngredientRepository(db), repository.NewStoreLayoutRepository(db), usecase.NewPlanEventBroker())
+		err = runRetention(ctx, planUsecase, cfg, os.Args[2:])
+	default:
+		log.Fatalf("不明なサブコマンドです: %s (serve, import, export, draft, seed, retention のいずれかを指定してください)", command)
+	}
+	if err != nil {
+		log.Fatalf("%sの実行に失敗しました: %v", command, err)
+	}
+}
+
+// runServer は、APIサーバーを起動します。
+func runServer(cfg *config.Config, db *gorm.DB) {
+	// 開発環境では起動のたびに初期データを最新の状態にする。本番環境では seed サブコマンドで実行する
 	if cfg.GinMode == "debug" {
-		if err := runSeeder(db); err != nil {
-			log.Fatalf("ダミーデータの投入に失敗しました: %v", err)
+		if _, err := seeder.Run(context.Background(), db, false); err != nil {
+			log.Fatalf("初期データの投入に失敗しました: %v", err)
 		}
+		log.Println("初期データの投入が正常に完了しました。")
+	}
+
+	// 持ち主のトークンが無いと、計画のIDを知っている誰もが計画を変更・削除できるため、開発環境以外では起動しない
+	if cfg.OwnerToken == "" {
+		if cfg.GinMode != "debug" {
+			log.Fatal("OWNER_TOKEN を設定してください")
+		}
+		log.Println("OWNER_TOKEN が設定されていないため、APIの呼び出し元を確かめません（開発環境のみ）")
 	}
 
 	planRepo := repository.NewPlanRepository(db)
 	menuRepo := repository.NewMenuRepository(db)
 	ingredientRepo := repository.NewIngredientRepository(db)
+	storeLayoutRepo := repository.NewStoreLayoutRepository(db)
+	shareTokenRepo := repository.NewShareTokenRepository(db)
+
+	planEvents := usecase.NewPlanEventBroker()
 
-	planUsecase := usecase.NewPlanUsecase(planRepo, menuRepo, ingredientRepo)
+	planUsecase := usecase.NewPlanUsecase(planRepo, menuRepo, ingredientRepo, storeLayoutRepo, planEvents)
+	catalogUsecase := usecase.NewCatalogUsecase(menuRepo, ingredientRepo)
+	storeLayoutUsecase := usecase.NewStoreLayoutUsecase(storeLayoutRepo, ingredientRepo)
+	shareUsecase := usecase.NewShareUsecase(planRepo, shareTokenRepo)
 
-	planHandler := handler.NewPlanHandler(planUsecase)
+	// 古い計画のアーカイブ・削除をバックグラウンドで定期的に行う
+	startRetentionJob(context.Background(), planUsecase, retentionPolicy(cfg), time.Duration(cfg.PlanRetentionIntervalMinutes)*time.Minute)
+
+	calendarOptions, err := icalendar.NewOptions(cfg.MealTimeMorning, cfg.MealTimeLunch, cfg.MealTimeDinner, cfg.MealDurationMinutes, cfg.CalendarTimeZone)
+	if err != nil {
+		log.Fatalf("カレンダーの設定が不正です: %v", err)
+	}
+
+	planHandler := handler.NewPlanHandler(planUsecase, calendarOptions)
 	ingredientHandler := handler.NewIngredientHandler(planUsecase)
+	catalogHandler := handler.NewCatalogHandler(catalogUsecase)
+	storeLayoutHandler := handler.NewStoreLayoutHandler(storeLayoutUsecase)
+	shareHandler := handler.NewShareHandler(shareUsecase, planUsecase)
 
-	router := handler.NewRouter(planHandler, ingredientHandler)
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("API仕様の読み込みに失敗しました: %v", err)
+	}
+
+	// 開発環境ではレスポンスもAPI仕様と照らし合わせ、一致しないものをログに出力する
+	router := handler.NewRouter(planHandler, ingredientHandler, catalogHandler, storeLayoutHandler, shareHandler, spec, cfg.GinMode == "debug", handler.NewOwnerAuth(cfg.OwnerToken))
+	if err := openapi.CheckRoutes(spec, router.Routes()); err != nil {
+		log.Fatal(err)
+	}
 
 	port := os.Getenv("GO_APP_PORT")
 	if port == "" {
@@ -55,16 +144,24 @@ func main() {
 	}
 }
 
-// runSeeder はダミーデータ投入処理を実行します。
-func runSeeder(db *gorm.DB) error {
-	// 依存関係を考慮し、Ingredient -> Menu の順で実行
-	if err := seeder.IngredientsSeeder(db); err != nil {
-		return fmt.Errorf("IngredientsSeederの実行に失敗: %w", err)
+// runSeed は、seed サブコマンドを実行します。
+// 初期データを反映し、発生した変更を表示します。-dry-run を指定した場合は何も保存せず、差分だけを表示します。
+func runSeed(ctx context.Context, db *gorm.DB, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "変更を保存せず、差分だけを表示します")
+	_ = fs.Parse(args)
+
+	changes, err := seeder.Run(ctx, db, *dryRun)
+	if err != nil {
+		return err
 	}
-	if err := seeder.MenusSeeder(db); err != nil {
-		return fmt.Errorf("MenusSeederの実行に失敗: %w", err)
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	if *dryRun {
+		fmt.Printf("%d件の変更があります（dry-run のため保存していません）\n", len(changes))
+	} else {
+		fmt.Printf("%d件の変更を反映しました\n", len(changes))
 	}
-
-	log.Println("ダミーデータの投入が正常に完了しました。")
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"meal-compass/backend/internal/config"
+	"meal-compass/backend/internal/usecase"
+)
+
+// retentionMetrics は、古い計画の整理の指標です。/debug/vars の "plan_retention" で参照できます。
+// dry-run の実行では、archived / deleted ではなく would_archive / would_delete に対象の件数を数えます。
+var retentionMetrics = expvar.NewMap("plan_retention")
+
+// retentionPolicy は、設定から古い計画を整理する条件を返します。
+func retentionPolicy(cfg *config.Config) usecase.RetentionPolicy {
+	return usecase.RetentionPolicy{
+		ArchiveAfterDays: cfg.PlanArchiveAfterDays,
+		DeleteAfterDays:  cfg.PlanDeleteAfterDays,
+		DryRun:           cfg.PlanRetentionDryRun,
+	}
+}
+
+// startRetentionJob は、古い計画の整理を起動時と interval ごとにバックグラウンドで実行します。
+// アーカイブも削除も設定されていない場合は何もしません。
+func startRetentionJob(ctx context.Context, planUsecase usecase.PlanUsecase, policy usecase.RetentionPolicy, interval time.Duration) {
+	if policy.ArchiveAfterDays == 0 && policy.DeleteAfterDays == 0 {
+		return
+	}
+	if interval <= 0 {
+		log.Printf("古い計画の整理の間隔が不正なため、整理を行いません: %v", interval)
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := applyRetention(ctx, planUsecase, policy); err != nil {
+				log.Printf("古い計画の整理に失敗しました: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// applyRetention は、古い計画の整理を1回実行し、結果をログと指標に記録します。
+func applyRetention(ctx context.Context, planUsecase usecase.PlanUsecase, policy usecase.RetentionPolicy) (*usecase.RetentionOutput, error) {
+	started := time.Now()
+	output, err := planUsecase.ApplyRetention(ctx, policy)
+
+	retentionMetrics.Add("runs", 1)
+	lastRun := new(expvar.String)
+	lastRun.Set(started.Format(time.RFC3339))
+	retentionMetrics.Set("last_run_at", lastRun)
+	duration := new(expvar.Float)
+	duration.Set(time.Since(started).Seconds())
+	retentionMetrics.Set("last_run_seconds", duration)
+	if err != nil {
+		retentionMetrics.Add("errors", 1)
+	}
+	if output == nil {
+		return nil, err
+	}
+
+	// 失敗した場合も、それまでに整理した件数は記録する
+	archived, deleted := "archived", "deleted"
+	if output.DryRun {
+		archived, deleted = "would_archive", "would_delete"
+	}
+	retentionMetrics.Add(archived, int64(len(output.ArchivedPlanIDs)))
+	retentionMetrics.Add(deleted, int64(len(output.DeletedPlanIDs)))
+	if len(output.ArchivedPlanIDs) > 0 || len(output.DeletedPlanIDs) > 0 {
+		log.Printf("古い計画を整理しました（dry-run: %t）: アーカイブ %d件、削除 %d件", output.DryRun, len(output.ArchivedPlanIDs), len(output.DeletedPlanIDs))
+	}
+	return output, err
+}
+
+// runRetention は、retention サブコマンドを実行します。
+// 設定（環境変数）の条件で古い計画を1回だけ整理し、対象の計画を表示します。フラグを指定した場合はフラグを優先します。
+func runRetention(ctx context.Context, planUsecase usecase.PlanUsecase, cfg *config.Config, args []string) error {
+	policy := retentionPolicy(cfg)
+	fs := flag.NewFlagSet("retention", flag.ExitOnError)
+	fs.IntVar(&policy.ArchiveAfterDays, "archive-after-days", policy.ArchiveAfterDays, "開始日からこの日数が経った計画をアーカイブします (0 の場合はアーカイブしません)")
+	fs.IntVar(&policy.DeleteAfterDays, "delete-after-days", policy.DeleteAfterDays, "開始日からこの日数が経った計画を削除します (0 の場合は削除しません)")
+	fs.BoolVar(&policy.DryRun, "dry-run", policy.DryRun, "変更を保存せず、対象の計画だけを表示します")
+	_ = fs.Parse(args)
+
+	output, err := applyRetention(ctx, planUsecase, policy)
+	if err != nil {
+		return err
+	}
+	for _, planID := range output.DeletedPlanIDs {
+		fmt.Printf("削除: %s\n", planID)
+	}
+	for _, planID := range output.ArchivedPlanIDs {
+		fmt.Printf("アーカイブ: %s\n", planID)
+	}
+	if output.DryRun {
+		fmt.Printf("アーカイブ %d件、削除 %d件の対象があります（dry-run のため保存していません）\n", len(output.ArchivedPlanIDs), len(output.DeletedPlanIDs))
+	} else {
+		fmt.Printf("アーカイブ %d件、削除 %d件を反映しました\n", len(output.ArchivedPlanIDs), len(output.DeletedPlanIDs))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// accessTokenParam は、URLのクエリパラメータの access_token とその値に一致します。
+var accessTokenParam = regexp.MustCompile(`([?&]access_token=)[^&#\s]*`)
+
+// redactedLogFormatter は、gin の標準の形式でアクセスログを出力します。パスの access_token の値は伏せます。
+func redactedLogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor, methodColor, resetColor = param.StatusCodeColor(), param.MethodColor(), param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		redactAccessToken(param.Path),
+		param.ErrorMessage,
+	)
+}
+
+// redactingWriter は、書き込む内容の access_token の値を伏せて w に書き込みます。
+// Recovery ミドルウェアはパニックしたリクエストをそのまま出力するため、その出力先に使います。
+type redactingWriter struct {
+	w io.Writer
+}
+
+func (r redactingWriter) Write(p []byte) (int, error) {
+	if _, err := r.w.Write(accessTokenParam.ReplaceAll(p, []byte("${1}REDACTED"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// redactAccessToken は、パスのクエリパラメータの access_token の値を伏せて返します。
+func redactAccessToken(path string) string {
+	return accessTokenParam.ReplaceAllString(path, "${1}REDACTED")
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactAccessToken(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "/api/plans/p1/events", want: "/api/plans/p1/events"},
+		{input: "/api/plans/p1/events?access_token=abc.def", want: "/api/plans/p1/events?access_token=REDACTED"},
+		{input: "/api/ingredient-list/p1?format=csv&access_token=abc&layout_id=l1", want: "/api/ingredient-list/p1?format=csv&access_token=REDACTED&layout_id=l1"},
+		{input: "/api/plans/p1/calendar.ics?my_access_token=abc", want: "/api/plans/p1/calendar.ics?my_access_token=abc"},
+	}
+	for _, tt := range tests {
+		if got := redactAccessToken(tt.input); got != tt.want {
+			t.Errorf("redactAccessToken(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRedactingWriter(t *testing.T) {
+	var b strings.Builder
+	dump := "GET /api/plans/p1/calendar.ics?access_token=abc.def HTTP/1.1\r\nHost: localhost\r\n"
+	if n, err := (redactingWriter{w: &b}).Write([]byte(dump)); n != len(dump) || err != nil {
+		t.Fatalf("Write() = %d, %v, want %d, nil", n, err, len(dump))
+	}
+	if want := "GET /api/plans/p1/calendar.ics?access_token=REDACTED HTTP/1.1\r\nHost: localhost\r\n"; b.String() != want {
+		t.Errorf("written = %q, want %q", b.String(), want)
+	}
+}
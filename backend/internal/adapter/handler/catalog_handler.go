@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/adapter/recipeio"
+	"meal-compass/backend/internal/adapter/schemaorg"
+	"meal-compass/backend/internal/usecase"
+)
+
+// CatalogHandler は、メニューと食材のマスターデータ関連のHTTPリクエストを処理します。
+type CatalogHandler struct {
+	catalogUsecase usecase.CatalogUsecase
+}
+
+// NewCatalogHandler は新しい CatalogHandler のインスタンスを生成します。
+func NewCatalogHandler(catalogUsecase usecase.CatalogUsecase) *CatalogHandler {
+	return &CatalogHandler{catalogUsecase: catalogUsecase}
+}
+
+// ImportRecipes は POST /api/recipes/import のリクエストを処理します。
+// 形式は format クエリパラメータ、省略時は Content-Type ヘッダーで判定します。
+func (h *CatalogHandler) ImportRecipes(c *gin.Context) {
+	var (
+		format recipeio.Format
+		err    error
+	)
+	if f := c.Query("format"); f != "" {
+		format, err = recipeio.ParseFormat(f)
+	} else {
+		format, err = recipeio.FormatFromContentType(c.GetHeader("Content-Type"))
+	}
+	if err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	strict := false
+	if s := c.Query("strict"); s != "" {
+		if strict, err = strconv.ParseBool(s); err != nil {
+			abortWithError(c, invalidRequest(errors.New("strict must be a boolean")))
+			return
+		}
+	}
+
+	catalog, err := recipeio.Decode(c.Request.Body, format)
+	if err != nil {
+		var rowErrs recipeio.RowErrors
+		if errors.As(err, &rowErrs) {
+			c.JSON(http.StatusUnprocessableEntity, &usecase.ImportRecipesOutput{
+				CreatedIngredients: []string{},
+				CreatedAliases:     []string{},
+				Errors:             rowErrs,
+			})
+		} else {
+			abortWithError(c, invalidRequest(err))
+		}
+		return
+	}
+
+	output, err := h.catalogUsecase.ImportRecipes(c.Request.Context(), usecase.ImportRecipesInput{
+		Catalog: catalog,
+		Strict:  strict,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if !output.Applied {
+		c.JSON(http.StatusUnprocessableEntity, output)
+		return
+	}
+	c.JSON(http.StatusOK, output)
+}
+
+// ExportRecipes は GET /api/recipes/export のリクエストを処理します。
+// format クエリパラメータで形式を指定します（省略時はYAML）。
+func (h *CatalogHandler) ExportRecipes(c *gin.Context) {
+	format, err := recipeio.ParseFormat(c.DefaultQuery("format", string(recipeio.FormatYAML)))
+	if err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	catalog, err := h.catalogUsecase.ExportRecipes(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="recipes.`+string(format)+`"`)
+	c.Status(http.StatusOK)
+	if err := recipeio.Encode(c.Writer, format, catalog); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// maxDraftSourceSize は、下書き作成で受け付けるHTML/JSON-LDの最大サイズです。
+const maxDraftSourceSize = 5 << 20
+
+// DraftRecipe は POST /api/recipes/draft のリクエストを処理します。
+// 保存したレシピページのHTML、またはJSON-LDをそのままリクエストボディで受け取ります。
+// ボディが maxDraftSourceSize を超える場合は、途中までで解析せずに 413 を返します。
+func (h *CatalogHandler) DraftRecipe(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxDraftSourceSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			abortWithError(c, fmt.Errorf("%w: 下書きを作成できるのは %d MB までです", usecase.ErrPayloadTooLarge, maxDraftSourceSize>>20))
+		} else {
+			abortWithError(c, invalidRequest(err))
+		}
+		return
+	}
+
+	recipe, err := schemaorg.ExtractRecipe(body)
+	if err != nil {
+		abortWithError(c, invalidContent(err))
+		return
+	}
+
+	output, err := h.catalogUsecase.DraftRecipe(c.Request.Context(), usecase.DraftRecipeInput{
+		Name:            recipe.Name,
+		SourceURL:       recipe.URL,
+		Yield:           recipe.Yield,
+		IngredientLines: recipe.Ingredients,
+		Steps:           recipe.Instructions,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetMenu は GET /api/menus/:menu_id のリクエストを処理します。
+func (h *CatalogHandler) GetMenu(c *gin.Context) {
+	menuID := c.Param("menu_id")
+
+	output, err := h.catalogUsecase.GetMenu(c.Request.Context(), menuID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetMenuVersions は GET /api/menus/:menu_id/versions のリクエストを処理します。
+func (h *CatalogHandler) GetMenuVersions(c *gin.Context) {
+	menuID := c.Param("menu_id")
+
+	output, err := h.catalogUsecase.GetMenuVersions(c.Request.Context(), menuID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": output})
+}
+
+// FindCookableMenus は POST /api/menus/cookable のリクエストを処理します。
+// 手持ちの食材（と量）を受け取り、それで作れる度合いの高い順にメニューを返します。
+func (h *CatalogHandler) FindCookableMenus(c *gin.Context) {
+	var req struct {
+		Ingredients []struct {
+			Name   string   `json:"name" binding:"required"`
+			Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+		} `json:"ingredients" binding:"required,min=1,dive"`
+		Servings int `json:"servings" binding:"omitempty,gt=0"`
+		Limit    int `json:"limit" binding:"omitempty,gt=0"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	input := usecase.FindCookableMenusInput{Servings: req.Servings, Limit: req.Limit}
+	for _, ing := range req.Ingredients {
+		input.Ingredients = append(input.Ingredients, &usecase.OnHandIngredientInput{Name: ing.Name, Amount: ing.Amount})
+	}
+
+	output, err := h.catalogUsecase.FindCookableMenus(c.Request.Context(), input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// SearchIngredients は GET /api/ingredients/search のリクエストを処理します。
+// q クエリパラメータの検索語に近い食材を、一致度の高い順に最大 limit 件返します。
+func (h *CatalogHandler) SearchIngredients(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			abortWithError(c, invalidRequest(errors.New("limit must be a positive integer")))
+			return
+		}
+		limit = n
+	}
+
+	output, err := h.catalogUsecase.SearchIngredients(c.Request.Context(), usecase.SearchIngredientsInput{
+		Query: c.Query("q"),
+		Limit: limit,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ingredients": output})
+}
+
+// AddIngredientAliases は POST /api/ingredients/:ingredient_id/aliases のリクエストを処理します。
+func (h *CatalogHandler) AddIngredientAliases(c *gin.Context) {
+	ingredientID := c.Param("ingredient_id")
+
+	var req struct {
+		Aliases []string `json:"aliases" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.catalogUsecase.AddIngredientAliases(c.Request.Context(), usecase.AddIngredientAliasesInput{
+		IngredientID: ingredientID,
+		Aliases:      req.Aliases,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
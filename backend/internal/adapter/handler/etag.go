@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// versionETag は、行の版を ETag（強いETag）の形式で返します。
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// ifMatchVersion は、If-Match ヘッダーで指定された版を返します。ヘッダーが無い場合や "*" の場合は nil を返します。
+// 版は ETag と同じ強いETag（"3" など）で1つだけ指定します。
+func ifMatchVersion(c *gin.Context) (*int, error) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+	if strings.HasPrefix(value, "W/") {
+		return nil, errors.New("If-Match には強いETagを指定してください")
+	}
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) || len(value) < 2 {
+		return nil, errors.New("If-Match の形式が不正です: " + value)
+	}
+	return &version, nil
+}
+
+// bindIfMatchVersion は、If-Match ヘッダーの版を返します。形式が不正な場合はエラー（400）を記録し、ok に false を返します。
+func bindIfMatchVersion(c *gin.Context) (version *int, ok bool) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		abortWithError(c, invalidRequest(err))
+		return nil, false
+	}
+	return version, true
+}
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
 	"meal-compass/backend/internal/usecase"
 )
@@ -21,32 +20,186 @@ func NewIngredientHandler(planUsecase usecase.PlanUsecase) *IngredientHandler {
 }
 
 // UpdateShoppingIngredientItem は PATCH /api/shopping_ingredient_items/:item_id のリクエストを処理します。
+// 購入済みかどうかのほかに、必要量と実際に購入した量を更新できます。省略した項目は変更しません。
+// If-Match ヘッダーにアイテムの版（ETag）を指定すると、他の利用者が先に更新していた場合は 412 を返します。
 func (h *IngredientHandler) UpdateShoppingIngredientItem(c *gin.Context) {
 	itemID := c.Param("item_id")
 
+	expectedVersion, ok := bindIfMatchVersion(c)
+	if !ok {
+		return
+	}
+
 	var req struct {
-		Bought bool `json:"bought"`
+		Bought          *bool    `json:"bought"`
+		Amount          *float64 `json:"amount"`
+		PurchasedAmount *float64 `json:"purchased_amount"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		abortWithError(c, invalidRequest(err))
 		return
 	}
 
 	input := usecase.UpdateShoppingIngredientItemInput{
-		ItemID: itemID,
-		Bought: req.Bought,
+		ItemID:          itemID,
+		Bought:          req.Bought,
+		Amount:          req.Amount,
+		PurchasedAmount: req.PurchasedAmount,
+		ExpectedVersion: expectedVersion,
 	}
 
 	output, err := h.planUsecase.UpdateShoppingIngredientItem(c.Request.Context(), input)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get ingredient list"})
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
+
+// AddShoppingItem は POST /api/ingredient-list/:shopping_plan_id/items のリクエストを処理します。
+// 名前がカタログの食材に一致すればその食材として、一致しなければ自由入力のアイテムとして手動で追加します。
+func (h *IngredientHandler) AddShoppingItem(c *gin.Context) {
+	var req struct {
+		Name   string  `json:"name" binding:"required"`
+		Amount float64 `json:"amount" binding:"required"`
+		Unit     string  `json:"unit"`
+		Note     string  `json:"note"`
+		TripDate string  `json:"trip_date"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.planUsecase.AddShoppingItem(c.Request.Context(), usecase.AddShoppingItemInput{
+		PlanID: c.Param("shopping_plan_id"),
+		Name:   req.Name,
+		Amount: req.Amount,
+		Unit:     req.Unit,
+		Note:     req.Note,
+		TripDate: req.TripDate,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+// DeleteShoppingItem は DELETE /api/shopping_ingredient_items/:item_id のリクエストを処理します。
+// 削除できるのは手動で追加したアイテムだけです。
+func (h *IngredientHandler) DeleteShoppingItem(c *gin.Context) {
+	err := h.planUsecase.DeleteShoppingItem(c.Request.Context(), c.Param("item_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// BatchUpdateShoppingItems は PATCH /api/ingredient-list/:shopping_plan_id/items のリクエストを処理します。
+// 複数のアイテムの購入状況・必要量を1つのトランザクションでまとめて更新し、アイテムごとの結果を返します。
+// If-Match ヘッダーには計画の版を、各アイテムの version にはアイテムの版を指定でき、一致しない場合は 412 を返します。
+func (h *IngredientHandler) BatchUpdateShoppingItems(c *gin.Context) {
+	expectedPlanVersion, ok := bindIfMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []struct {
+			ID              string   `json:"id" binding:"required"`
+			Bought          *bool    `json:"bought"`
+			Amount          *float64 `json:"amount"`
+			PurchasedAmount *float64 `json:"purchased_amount"`
+			Version         *int     `json:"version"`
+		} `json:"items" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	items := make([]usecase.UpdateShoppingIngredientItemInput, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = usecase.UpdateShoppingIngredientItemInput{
+			ItemID:          item.ID,
+			Bought:          item.Bought,
+			Amount:          item.Amount,
+			PurchasedAmount: item.PurchasedAmount,
+			ExpectedVersion: item.Version,
+		}
+	}
+
+	output, err := h.planUsecase.BatchUpdateShoppingItems(c.Request.Context(), usecase.BatchUpdateShoppingItemsInput{
+		PlanID:              c.Param("shopping_plan_id"),
+		Items:               items,
+		ExpectedPlanVersion: expectedPlanVersion,
+	})
+	respondShoppingItemResults(c, output, err)
+}
+
+// CheckOffMergedLines は PATCH /api/merged-ingredient-list/lines のリクエストを処理します。
+// まとめた買い物リストの行を購入済み（または未購入）にし、行に含まれるすべての計画のアイテムに反映します。
+func (h *IngredientHandler) CheckOffMergedLines(c *gin.Context) {
+	var req struct {
+		PlanIDs []string `json:"plan_ids" binding:"required"`
+		Lines   []struct {
+			Key    string `json:"key" binding:"required"`
+			Bought *bool  `json:"bought" binding:"required"`
+		} `json:"lines" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	lines := make([]usecase.MergedLineCheck, len(req.Lines))
+	for i, line := range req.Lines {
+		lines[i] = usecase.MergedLineCheck{Key: line.Key, Bought: *line.Bought}
+	}
+
+	output, err := h.planUsecase.CheckOffMergedLines(c.Request.Context(), usecase.CheckOffMergedLinesInput{
+		PlanIDs: req.PlanIDs,
+		Lines:   lines,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"lines": output})
+}
+
+// respondShoppingItemResults は、アイテムをまとめて更新した結果をレスポンスとして返します。ETag には計画の版を返します。
+func respondShoppingItemResults(c *gin.Context, output *usecase.BatchUpdateShoppingItemsOutput, err error) {
+	if errors.Is(err, usecase.ErrShoppingItemBatchRejected) {
+		// どのアイテムを更新できなかったかが分かるよう、エラーにアイテムごとの結果を加えて返す
+		p := newProblem(c, err)
+		if errors.Is(err, usecase.ErrVersionConflict) {
+			p.Status = http.StatusPreconditionFailed
 		}
+		c.Header("ETag", versionETag(output.PlanVersion))
+		writeProblem(c, p.Status, struct {
+			*problem
+			PlanVersion int                           `json:"plan_version"`
+			Results     []*usecase.ShoppingItemResult `json:"results"`
+		}{p, output.PlanVersion, output.Results})
+		return
+	}
+	if err != nil {
+		abortWithError(c, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(output.PlanVersion))
 	c.JSON(http.StatusOK, output)
-}
\ No newline at end of file
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// publicVars は、本番環境でも /debug/vars で返す expvar の変数の名前です。
+// コマンドラインやメモリの統計（cmdline、memstats）は含めません。
+var publicVars = []string{"plan_retention"}
+
+// expvarSubsetHandler は、expvar の変数のうち names のものだけを、expvar.Handler と同じ JSON の形式で返すハンドラーです。
+// 登録されていない変数は省きます。
+func expvarSubsetHandler(names []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var b strings.Builder
+		b.WriteString("{\n")
+		first := true
+		for _, name := range names {
+			v := expvar.Get(name)
+			if v == nil {
+				continue
+			}
+			if !first {
+				b.WriteString(",\n")
+			}
+			first = false
+			fmt.Fprintf(&b, "%q: %s", name, v.String())
+		}
+		b.WriteString("\n}\n")
+		c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(b.String()))
+	}
+}
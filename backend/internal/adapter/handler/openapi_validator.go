@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"bytes"
+	"log"
+	"mime"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/adapter/openapi"
+)
+
+// maxValidatedResponseSize は、API仕様と照らし合わせるレスポンスの最大サイズです。これより大きいレスポンスは検証しません。
+const maxValidatedResponseSize = 1 << 20
+
+// OpenAPIValidator は、リクエストをAPI仕様（doc）と照らし合わせ、合わないものを 400 のエラーとして返すミドルウェアです。
+// validateResponses が true の場合は、JSONのレスポンスもAPI仕様と照らし合わせ、合わないものをログに出力します（開発時の確認用）。
+// API仕様にないルート（/health など）は検証しません。
+func OpenAPIValidator(doc *openapi3.T, validateResponses bool) gin.HandlerFunc {
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	// スキーマの無いリクエストボディ（レシピの取り込みなど）は読み込まずにハンドラーに任せる
+	rawBodyOptions := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, ExcludeRequestBody: true}
+
+	return func(c *gin.Context) {
+		path, ok := openapi.PathFromRoute(c.FullPath())
+		item := doc.Paths.Value(path)
+		if !ok || item == nil || item.GetOperation(c.Request.Method) == nil {
+			c.Next()
+			return
+		}
+		op := item.GetOperation(c.Request.Method)
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      &routers.Route{Spec: doc, Path: path, PathItem: item, Method: c.Request.Method, Operation: op},
+			Options:    options,
+		}
+		if !hasRequestBodySchema(op) {
+			input.Options = rawBodyOptions
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			// ErrorHandler より前で動くため、エラーのレスポンスはここで返す
+			p := newProblem(c, invalidRequest(err))
+			writeProblem(c, p.Status, p)
+			c.Abort()
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		if !recorder.recorded || recorder.truncated {
+			return
+		}
+		output := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.Status(),
+			Header:                 recorder.Header(),
+			Options:                options,
+		}
+		output.SetBodyBytes(recorder.body.Bytes())
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), output); err != nil {
+			log.Printf("レスポンスがAPI仕様と一致しません: %s %s (%d): %v", c.Request.Method, c.Request.URL.Path, recorder.Status(), err)
+		}
+	}
+}
+
+// hasRequestBodySchema は、操作のリクエストボディにスキーマが書かれているかを返します。
+func hasRequestBodySchema(op *openapi3.Operation) bool {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return false
+	}
+	for _, mediaType := range op.RequestBody.Value.Content {
+		if mediaType.Schema != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder は、JSONのレスポンスボディを書き出しながら記録する gin.ResponseWriter です。
+// それ以外（カレンダー、Server-Sent Events、PDFなど）は記録しません。
+type responseRecorder struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	recorded  bool // JSONのレスポンスを記録したか
+	truncated bool // maxValidatedResponseSize を超えたため記録をやめたか
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *responseRecorder) record(data []byte) {
+	if w.truncated || !isJSONContentType(w.Header().Get("Content-Type")) {
+		return
+	}
+	if w.body.Len()+len(data) > maxValidatedResponseSize {
+		w.truncated = true
+		w.body.Reset()
+		return
+	}
+	w.recorded = true
+	w.body.Write(data)
+}
+
+// isJSONContentType は、Content-Type が application/json または application/problem+json かを返します。
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || mediaType == problemContentType)
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+const (
+	// ownerSessionCookie は、持ち主のセッションを保存するクッキーの名前です。
+	ownerSessionCookie = "meal_compass_session"
+	// ownerSessionMaxAge は、持ち主のセッションの有効期間です。
+	ownerSessionMaxAge = 30 * 24 * time.Hour
+	// urlTokenTTL は、URLに付けるトークン（access_token）の有効期間です。
+	// URLはログや履歴に残りやすいため、短くしてそのURL（パス）でだけ使えるようにします。
+	urlTokenTTL = 10 * time.Minute
+)
+
+// OwnerAuth は、計画の持ち主（OWNER_TOKEN を知っている利用者）の確認と、ブラウザ向けのセッションの発行を担当します。
+// ブラウザにはトークンそのものを渡さず、トークンと引き換えに署名したセッションを HttpOnly のクッキーで渡します。
+type OwnerAuth struct {
+	token string
+	// key は、セッションとURLのトークンの署名に使う鍵です。token から作るため、token を変えると発行済みのものは無効になります。
+	key []byte
+}
+
+// NewOwnerAuth は新しい OwnerAuth のインスタンスを生成します。token が空の場合は確かめません（開発環境用）。
+func NewOwnerAuth(token string) *OwnerAuth {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte("meal-compass owner session"))
+	return &OwnerAuth{token: token, key: mac.Sum(nil)}
+}
+
+// Require は、持ち主からのリクエストかを確かめるミドルウェアを返します。
+// ブラウザはセッションのクッキーで、それ以外（スクリプトなど）は Authorization ヘッダー（Bearer）にトークンを指定します。
+// ヘッダーもクッキーも付けられない場合は、CreateURLToken で発行したトークンを access_token クエリパラメータに指定して GET で呼び出せます。
+// 持ち主のトークンそのものは、URLに残らないよう access_token では受け付けません。
+func (a *OwnerAuth) Require() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.token == "" {
+			c.Next()
+			return
+		}
+		if !a.authorized(c) {
+			c.Header("WWW-Authenticate", `Bearer realm="meal-compass"`)
+			abortWithError(c, usecase.ErrUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// CreateSession は POST /api/session のリクエストを処理します。
+// トークンが一致した場合は、持ち主のセッションを HttpOnly のクッキーに設定します。
+func (a *OwnerAuth) CreateSession(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+	if a.token != "" && !a.matchesToken(req.Token) {
+		abortWithError(c, usecase.ErrUnauthorized)
+		return
+	}
+
+	expiresAt := time.Now().Add(ownerSessionMaxAge)
+	a.setSessionCookie(c, a.sign("session|"+strconv.FormatInt(expiresAt.Unix(), 10)), int(ownerSessionMaxAge/time.Second))
+	c.Status(http.StatusNoContent)
+}
+
+// CreateURLToken は POST /api/url-tokens のリクエストを処理します。
+// 指定されたパスへの GET でだけ、urlTokenTTL の間使えるトークンを発行します。
+func (a *OwnerAuth) CreateURLToken(c *gin.Context) {
+	var req struct {
+		Path string `json:"path" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+	if !strings.HasPrefix(req.Path, "/api/") || strings.ContainsAny(req.Path, "?#|") {
+		abortWithError(c, invalidRequest(errors.New("path には /api/ から始まるパスをクエリパラメータを付けずに指定してください")))
+		return
+	}
+
+	expiresAt := time.Now().Add(urlTokenTTL).Truncate(time.Second)
+	c.JSON(http.StatusCreated, gin.H{
+		"access_token": a.sign("url|" + req.Path + "|" + strconv.FormatInt(expiresAt.Unix(), 10)),
+		"expires_at":   expiresAt,
+	})
+}
+
+// DeleteSession は DELETE /api/session のリクエストを処理します。セッションのクッキーを消します。
+func (a *OwnerAuth) DeleteSession(c *gin.Context) {
+	a.setSessionCookie(c, "", -1)
+	c.Status(http.StatusNoContent)
+}
+
+// authorized は、リクエストで指定された持ち主の資格情報が正しいかを返します。
+// Authorization ヘッダー、セッションのクッキー、access_token（GET のみ）の順に見て、最初に見つかったものだけで判断します。
+func (a *OwnerAuth) authorized(c *gin.Context) bool {
+	if scheme, credential, ok := strings.Cut(c.GetHeader("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return a.matchesToken(strings.TrimSpace(credential))
+	}
+	if session, err := c.Cookie(ownerSessionCookie); err == nil && session != "" {
+		return a.validSession(session)
+	}
+	if c.Request.Method == http.MethodGet {
+		return a.validURLToken(c.Query("access_token"), c.Request.URL.Path)
+	}
+	return false
+}
+
+// matchesToken は、指定された値が持ち主のトークンと一致するかを返します。
+func (a *OwnerAuth) matchesToken(credential string) bool {
+	return subtle.ConstantTimeCompare([]byte(credential), []byte(a.token)) == 1
+}
+
+// validSession は、セッションのクッキーの署名が正しく、期限が切れていないかを返します。
+func (a *OwnerAuth) validSession(session string) bool {
+	payload, ok := a.verify(session)
+	if !ok {
+		return false
+	}
+	kind, expires, ok := strings.Cut(payload, "|")
+	if !ok || kind != "session" {
+		return false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	return err == nil && time.Now().Before(time.Unix(unix, 0))
+}
+
+// validURLToken は、URLに付けたトークンの署名が正しく、path 用に発行したもので、期限が切れていないかを返します。
+func (a *OwnerAuth) validURLToken(token, path string) bool {
+	payload, ok := a.verify(token)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(payload, "|")
+	if len(parts) != 3 || parts[0] != "url" || parts[1] != path {
+		return false
+	}
+	unix, err := strconv.ParseInt(parts[2], 10, 64)
+	return err == nil && time.Now().Before(time.Unix(unix, 0))
+}
+
+// sign は、payload に署名した値（payload と署名をそれぞれ base64url にして . でつないだもの）を返します。
+func (a *OwnerAuth) sign(payload string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify は、sign で署名した値の署名を確かめ、payload を返します。
+func (a *OwnerAuth) verify(value string) (string, bool) {
+	encodedPayload, encodedSig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return "", false
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", false
+	}
+	return string(payload), true
+}
+
+// setSessionCookie は、セッションのクッキーを設定します。maxAge が負の場合はクッキーを消します。
+// JavaScript から読めないよう HttpOnly にし、HTTPS で受けたリクエストでは Secure にします。
+func (a *OwnerAuth) setSessionCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     ownerSessionCookie,
+		Value:    value,
+		Path:     "/api",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
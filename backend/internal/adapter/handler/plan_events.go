@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+const (
+	// planEventHeartbeat は、接続が切られないように空のコメントを送る間隔です。
+	planEventHeartbeat = 25 * time.Second
+	// planEventRetry は、切断されたクライアントが再接続するまでの待ち時間（ミリ秒）です。
+	planEventRetry = 3000
+)
+
+// StreamPlanEvents は GET /api/plans/:shopping_plan_id/events のリクエストを処理します。
+// 計画の変更（買い物リストのアイテムの追加・更新・削除、計画の削除）を Server-Sent Events で配信します。
+// 再接続したクライアントは、Last-Event-ID ヘッダー（または last_event_id クエリパラメータ）の番号より後のイベントを受け取れます。
+func (h *PlanHandler) StreamPlanEvents(c *gin.Context) {
+	h.streamPlanEvents(c, false)
+}
+
+// StreamSharedPlanEvents は GET /api/shared/:share_token/events のリクエストを処理します。
+// StreamPlanEvents と同じイベントを、計画のID（plan_id）を除いて配信します。
+func (h *PlanHandler) StreamSharedPlanEvents(c *gin.Context) {
+	h.streamPlanEvents(c, true)
+}
+
+// streamPlanEvents は、計画の変更を Server-Sent Events で配信します。hidePlanID が true の場合は計画のIDを除きます。
+func (h *PlanHandler) streamPlanEvents(c *gin.Context, hidePlanID bool) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var afterSeq uint64
+	if lastEventID != "" {
+		seq, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			abortWithError(c, invalidRequest(errors.New("Invalid Last-Event-ID: "+lastEventID)))
+			return
+		}
+		afterSeq = seq
+	}
+
+	sub, err := h.planUsecase.SubscribePlanEvents(c.Request.Context(), c.Param("shopping_plan_id"), afterSeq)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer sub.Cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx でバッファリングさせない
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", planEventRetry)
+	for _, event := range sub.Missed {
+		if err := writePlanEvent(w, event, hidePlanID); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(planEventHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// 配信が追いつかずに打ち切られた。クライアントは再接続して続きを受け取る
+				return
+			}
+			if err := writePlanEvent(w, event, hidePlanID); err != nil {
+				return
+			}
+			if event.Type == usecase.PlanEventPlanDeleted {
+				// 計画が無くなったため配信を終える（再接続しても 404 になる）
+				w.Flush()
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+// writePlanEvent は、イベントを1件の Server-Sent Events のメッセージとして書き込みます。
+// hidePlanID が true の場合は、計画のIDを除いて書き込みます（イベントは他の購読者と共有しているため、複製して変更する）。
+func writePlanEvent(w io.Writer, event *usecase.PlanEvent, hidePlanID bool) error {
+	if hidePlanID {
+		shared := *event
+		shared.PlanID = ""
+		event = &shared
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
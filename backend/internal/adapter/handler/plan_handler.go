@@ -2,22 +2,28 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 
+	"meal-compass/backend/internal/adapter/icalendar"
+	"meal-compass/backend/internal/adapter/listexport"
+	"meal-compass/backend/internal/domain/model"
 	"meal-compass/backend/internal/usecase"
 )
 
 // PlanHandler は、計画関連のHTTPリクエストを処理します。
 type PlanHandler struct {
-	planUsecase usecase.PlanUsecase // Usecaseへのインターフェースを保持
+	planUsecase     usecase.PlanUsecase // Usecaseへのインターフェースを保持
+	calendarOptions icalendar.Options   // 献立のカレンダーの予定の既定の時刻
 }
 
 // NewPlanHandler は新しい PlanHandler のインスタンスを生成します。
-func NewPlanHandler(planUsecase usecase.PlanUsecase) *PlanHandler {
-	return &PlanHandler{planUsecase: planUsecase}
+func NewPlanHandler(planUsecase usecase.PlanUsecase, calendarOptions icalendar.Options) *PlanHandler {
+	return &PlanHandler{planUsecase: planUsecase, calendarOptions: calendarOptions}
 }
 
 // CreateNewPlan は POST /api/create-new-plan のリクエストを処理します。
@@ -28,11 +34,14 @@ func (h *PlanHandler) CreateNewPlan(c *gin.Context) {
 			DateOffset int    `json:"date_offset"`
 			MealPeriod string `json:"meal_period"`
 		} `json:"planned_meals" binding:"required"`
+		AvoidIngredients  []string `json:"avoid_ingredients"`
+		OnHandIngredients []string `json:"on_hand_ingredients"`
+		TripOffsets       []int    `json:"trip_offsets"` // 買い物に行く日（計画の開始日からの日数）。省略時は日持ちから決める
 	}
 
 	// JSONボディを構造体にバインド。形式が不正な場合は400エラー。
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		abortWithError(c, invalidRequest(err))
 		return
 	}
 
@@ -41,11 +50,11 @@ func (h *PlanHandler) CreateNewPlan(c *gin.Context) {
 	for i, meal := range req.PlannedMeals {
 		// バリデーション: date_offsetが負数でないか、meal_periodが正しい値か
 		if meal.DateOffset < 0 {
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "date_offset cannot be negative"})
+			abortWithError(c, invalidContent(errors.New("date_offset cannot be negative")))
 			return
 		}
 		if !(meal.MealPeriod == "MORNING" || meal.MealPeriod == "LUNCH" || meal.MealPeriod == "DINNER") {
-			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "invalid meal_period"})
+			abortWithError(c, invalidContent(errors.New("invalid meal_period")))
 			return
 		}
 		plannedMealsDTO[i] = usecase.PlannedMealInput{
@@ -55,10 +64,15 @@ func (h *PlanHandler) CreateNewPlan(c *gin.Context) {
 	}
 
 	// Usecaseを呼び出し
-	output, err := h.planUsecase.CreatePlan(c.Request.Context(), plannedMealsDTO)
+	output, err := h.planUsecase.CreatePlan(c.Request.Context(), usecase.CreatePlanInput{
+		Meals:             plannedMealsDTO,
+		AvoidIngredients:  req.AvoidIngredients,
+		OnHandIngredients: req.OnHandIngredients,
+		TripDays:          req.TripOffsets,
+	})
 	if err != nil {
-		// Usecaseから返されたエラーに応じてレスポンスを返す
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create plan: " + err.Error()})
+		// Usecaseから返されたエラーは、ErrorHandler がエラーの種類に応じたレスポンスにする
+		abortWithError(c, err)
 		return
 	}
 
@@ -72,11 +86,7 @@ func (h *PlanHandler) GetMenuList(c *gin.Context) {
 
 	output, err := h.planUsecase.GetMenuList(c.Request.Context(), planID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get menu list"})
-		}
+		abortWithError(c, err)
 		return
 	}
 
@@ -84,14 +94,113 @@ func (h *PlanHandler) GetMenuList(c *gin.Context) {
 }
 
 // GetIngredientList は GET /api/ingredient-list/:shopping_plan_id のリクエストを処理します。
+// layout_id クエリパラメータで店舗レイアウトを指定すると、その売り場の順に並べて返します。
+// trip_date クエリパラメータ (YYYY-MM-DD) を指定すると、その日に買いに行くアイテムだけを返します。
+// format クエリパラメータ、省略時は Accept ヘッダーで、テキスト/Markdown/CSV/PDFでの書き出しを指定できます。
 func (h *PlanHandler) GetIngredientList(c *gin.Context) {
 	planID := c.Param("shopping_plan_id")
 
-	output, err := h.planUsecase.GetIngredientList(c.Request.Context(), planID)
+	format := listexport.FormatFromAccept(c.GetHeader("Accept"))
+	if f := c.Query("format"); f != "" {
+		var err error
+		if format, err = listexport.ParseFormat(f); err != nil {
+			abortWithError(c, invalidRequest(err))
+			return
+		}
+	}
+
+	output, err := h.planUsecase.GetIngredientList(c.Request.Context(), usecase.GetIngredientListInput{
+		PlanID:   planID,
+		LayoutID: c.Query("layout_id"),
+		TripDate: c.Query("trip_date"),
+	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Plan not found"})
+		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"ingredients": output})
-}
\ No newline at end of file
+	if format == listexport.FormatJSON {
+		// まとめて更新するときに If-Match で指定できるよう、計画の版を返す
+		c.Header("ETag", versionETag(output.Version))
+		c.JSON(http.StatusOK, output)
+		return
+	}
+	c.Header("Content-Type", format.ContentType())
+	filename := "shopping-list"
+	if output.TripDate != nil {
+		filename += "-" + *output.TripDate
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`.`+string(format)+`"`)
+	c.Status(http.StatusOK)
+	if err := listexport.Encode(c.Writer, format, output); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// GetMergedIngredientList は GET /api/merged-ingredient-list のリクエストを処理します。
+// plan_ids クエリパラメータ（カンマ区切り、または複数指定）の計画の買い物リストを、同じ食材ごとに1行にまとめて返します。
+// layout_id クエリパラメータで店舗レイアウトを指定すると、その売り場の順に並べます。
+func (h *PlanHandler) GetMergedIngredientList(c *gin.Context) {
+	output, err := h.planUsecase.GetMergedIngredientList(c.Request.Context(), usecase.GetMergedIngredientListInput{
+		PlanIDs:  splitQueryList(c.QueryArray("plan_ids")),
+		LayoutID: c.Query("layout_id"),
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// splitQueryList は、カンマ区切りで指定されたクエリパラメータの値を1つのリストにします。
+func splitQueryList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		list = append(list, strings.Split(value, ",")...)
+	}
+	return list
+}
+
+// GetLeftovers は GET /api/leftovers/:shopping_plan_id のリクエストを処理します。
+// 必要量を超えて購入した食材（残りもの）を、使い切る目安の日付が近い順に返します。
+func (h *PlanHandler) GetLeftovers(c *gin.Context) {
+	output, err := h.planUsecase.GetLeftovers(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leftovers": output})
+}
+
+// GetMealCalendar は GET /api/plans/:shopping_plan_id/calendar.ics のリクエストを処理します。
+// 食事ごとの予定をiCalendar形式で返します。morning、lunch、dinner クエリパラメータ (HH:MM) で既定の時刻を変更できます。
+func (h *PlanHandler) GetMealCalendar(c *gin.Context) {
+	opts := h.calendarOptions
+	for param, period := range map[string]model.MealPeriod{"morning": model.Morning, "lunch": model.Lunch, "dinner": model.Dinner} {
+		s := c.Query(param)
+		if s == "" {
+			continue
+		}
+		clock, err := icalendar.ParseClock(s)
+		if err != nil {
+			abortWithError(c, invalidRequest(fmt.Errorf("%s: %w", param, err)))
+			return
+		}
+		opts = opts.WithMealTime(period, clock)
+	}
+
+	meals, err := h.planUsecase.GetMenuList(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", icalendar.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="meal-plan.ics"`)
+	c.Status(http.StatusOK)
+	if err := icalendar.Encode(c.Writer, meals, opts, time.Now()); err != nil {
+		_ = c.Error(err)
+	}
+}
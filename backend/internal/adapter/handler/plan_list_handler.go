@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// ListPlans は GET /api/plans のリクエストを処理します。
+// 計画を開始日の新しい順に最大 limit 件、食事の件数や購入済みの割合とともに返します。
+// from、to クエリパラメータ (YYYY-MM-DD) で開始日の期間を、status (active, archived, all) でアーカイブの状態を絞り込み、
+// cursor に前のページの next_cursor を指定して続きを取得します。
+func (h *PlanHandler) ListPlans(c *gin.Context) {
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			abortWithError(c, invalidRequest(errors.New("limit must be a positive integer")))
+			return
+		}
+		limit = n
+	}
+
+	output, err := h.planUsecase.ListPlans(c.Request.Context(), usecase.ListPlansInput{
+		From:   c.Query("from"),
+		To:     c.Query("to"),
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+		Limit:  limit,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetPlan は GET /api/plans/:shopping_plan_id のリクエストを処理します。
+// 計画の開始日や版などの情報と、食事や買い物の進み具合、買いに行く日を返します。ETag には計画の版を設定します。
+func (h *PlanHandler) GetPlan(c *gin.Context) {
+	output, err := h.planUsecase.GetPlan(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
+
+// GetSharedPlan は GET /api/shared/:share_token/plan のリクエストを処理します。
+// GetPlan と同じ情報を、計画のIDを除いて返します（共有リンクの利用者に持ち主のAPIで使うIDを知らせないため）。
+func (h *PlanHandler) GetSharedPlan(c *gin.Context) {
+	output, err := h.planUsecase.GetSharedPlan(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
+
+// UpdatePlan は PATCH /api/plans/:shopping_plan_id のリクエストを処理します。
+// archived に true を指定すると計画をアーカイブし（一覧に既定では表示しない）、false を指定すると解除します。
+func (h *PlanHandler) UpdatePlan(c *gin.Context) {
+	var req struct {
+		Archived *bool `json:"archived" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.planUsecase.SetPlanArchived(c.Request.Context(), usecase.SetPlanArchivedInput{
+		PlanID:   c.Param("shopping_plan_id"),
+		Archived: *req.Archived,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(output.Version))
+	c.JSON(http.StatusOK, output)
+}
+
+// DeletePlan は DELETE /api/plans/:shopping_plan_id のリクエストを処理します。
+// 計画を献立、買い物リスト、レシート、共有リンクとともに削除します。If-Match ヘッダーで計画の版を指定できます。
+func (h *PlanHandler) DeletePlan(c *gin.Context) {
+	expectedVersion, ok := bindIfMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	err := h.planUsecase.DeletePlan(c.Request.Context(), usecase.DeletePlanInput{
+		PlanID:          c.Param("shopping_plan_id"),
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
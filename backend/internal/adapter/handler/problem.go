@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// problemContentType は、エラーのレスポンス（RFC 7807 の Problem Details）の Content-Type です。
+const problemContentType = "application/problem+json"
+
+// problemTypePrefix は、Problem Details の type に使うURIの接頭辞です。エラーのコードを続けます。
+const problemTypePrefix = "urn:meal-compass:problem:"
+
+// problemStatuses は、ユースケースのエラーの種類ごとのHTTPステータスです。
+var problemStatuses = map[*usecase.Error]int{
+	usecase.ErrNotFound:           http.StatusNotFound,
+	usecase.ErrInvalidRequest:     http.StatusBadRequest,
+	usecase.ErrValidation:         http.StatusUnprocessableEntity,
+	usecase.ErrConflict:           http.StatusConflict,
+	usecase.ErrPreconditionFailed: http.StatusPreconditionFailed,
+	usecase.ErrInsufficientMenus:  http.StatusUnprocessableEntity,
+	usecase.ErrPayloadTooLarge:    http.StatusRequestEntityTooLarge,
+	usecase.ErrUnauthorized:       http.StatusUnauthorized,
+	usecase.ErrForbidden:          http.StatusForbidden,
+	usecase.ErrGone:               http.StatusGone,
+}
+
+// problem は、RFC 7807 の Problem Details のレスポンスです。
+// code は、エラーを識別する変わらない文字列（usecase.Error の Code）です。
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance"`
+	Code     string `json:"code"`
+}
+
+// newProblem は、エラーから Problem Details を組み立てます。
+// usecase.Error に分類されないエラーは、内容を返さずに 500 の internal_error とします。
+func newProblem(c *gin.Context, err error) *problem {
+	p := &problem{
+		Type:     problemTypePrefix + "internal_error",
+		Title:    "サーバーでエラーが発生しました",
+		Status:   http.StatusInternalServerError,
+		Detail:   "サーバーでエラーが発生しました。時間をおいてやり直してください",
+		Instance: c.Request.URL.Path,
+		Code:     "internal_error",
+	}
+	var ue *usecase.Error
+	if !errors.As(err, &ue) {
+		return p
+	}
+	if status, ok := problemStatuses[ue.Kind()]; ok {
+		p.Status = status
+	}
+	p.Type = problemTypePrefix + ue.Code
+	p.Title = ue.Message
+	p.Detail = err.Error()
+	p.Code = ue.Code
+	return p
+}
+
+// writeProblem は、body を Problem Details のレスポンスとして返します。
+// body には problem か、problem を埋め込んで項目を加えた構造体を指定します。
+func writeProblem(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", problemContentType)
+	c.JSON(status, body)
+}
+
+// ErrorHandler は、ハンドラーが c.Error で記録したエラーを Problem Details（application/problem+json）として返すミドルウェアです。
+// ハンドラーがすでにレスポンスを書き始めている場合（書き出しの途中で失敗した場合など）は何もしません。
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		p := newProblem(c, c.Errors.Last().Err)
+		writeProblem(c, p.Status, p)
+	}
+}
+
+// abortWithError は、エラーを記録して後続のハンドラーを実行しないようにします。レスポンスは ErrorHandler が返します。
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// invalidRequest は、リクエストの形式が不正な場合のエラーを usecase.ErrInvalidRequest に分類して返します。
+func invalidRequest(err error) error {
+	return fmt.Errorf("%w: %w", usecase.ErrInvalidRequest, err)
+}
+
+// invalidContent は、リクエストの内容を解釈できない場合のエラーを usecase.ErrValidation に分類して返します。
+func invalidContent(err error) error {
+	return fmt.Errorf("%w: %w", usecase.ErrValidation, err)
+}
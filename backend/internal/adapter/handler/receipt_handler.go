@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// RecordReceipt は POST /api/plans/:shopping_plan_id/receipts のリクエストを処理します。
+// 実際に購入した品物と支払った金額（貼り付けたレシートのテキストでも可）を記録し、対応する買い物リストのアイテムを購入済みにします。
+func (h *PlanHandler) RecordReceipt(c *gin.Context) {
+	var req struct {
+		StoreName   string     `json:"store_name"`
+		PurchasedAt *time.Time `json:"purchased_at"`
+		Lines       []struct {
+			Name     string   `json:"name" binding:"required"`
+			Quantity *float64 `json:"quantity"`
+			Unit     string   `json:"unit"`
+			Price    *int     `json:"price" binding:"required"`
+			ItemID   string   `json:"item_id"`
+		} `json:"lines" binding:"dive"`
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	input := usecase.RecordReceiptInput{
+		PlanID:      c.Param("shopping_plan_id"),
+		StoreName:   req.StoreName,
+		PurchasedAt: req.PurchasedAt,
+		Lines:       make([]usecase.ReceiptLineInput, len(req.Lines)),
+		Text:        req.Text,
+	}
+	for i, line := range req.Lines {
+		input.Lines[i] = usecase.ReceiptLineInput{
+			Name:     line.Name,
+			Quantity: line.Quantity,
+			Unit:     line.Unit,
+			Price:    *line.Price,
+			ItemID:   line.ItemID,
+		}
+	}
+
+	output, err := h.planUsecase.RecordReceipt(c.Request.Context(), input)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+// GetReceipts は GET /api/plans/:shopping_plan_id/receipts のリクエストを処理します。
+func (h *PlanHandler) GetReceipts(c *gin.Context) {
+	output, err := h.planUsecase.GetReceipts(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"receipts": output})
+}
+
+// DeleteReceipt は DELETE /api/plans/:shopping_plan_id/receipts/:receipt_id のリクエストを処理します。
+// 購入済みにしたアイテムは元に戻しません。
+func (h *PlanHandler) DeleteReceipt(c *gin.Context) {
+	err := h.planUsecase.DeleteReceipt(c.Request.Context(), c.Param("shopping_plan_id"), c.Param("receipt_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetPlanSpend は GET /api/plans/:shopping_plan_id/spend のリクエストを処理します。
+// 計画に記録したレシートの支出を、食材分類ごと・食材ごとに集計して返します。
+func (h *PlanHandler) GetPlanSpend(c *gin.Context) {
+	output, err := h.planUsecase.GetPlanSpend(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetMonthlySpend は GET /api/spend/monthly のリクエストを処理します。
+// from、to クエリパラメータ (YYYY-MM) の期間の支出を、すべての計画について月ごとに集計して返します。
+func (h *PlanHandler) GetMonthlySpend(c *gin.Context) {
+	output, err := h.planUsecase.GetMonthlySpend(c.Request.Context(), usecase.GetMonthlySpendInput{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"months": output})
+}
@@ -1,44 +1,171 @@
 package handler
 
 import (
+	"expvar"
 	"net/http"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/usecase"
 )
 
 // NewRouter は、ハンドラーを受け取り、Ginのルーターエンジンをセットアップして返します。
-func NewRouter(planHandler *PlanHandler, ingredientHandler *IngredientHandler) *gin.Engine {
-	// gin.Default() は Logger と Recovery ミドルウェアを搭載したルーターを生成します
-	router := gin.Default()
+// リクエストは API仕様（spec）と照らし合わせます。debug が true（開発環境）の場合はレスポンスも照らし合わせ、
+// /debug/vars で expvar のすべての変数を返します（それ以外では古い計画の整理の指標だけを返します）。
+// 共有リンクとAPI仕様、セッション以外の /api は、計画の持ち主（ownerAuth）からのリクエストだけを受け付けます。
+func NewRouter(planHandler *PlanHandler, ingredientHandler *IngredientHandler, catalogHandler *CatalogHandler, storeLayoutHandler *StoreLayoutHandler, shareHandler *ShareHandler, spec *openapi3.T, debug bool, ownerAuth *OwnerAuth) *gin.Engine {
+	// gin.Default() と同じく Logger と Recovery ミドルウェアを使います。
+	// ログにはURLに付けたトークン（access_token）を残さないよう、値を伏せて出力します
+	router := gin.New()
+	router.Use(
+		gin.LoggerWithConfig(gin.LoggerConfig{Formatter: redactedLogFormatter}),
+		gin.RecoveryWithWriter(redactingWriter{w: gin.DefaultErrorWriter}),
+	)
 
 	// CORS (Cross-Origin Resource Sharing) の設定
 	// フロントエンドのURL (Viteのデフォルト開発サーバー) からのアクセスを許可します
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost"} // フロントエンドのオリジン
-	config.AllowMethods = []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Last-Event-ID", "If-Match"}
+	config.ExposeHeaders = []string{"ETag"}
+	// 持ち主のセッションのクッキーを送れるようにします
+	config.AllowCredentials = true
 	router.Use(cors.New(config))
 
+	// リクエスト (開発時はレスポンスも) をAPI仕様と照らし合わせます。エラーのレスポンスも検証できるよう ErrorHandler より前に置きます
+	router.Use(OpenAPIValidator(spec, debug))
+
+	// ハンドラーが記録したエラーを、エラーの種類に応じた application/problem+json のレスポンスにします
+	router.Use(ErrorHandler())
+	router.NoRoute(func(c *gin.Context) {
+		_ = c.Error(usecase.ErrNotFound)
+	})
+
 	// ヘルスチェック用のエンドポイント
 	router.GET("/health", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
 
+	// 実行中の指標 (古い計画の整理の件数など) を expvar の JSON で返すエンドポイント
+	// コマンドラインやメモリの統計も含むすべての変数は、開発環境でのみ返します
+	if debug {
+		router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	} else {
+		router.GET("/debug/vars", expvarSubsetHandler(publicVars))
+	}
+
 	// APIのルートグループ
 	api := router.Group("/api")
 	{
+		// API仕様 (OpenAPI 3)
+		api.GET("/openapi.json", func(c *gin.Context) {
+			c.JSON(http.StatusOK, spec)
+		})
+
+		// 持ち主のセッション (トークンと引き換えに HttpOnly のクッキーを発行する) の開始と終了
+		api.POST("/session", ownerAuth.CreateSession)
+		api.DELETE("/session", ownerAuth.DeleteSession)
+
+		// 共有リンクからのアクセス (トークンの範囲を確かめてから、計画のハンドラーで処理する)
+		shared := api.Group("/shared/:share_token")
+		{
+			readOnly := shareHandler.RequireShareScope(model.ShareReadOnly)
+			shared.GET("/plan", readOnly, planHandler.GetSharedPlan)
+			shared.GET("/menu-list", readOnly, planHandler.GetMenuList)
+			shared.GET("/ingredient-list", readOnly, planHandler.GetIngredientList)
+			shared.GET("/calendar.ics", readOnly, planHandler.GetMealCalendar)
+			shared.GET("/leftovers", readOnly, planHandler.GetLeftovers)
+			shared.GET("/events", readOnly, planHandler.StreamSharedPlanEvents)
+			shared.PATCH("/items", shareHandler.RequireShareScope(model.ShareCheckOff), shareHandler.CheckOffItems)
+		}
+	}
+
+	// 計画の持ち主だけが使えるAPI (計画やアイテムのIDで操作するため、共有リンクの範囲では使えない)
+	owner := api.Group("", ownerAuth.Require())
+	{
+		// URLに付けるトークン (ヘッダーもクッキーも付けられない場合に、そのパスへの GET でだけ短い間使える) の発行
+		owner.POST("/url-tokens", ownerAuth.CreateURLToken)
+
 		// 計画作成
-		api.POST("/create-new-plan", planHandler.CreateNewPlan)
+		owner.POST("/create-new-plan", planHandler.CreateNewPlan)
 
 		// メニューリスト取得
-		api.GET("/menu-list/:shopping_plan_id", planHandler.GetMenuList)
+		owner.GET("/menu-list/:shopping_plan_id", planHandler.GetMenuList)
+
+		// 計画の一覧 (開始日の新しい順、カーソルでページ送り) と計画の情報の取得・アーカイブ・削除
+		owner.GET("/plans", planHandler.ListPlans)
+		owner.GET("/plans/:shopping_plan_id", planHandler.GetPlan)
+		owner.PATCH("/plans/:shopping_plan_id", planHandler.UpdatePlan)
+		owner.DELETE("/plans/:shopping_plan_id", planHandler.DeletePlan)
+
+		// 献立のカレンダー (iCalendar) 取得
+		owner.GET("/plans/:shopping_plan_id/calendar.ics", planHandler.GetMealCalendar)
+
+		// 計画の変更の配信 (Server-Sent Events)
+		owner.GET("/plans/:shopping_plan_id/events", planHandler.StreamPlanEvents)
+
+		// 実際の買い物（レシート）の記録・一覧・削除と、計画の支出の集計
+		owner.POST("/plans/:shopping_plan_id/receipts", planHandler.RecordReceipt)
+		owner.GET("/plans/:shopping_plan_id/receipts", planHandler.GetReceipts)
+		owner.DELETE("/plans/:shopping_plan_id/receipts/:receipt_id", planHandler.DeleteReceipt)
+		owner.GET("/plans/:shopping_plan_id/spend", planHandler.GetPlanSpend)
+
+		// すべての計画の月ごとの支出
+		owner.GET("/spend/monthly", planHandler.GetMonthlySpend)
+
+		// 買い物計画の共有リンクの発行・一覧・無効化
+		owner.POST("/plans/:shopping_plan_id/share-tokens", shareHandler.CreateShareToken)
+		owner.GET("/plans/:shopping_plan_id/share-tokens", shareHandler.GetShareTokens)
+		owner.DELETE("/plans/:shopping_plan_id/share-tokens/:token_id", shareHandler.RevokeShareToken)
+
+		// 買い物リスト取得 (layout_id で店舗の売り場の順に並べる)
+		owner.GET("/ingredient-list/:shopping_plan_id", planHandler.GetIngredientList)
+
+		// 買い物リストへの手動のアイテムの追加 (自由入力またはカタログの食材)
+		owner.POST("/ingredient-list/:shopping_plan_id/items", ingredientHandler.AddShoppingItem)
+		// 買い物リストのアイテムのまとめて更新 (レジでまとめてチェックする場合など)
+		owner.PATCH("/ingredient-list/:shopping_plan_id/items", ingredientHandler.BatchUpdateShoppingItems)
+
+		// 複数の計画の買い物リストをまとめた取得と、まとめた行の購入済みチェック (元の計画のアイテムに反映)
+		owner.GET("/merged-ingredient-list", planHandler.GetMergedIngredientList)
+		owner.PATCH("/merged-ingredient-list/lines", ingredientHandler.CheckOffMergedLines)
+
+		// 必要量を超えて購入した食材（残りもの）の取得
+		owner.GET("/leftovers/:shopping_plan_id", planHandler.GetLeftovers)
+
+		// 店舗レイアウト（売り場の並び）の登録・取得・更新・削除
+		owner.POST("/store-layouts", storeLayoutHandler.CreateStoreLayout)
+		owner.GET("/store-layouts", storeLayoutHandler.GetStoreLayouts)
+		owner.GET("/store-layouts/:layout_id", storeLayoutHandler.GetStoreLayout)
+		owner.PUT("/store-layouts/:layout_id", storeLayoutHandler.UpdateStoreLayout)
+		owner.DELETE("/store-layouts/:layout_id", storeLayoutHandler.DeleteStoreLayout)
+
+		// 買い物リストのアイテム更新 (購入済みチェック、必要量・購入した量の記録)
+		owner.PATCH("/shopping_ingredient_items/:item_id", ingredientHandler.UpdateShoppingIngredientItem)
+		// 手動で追加したアイテムの削除
+		owner.DELETE("/shopping_ingredient_items/:item_id", ingredientHandler.DeleteShoppingItem)
+
+		// メニュー（調理手順を含む）取得
+		owner.GET("/menus/:menu_id", catalogHandler.GetMenu)
+		owner.GET("/menus/:menu_id/versions", catalogHandler.GetMenuVersions)
+
+		// 手持ちの食材で作れるメニューの検索
+		owner.POST("/menus/cookable", catalogHandler.FindCookableMenus)
+
+		// 食材の検索（表記ゆれ・別名を含む）と別名の追加
+		owner.GET("/ingredients/search", catalogHandler.SearchIngredients)
+		owner.POST("/ingredients/:ingredient_id/aliases", catalogHandler.AddIngredientAliases)
 
-		// 買い物リスト取得
-		api.GET("/ingredient-list/:shopping_plan_id", planHandler.GetIngredientList)
+		// レシピの一括取り込み/書き出し (YAML, JSON, CSV)
+		owner.POST("/recipes/import", catalogHandler.ImportRecipes)
+		owner.GET("/recipes/export", catalogHandler.ExportRecipes)
 
-		// 買い物リストのアイテム更新 (購入済みチェック)
-		api.PATCH("/shopping_ingredient_items/:item_id", ingredientHandler.UpdateShoppingIngredientItem)
+		// schema.org/Recipe を埋め込んだHTMLからメニューの下書きを作成
+		owner.POST("/recipes/draft", catalogHandler.DraftRecipe)
 	}
 
 	return router
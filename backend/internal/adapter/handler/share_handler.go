@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/usecase"
+)
+
+// ShareHandler は、買い物計画の共有リンク関連のHTTPリクエストを処理します。
+type ShareHandler struct {
+	shareUsecase usecase.ShareUsecase
+	planUsecase  usecase.PlanUsecase // 共有リンクからの購入済みのチェックに利用
+}
+
+// NewShareHandler は新しい ShareHandler のインスタンスを生成します。
+func NewShareHandler(shareUsecase usecase.ShareUsecase, planUsecase usecase.PlanUsecase) *ShareHandler {
+	return &ShareHandler{shareUsecase: shareUsecase, planUsecase: planUsecase}
+}
+
+// CreateShareToken は POST /api/plans/:shopping_plan_id/share-tokens のリクエストを処理します。
+// トークンは発行時のレスポンスでしか返さないため、呼び出し側で共有リンクとして渡します。
+func (h *ShareHandler) CreateShareToken(c *gin.Context) {
+	var req struct {
+		Scope     string     `json:"scope" binding:"required"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.shareUsecase.CreateShareToken(c.Request.Context(), usecase.CreateShareTokenInput{
+		PlanID:    c.Param("shopping_plan_id"),
+		Scope:     req.Scope,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+// GetShareTokens は GET /api/plans/:shopping_plan_id/share-tokens のリクエストを処理します。
+func (h *ShareHandler) GetShareTokens(c *gin.Context) {
+	output, err := h.shareUsecase.GetShareTokens(c.Request.Context(), c.Param("shopping_plan_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"share_tokens": output})
+}
+
+// RevokeShareToken は DELETE /api/plans/:shopping_plan_id/share-tokens/:token_id のリクエストを処理します。
+func (h *ShareHandler) RevokeShareToken(c *gin.Context) {
+	err := h.shareUsecase.RevokeShareToken(c.Request.Context(), c.Param("shopping_plan_id"), c.Param("token_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RequireShareScope は、パスの :share_token が有効で required の操作を許可しているかを確かめるミドルウェアを返します。
+// 許可されている場合は、共有している計画のIDを :shopping_plan_id パラメータとして後続のハンドラーに渡します。
+func (h *ShareHandler) RequireShareScope(required model.ShareScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, err := h.shareUsecase.AuthorizeShareToken(c.Request.Context(), c.Param("share_token"), required)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Params = append(c.Params, gin.Param{Key: "shopping_plan_id", Value: access.PlanID})
+		c.Next()
+	}
+}
+
+// CheckOffItems は PATCH /api/shared/:share_token/items のリクエストを処理します。
+// 共有リンクからは購入済みのチェックと購入した量の記録だけができ、必要量は変更できません。
+// 版の指定（If-Match と各アイテムの version）は PATCH /api/ingredient-list/:shopping_plan_id/items と同じです。
+func (h *ShareHandler) CheckOffItems(c *gin.Context) {
+	expectedPlanVersion, ok := bindIfMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Items []struct {
+			ID              string   `json:"id" binding:"required"`
+			Bought          *bool    `json:"bought"`
+			PurchasedAmount *float64 `json:"purchased_amount"`
+			Version         *int     `json:"version"`
+		} `json:"items" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	items := make([]usecase.UpdateShoppingIngredientItemInput, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = usecase.UpdateShoppingIngredientItemInput{
+			ItemID:          item.ID,
+			Bought:          item.Bought,
+			PurchasedAmount: item.PurchasedAmount,
+			ExpectedVersion: item.Version,
+		}
+	}
+
+	output, err := h.planUsecase.BatchUpdateShoppingItems(c.Request.Context(), usecase.BatchUpdateShoppingItemsInput{
+		PlanID:              c.Param("shopping_plan_id"),
+		Items:               items,
+		ExpectedPlanVersion: expectedPlanVersion,
+	})
+	respondShoppingItemResults(c, output, err)
+}
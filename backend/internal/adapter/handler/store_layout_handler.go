@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// StoreLayoutHandler は、店舗レイアウト（売り場の並び）関連のHTTPリクエストを処理します。
+type StoreLayoutHandler struct {
+	storeLayoutUsecase usecase.StoreLayoutUsecase
+}
+
+// NewStoreLayoutHandler は新しい StoreLayoutHandler のインスタンスを生成します。
+func NewStoreLayoutHandler(storeLayoutUsecase usecase.StoreLayoutUsecase) *StoreLayoutHandler {
+	return &StoreLayoutHandler{storeLayoutUsecase: storeLayoutUsecase}
+}
+
+// storeLayoutRequest は、店舗レイアウトの登録・更新のリクエストBodyです。
+type storeLayoutRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Sections []struct {
+		Name            string   `json:"name" binding:"required"`
+		IngredientTypes []string `json:"ingredient_types"`
+		Ingredients     []string `json:"ingredients"`
+	} `json:"sections" binding:"required,min=1,dive"`
+}
+
+func (r *storeLayoutRequest) toInput(layoutID string) usecase.SaveStoreLayoutInput {
+	input := usecase.SaveStoreLayoutInput{LayoutID: layoutID, Name: r.Name}
+	for _, section := range r.Sections {
+		input.Sections = append(input.Sections, &usecase.StoreSectionInput{
+			Name:            section.Name,
+			IngredientTypes: section.IngredientTypes,
+			Ingredients:     section.Ingredients,
+		})
+	}
+	return input
+}
+
+// CreateStoreLayout は POST /api/store-layouts のリクエストを処理します。
+func (h *StoreLayoutHandler) CreateStoreLayout(c *gin.Context) {
+	var req storeLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.storeLayoutUsecase.CreateStoreLayout(c.Request.Context(), req.toInput(""))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+// GetStoreLayouts は GET /api/store-layouts のリクエストを処理します。
+func (h *StoreLayoutHandler) GetStoreLayouts(c *gin.Context) {
+	output, err := h.storeLayoutUsecase.GetStoreLayouts(c.Request.Context())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"layouts": output})
+}
+
+// GetStoreLayout は GET /api/store-layouts/:layout_id のリクエストを処理します。
+func (h *StoreLayoutHandler) GetStoreLayout(c *gin.Context) {
+	output, err := h.storeLayoutUsecase.GetStoreLayout(c.Request.Context(), c.Param("layout_id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// UpdateStoreLayout は PUT /api/store-layouts/:layout_id のリクエストを処理します。
+// 売り場は丸ごと置き換えられます。
+func (h *StoreLayoutHandler) UpdateStoreLayout(c *gin.Context) {
+	var req storeLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithError(c, invalidRequest(err))
+		return
+	}
+
+	output, err := h.storeLayoutUsecase.UpdateStoreLayout(c.Request.Context(), req.toInput(c.Param("layout_id")))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// DeleteStoreLayout は DELETE /api/store-layouts/:layout_id のリクエストを処理します。
+func (h *StoreLayoutHandler) DeleteStoreLayout(c *gin.Context) {
+	if err := h.storeLayoutUsecase.DeleteStoreLayout(c.Request.Context(), c.Param("layout_id")); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,168 @@
+// Package icalendar は、食事の計画をiCalendar（RFC 5545）形式で書き出します。
+// カレンダーアプリで購読・取り込みできるよう、食事1つを1つの予定（VEVENT）にします。
+package icalendar
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/usecase"
+)
+
+// ContentType は、iCalendarのContent-Typeです。
+const ContentType = "text/calendar; charset=utf-8"
+
+// Clock は、1日の中の時刻（時:分）です。
+type Clock struct {
+	Hour   int
+	Minute int
+}
+
+// ParseClock は、"HH:MM" 形式の文字列から Clock を返します。
+func ParseClock(s string) (Clock, error) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	hour, errH := strconv.Atoi(h)
+	minute, errM := strconv.Atoi(m)
+	if !ok || errH != nil || errM != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return Clock{}, fmt.Errorf("時刻は HH:MM の形式で指定してください: %q", s)
+	}
+	return Clock{Hour: hour, Minute: minute}, nil
+}
+
+func (c Clock) String() string {
+	return fmt.Sprintf("%02d:%02d", c.Hour, c.Minute)
+}
+
+// Options は、予定の時刻の決め方です。
+type Options struct {
+	MealTimes map[model.MealPeriod]Clock // 時間帯ごとの食事の開始時刻
+	Duration  time.Duration              // 予定の長さ
+	Location  *time.Location             // 食事の時刻のタイムゾーン
+}
+
+// NewOptions は、時間帯ごとの開始時刻（"HH:MM"）、予定の長さ（分）、タイムゾーン名から Options を作成します。
+func NewOptions(morning, lunch, dinner string, durationMinutes int, timeZone string) (Options, error) {
+	opts := Options{MealTimes: make(map[model.MealPeriod]Clock, 3), Duration: time.Duration(durationMinutes) * time.Minute}
+	for period, s := range map[model.MealPeriod]string{model.Morning: morning, model.Lunch: lunch, model.Dinner: dinner} {
+		clock, err := ParseClock(s)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.MealTimes[period] = clock
+	}
+	if durationMinutes <= 0 {
+		return Options{}, fmt.Errorf("予定の長さは1分以上を指定してください: %d", durationMinutes)
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return Options{}, fmt.Errorf("タイムゾーンを読み込めません: %w", err)
+	}
+	opts.Location = loc
+	return opts, nil
+}
+
+// WithMealTime は、時間帯の開始時刻を差し替えた Options を返します。
+func (o Options) WithMealTime(period model.MealPeriod, clock Clock) Options {
+	times := make(map[model.MealPeriod]Clock, len(o.MealTimes))
+	for p, c := range o.MealTimes {
+		times[p] = c
+	}
+	times[period] = clock
+	o.MealTimes = times
+	return o
+}
+
+// periodLabels は、予定の件名に使う時間帯の名前です。
+var periodLabels = map[model.MealPeriod]string{
+	model.Morning: "朝ごはん",
+	model.Lunch:   "昼ごはん",
+	model.Dinner:  "晩ごはん",
+}
+
+// Encode は、計画の食事をiCalendar形式で書き出します。
+// 予定の時刻は、食事の日付と時間帯ごとの開始時刻から決め、UTCで書き出します。説明にはメニューの材料を入れます。
+func Encode(w io.Writer, meals []*usecase.MenuOutput, opts Options, now time.Time) error {
+	var b strings.Builder
+	line := func(name, value string) {
+		b.WriteString(fold(name + ":" + value))
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//meal-compass//meal plan//JA")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeText("献立"))
+
+	stamp := formatUTC(now)
+	for _, meal := range meals {
+		date, err := time.ParseInLocation("2006-01-02", meal.Date, opts.Location)
+		if err != nil {
+			return fmt.Errorf("食事の日付を解釈できません: %w", err)
+		}
+		period := model.MealPeriod(meal.MealPeriod)
+		clock := opts.MealTimes[period]
+		start := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour, clock.Minute, 0, 0, opts.Location)
+
+		line("BEGIN", "VEVENT")
+		line("UID", meal.MealID+"@meal-compass")
+		line("DTSTAMP", stamp)
+		line("DTSTART", formatUTC(start))
+		line("DTEND", formatUTC(start.Add(opts.Duration)))
+		line("SUMMARY", escapeText(periodLabels[period]+": "+meal.MenuName))
+		line("DESCRIPTION", escapeText(description(meal)))
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// description は、予定の説明（材料の一覧と、行った代用）を組み立てます。
+func description(meal *usecase.MenuOutput) string {
+	var b strings.Builder
+	b.WriteString("材料（1人前）\n")
+	for _, ing := range meal.Ingredients {
+		b.WriteString("・" + ing.Name + " " + strconv.FormatFloat(ing.Amount, 'f', -1, 64) + ing.Unit + "\n")
+	}
+	for i, sub := range meal.Substitutions {
+		if i == 0 {
+			b.WriteString("\n代用\n")
+		}
+		b.WriteString("・" + sub.From + " → " + sub.To + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// escapeText は、TEXT型の値の特殊文字をエスケープします。
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// fold は、内容行を75オクテットごとに折り返し（継続行は空白で始める）、CRLFで終えます。マルチバイト文字の途中では折り返しません。
+func fold(s string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package icalendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/usecase"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Clock
+		wantErr bool
+	}{
+		{input: "07:30", want: Clock{Hour: 7, Minute: 30}},
+		{input: " 7:05 ", want: Clock{Hour: 7, Minute: 5}},
+		{input: "23:59", want: Clock{Hour: 23, Minute: 59}},
+		{input: "24:00", wantErr: true},
+		{input: "12:60", wantErr: true},
+		{input: "-1:00", wantErr: true},
+		{input: "1200", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseClock(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClock(%q) = %v, %v, want %v (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNewOptions(t *testing.T) {
+	tests := []struct {
+		name                   string
+		morning, lunch, dinner string
+		duration               int
+		timeZone               string
+		wantErr                bool
+	}{
+		{name: "正しい指定", morning: "07:00", lunch: "12:00", dinner: "19:00", duration: 30, timeZone: "UTC"},
+		{name: "時刻の形式が不正", morning: "7時", lunch: "12:00", dinner: "19:00", duration: 30, timeZone: "UTC", wantErr: true},
+		{name: "長さが0分", morning: "07:00", lunch: "12:00", dinner: "19:00", duration: 0, timeZone: "UTC", wantErr: true},
+		{name: "不明なタイムゾーン", morning: "07:00", lunch: "12:00", dinner: "19:00", duration: 30, timeZone: "Mars/Olympus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := NewOptions(tt.morning, tt.lunch, tt.dinner, tt.duration, tt.timeZone)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := opts.MealTimes[model.Dinner]; got != (Clock{Hour: 19}) {
+				t.Errorf("MealTimes[DINNER] = %v, want 19:00", got)
+			}
+			if opts.Duration != time.Duration(tt.duration)*time.Minute {
+				t.Errorf("Duration = %v, want %d minutes", opts.Duration, tt.duration)
+			}
+		})
+	}
+}
+
+func TestWithMealTimeDoesNotModifyOriginal(t *testing.T) {
+	opts := Options{MealTimes: map[model.MealPeriod]Clock{model.Morning: {Hour: 7}}}
+	changed := opts.WithMealTime(model.Morning, Clock{Hour: 9})
+	if opts.MealTimes[model.Morning].Hour != 7 || changed.MealTimes[model.Morning].Hour != 9 {
+		t.Errorf("WithMealTime() original = %v, changed = %v", opts.MealTimes, changed.MealTimes)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	opts := Options{
+		MealTimes: map[model.MealPeriod]Clock{model.Morning: {Hour: 7}, model.Lunch: {Hour: 12}, model.Dinner: {Hour: 19, Minute: 30}},
+		Duration:  45 * time.Minute,
+		Location:  jst,
+	}
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, jst)
+
+	tests := []struct {
+		name    string
+		meals   []*usecase.MenuOutput
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "食事なし",
+			want: []string{
+				"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//meal-compass//meal plan//JA", "CALSCALE:GREGORIAN",
+				"METHOD:PUBLISH", "X-WR-CALNAME:献立", "END:VCALENDAR",
+			},
+		},
+		{
+			name: "食事ごとの予定（時刻はUTC、説明に材料と代用）",
+			meals: []*usecase.MenuOutput{
+				{
+					MealID: "meal-1", Date: "2024-04-02", MealPeriod: "MORNING", MenuName: "トースト",
+					Ingredients: []*usecase.MenuIngredientInfo{{Name: "食パン", Amount: 1, Unit: "枚"}},
+				},
+				{
+					MealID: "meal-2", Date: "2024-04-02", MealPeriod: "DINNER", MenuName: "鮭, きのこ; ホイル焼き",
+					Ingredients:   []*usecase.MenuIngredientInfo{{Name: "鶏肉", Amount: 0.5, Unit: "枚"}},
+					Substitutions: []*usecase.SubstitutionOutput{{From: "鮭", To: "鶏肉"}},
+				},
+			},
+			want: []string{
+				"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//meal-compass//meal plan//JA", "CALSCALE:GREGORIAN",
+				"METHOD:PUBLISH", "X-WR-CALNAME:献立",
+				"BEGIN:VEVENT", "UID:meal-1@meal-compass", "DTSTAMP:20240401T000000Z",
+				"DTSTART:20240401T220000Z", "DTEND:20240401T224500Z",
+				"SUMMARY:朝ごはん: トースト", `DESCRIPTION:材料（1人前）\n・食パン 1枚`, "END:VEVENT",
+				"BEGIN:VEVENT", "UID:meal-2@meal-compass", "DTSTAMP:20240401T000000Z",
+				"DTSTART:20240402T103000Z", "DTEND:20240402T111500Z",
+				`SUMMARY:晩ごはん: 鮭\, きのこ\; ホイル焼き`, `DESCRIPTION:材料（1人前）\n・鶏肉 0.5枚\n\n代用\n・鮭 → 鶏肉`, "END:VEVENT",
+				"END:VCALENDAR",
+			},
+		},
+		{
+			name:    "日付の形式が不正",
+			meals:   []*usecase.MenuOutput{{MealID: "meal-1", Date: "2024/04/02", MealPeriod: "LUNCH"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			err := Encode(&b, tt.meals, opts, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Encode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := unfold(b.String()); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Encode() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "肉じゃが", want: "肉じゃが"},
+		{input: `a\b`, want: `a\\b`},
+		{input: "a;b,c", want: `a\;b\,c`},
+		{input: "1行目\r\n2行目\n3行目", want: `1行目\n2行目\n3行目`},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.input); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "短い行", input: "SUMMARY:朝ごはん"},
+		{name: "ちょうど75オクテット", input: strings.Repeat("a", 75)},
+		{name: "ASCIIの長い行", input: "DESCRIPTION:" + strings.Repeat("abcdefghij", 20)},
+		{name: "マルチバイト文字の長い行", input: "DESCRIPTION:" + strings.Repeat("材料（1人前）", 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fold(tt.input)
+			if !strings.HasSuffix(got, "\r\n") {
+				t.Fatalf("fold() = %q, want CRLF at the end", got)
+			}
+			lines := strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > 75 {
+					t.Errorf("line %d is %d octets, want at most 75", i, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a multibyte character: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if joined := unfold(got); len(joined) != 1 || joined[0] != tt.input {
+				t.Errorf("unfold(fold()) = %q, want %q", joined, tt.input)
+			}
+		})
+	}
+}
+
+// unfold は、折り返された内容行を元に戻し、行ごとに分けて返します（RFC 5545 3.1）。
+func unfold(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n ", "")
+	return strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+}
@@ -0,0 +1,211 @@
+// Package listexport は、買い物リストをテキスト/Markdown/CSV/PDFに書き出します。
+// 印刷したりチャットに貼り付けたりして、Webアプリを開かずに買い物できるようにするためのものです。
+package listexport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// Format は、書き出すファイル形式を表す型です。
+type Format string
+
+const (
+	FormatJSON     Format = "json" // APIの通常のレスポンス。書き出しは行わない
+	FormatText     Format = "txt"
+	FormatMarkdown Format = "md"
+	FormatCSV      Format = "csv"
+	FormatPDF      Format = "pdf"
+)
+
+// title は、書き出す買い物リストの見出しです。
+const title = "買い物リスト"
+
+// ParseFormat は、文字列（"json", "txt", "text", "md", "markdown", "csv", "pdf"）から Format を返します。
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return FormatJSON, nil
+	case "txt", "text":
+		return FormatText, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "csv":
+		return FormatCSV, nil
+	case "pdf":
+		return FormatPDF, nil
+	}
+	return "", fmt.Errorf("未対応の形式です: %q", s)
+}
+
+// FormatFromAccept は、Acceptヘッダーの値から Format を選びます。
+// 書き出しに対応するメディアタイプが含まれていなければ（"*/*" を含む）、JSONを返します。
+func FormatFromAccept(accept string) Format {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/json":
+			return FormatJSON
+		case "text/plain":
+			return FormatText
+		case "text/markdown":
+			return FormatMarkdown
+		case "text/csv":
+			return FormatCSV
+		case "application/pdf":
+			return FormatPDF
+		}
+	}
+	return FormatJSON
+}
+
+// ContentType は、Format に対応するContent-Typeを返します。
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// Encode は、買い物リストを指定された形式で書き出します。アイテムは売り場（list.Sections）ごとにまとめます。
+func Encode(w io.Writer, format Format, list *usecase.ShoppingListOutput) error {
+	switch format {
+	case FormatText:
+		return encodeText(w, list)
+	case FormatMarkdown:
+		return encodeMarkdown(w, list)
+	case FormatCSV:
+		return encodeCSV(w, list)
+	case FormatPDF:
+		return encodePDF(w, list)
+	}
+	return fmt.Errorf("未対応の形式です: %q", format)
+}
+
+// encodeText は、LINEなどのチャットにそのまま貼り付けられるチェックリストを書き出します。
+func encodeText(w io.Writer, list *usecase.ShoppingListOutput) error {
+	var b strings.Builder
+	b.WriteString("【" + listTitle(list) + "】\n")
+	for _, section := range list.Sections {
+		b.WriteString("\n■ " + section.Name + "\n")
+		for _, item := range section.Ingredients {
+			mark := "☐"
+			if item.Bought {
+				mark = "☑"
+			}
+			b.WriteString(mark + " " + itemLine(item) + "\n")
+			if item.Note != nil && *item.Note != "" {
+				b.WriteString("　※" + *item.Note + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encodeMarkdown は、Markdownのタスクリストを書き出します。
+func encodeMarkdown(w io.Writer, list *usecase.ShoppingListOutput) error {
+	var b strings.Builder
+	b.WriteString("# " + escapeMarkdown(listTitle(list)) + "\n")
+	for _, section := range list.Sections {
+		b.WriteString("\n## " + escapeMarkdown(section.Name) + "\n\n")
+		for _, item := range section.Ingredients {
+			mark := "[ ]"
+			if item.Bought {
+				mark = "[x]"
+			}
+			b.WriteString("- " + mark + " " + escapeMarkdown(itemLine(item)))
+			if item.Note != nil && *item.Note != "" {
+				b.WriteString("（" + escapeMarkdown(*item.Note) + "）")
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// csvHeader は、買い物リストのCSVの列です。
+var csvHeader = []string{"section", "name", "type", "amount", "unit", "remaining_amount", "purchased_amount", "bought", "manual", "note", "trip_date"}
+
+// encodeCSV は、1行に1アイテムのCSVを書き出します。
+// 表計算ソフト（Excel）で開いても文字化けしないよう、先頭にUTF-8のBOMを付けます。
+func encodeCSV(w io.Writer, list *usecase.ShoppingListOutput) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, section := range list.Sections {
+		for _, item := range section.Ingredients {
+			record := []string{
+				section.Name, item.Name, item.Type, formatFloat(item.Amount), item.Unit, formatFloat(item.RemainingAmount),
+				"", strconv.FormatBool(item.Bought), strconv.FormatBool(item.Manual), "", item.TripDate,
+			}
+			if item.PurchasedAmount != nil {
+				record[6] = formatFloat(*item.PurchasedAmount)
+			}
+			if item.Note != nil {
+				record[9] = *item.Note
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// listTitle は、買い物リストの見出しを返します。買いに行く日で絞り込んだ場合は、その日を添えます。
+func listTitle(list *usecase.ShoppingListOutput) string {
+	if list.TripDate != nil {
+		return title + "（" + *list.TripDate + "）"
+	}
+	return title
+}
+
+// itemLine は、アイテムを「名前 数量単位」の1行で表します。一部だけ購入したアイテムは、残りの量を添えます。
+func itemLine(item *usecase.IngredientListOutput) string {
+	line := item.Name + " " + formatQuantity(item.Amount, item.Unit)
+	if !item.Bought && item.PurchasedAmount != nil && item.RemainingAmount > 0 {
+		line += "（残り" + formatQuantity(item.RemainingAmount, item.Unit) + "）"
+	}
+	return line
+}
+
+func formatQuantity(amount float64, unit string) string {
+	return formatFloat(amount) + unit
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,245 @@
+package listexport
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// PDFはA4縦で、フォントを埋め込まずにPDFの標準の日本語フォント（Adobe-Japan1の平成角ゴシック）を指定します。
+// ビューアーがシステムの日本語フォントで表示するため、フォントファイルを同梱せずに日本語を扱えます。
+
+// A4の大きさと余白（単位はポイント）
+const (
+	pageWidth    = 595.28
+	pageHeight   = 841.89
+	pageMargin   = 48.0
+	contentWidth = pageWidth - pageMargin*2
+)
+
+// 文字の大きさと行の高さ（単位はポイント）
+const (
+	titleSize      = 18.0
+	sectionSize    = 13.0
+	itemSize       = 11.0
+	noteSize       = 9.0
+	footerSize     = 9.0
+	itemLeading    = 20.0
+	noteLeading    = 13.0
+	sectionLeading = 28.0
+	checkboxSize   = 9.0
+)
+
+const (
+	pdfFontName = "HeiseiKakuGo-W5"
+	// pdfFontWidths は、半角の英数字（CID 1〜95）と半角カナ（CID 231〜632）を半角幅にするための字幅の指定です。それ以外は全角幅（DW）です。
+	pdfFontWidths = "[1 95 500 231 632 500]"
+)
+
+// pdfPage は、1ページ分の描画命令を組み立てます。
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+func (p *pdfPage) text(x, y, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, encodeUTF16Hex(s))
+}
+
+func (p *pdfPage) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+func (p *pdfPage) rect(x, y, w, h, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f %.2f %.2f re S\n", width, x, y, w, h)
+}
+
+func (p *pdfPage) gray(level float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f G\n", level, level)
+}
+
+// pdfLayout は、ページをまたいでアイテムを上から順に配置します。
+type pdfLayout struct {
+	pages []*pdfPage
+	y     float64 // 次に描く行のベースライン
+}
+
+func (l *pdfLayout) page() *pdfPage {
+	return l.pages[len(l.pages)-1]
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &pdfPage{})
+	l.y = pageHeight - pageMargin - itemSize
+}
+
+// reserve は、高さ h の行が今のページに収まらなければ改ページします。
+func (l *pdfLayout) reserve(h float64) {
+	// ページ番号の分の余白を残す
+	if l.y-h < pageMargin+footerSize*2 {
+		l.newPage()
+	}
+}
+
+// encodePDF は、売り場ごとにまとめたチェックボックス付きの買い物リストを、印刷用のA4のPDFで書き出します。
+func encodePDF(w io.Writer, list *usecase.ShoppingListOutput) error {
+	l := &pdfLayout{}
+	l.newPage()
+	l.y = pageHeight - pageMargin - titleSize
+	l.page().text(pageMargin, l.y, titleSize, listTitle(list))
+	l.y -= titleSize
+
+	for _, section := range list.Sections {
+		// 売り場の見出しだけがページの最後に残らないよう、最初のアイテムと合わせて確保する
+		l.reserve(sectionLeading + itemLeading)
+		l.y -= sectionLeading
+		l.page().text(pageMargin, l.y, sectionSize, section.Name)
+		l.page().line(pageMargin, l.y-4, pageMargin+contentWidth, l.y-4, 0.8)
+		l.y -= 6
+
+		for _, item := range section.Ingredients {
+			var noteLines []string
+			if item.Note != nil && *item.Note != "" {
+				noteLines = wrapText("※"+*item.Note, noteSize, contentWidth-checkboxSize-8)
+			}
+			l.reserve(itemLeading + float64(len(noteLines))*noteLeading)
+			l.y -= itemLeading
+			drawItem(l.page(), l.y, item)
+			for _, note := range noteLines {
+				l.y -= noteLeading
+				l.page().gray(0.35)
+				l.page().text(pageMargin+checkboxSize+8, l.y, noteSize, note)
+				l.page().gray(0)
+			}
+		}
+	}
+
+	for i, page := range l.pages {
+		label := fmt.Sprintf("%d / %d", i+1, len(l.pages))
+		page.text(pageWidth/2-textWidth(label, footerSize)/2, pageMargin/2, footerSize, label)
+	}
+	return writePDF(w, l.pages)
+}
+
+// drawItem は、チェックボックス、名前、数量を1行に描きます。購入済みのアイテムはチェックを入れて薄く表示します。
+func drawItem(p *pdfPage, y float64, item *usecase.IngredientListOutput) {
+	boxY := y - 1
+	p.rect(pageMargin, boxY, checkboxSize, checkboxSize, 0.8)
+	if item.Bought {
+		p.line(pageMargin+1.5, boxY+4.5, pageMargin+3.8, boxY+1.8, 1.2)
+		p.line(pageMargin+3.8, boxY+1.8, pageMargin+8, boxY+8, 1.2)
+		p.gray(0.55)
+	}
+
+	quantity := formatQuantity(item.Amount, item.Unit)
+	if !item.Bought && item.PurchasedAmount != nil && item.RemainingAmount > 0 {
+		quantity = "残り" + formatQuantity(item.RemainingAmount, item.Unit) + " / " + quantity
+	}
+	quantityX := pageMargin + contentWidth - textWidth(quantity, itemSize)
+	nameX := pageMargin + checkboxSize + 8
+	name := truncateText(item.Name, itemSize, quantityX-nameX-12)
+	p.text(nameX, y, itemSize, name)
+	p.text(quantityX, y, itemSize, quantity)
+	p.line(nameX+textWidth(name, itemSize)+6, y+1, quantityX-6, y+1, 0.3)
+	if item.Bought {
+		p.gray(0)
+	}
+}
+
+// textWidth は、文字列の幅を半角を0.5文字、それ以外を1文字として見積もります。
+func textWidth(s string, size float64) float64 {
+	width := 0.0
+	for _, r := range s {
+		if isHalfWidth(r) {
+			width += 0.5
+		} else {
+			width += 1
+		}
+	}
+	return width * size
+}
+
+func isHalfWidth(r rune) bool {
+	return (r >= 0x20 && r <= 0x7e) || (r >= 0xff61 && r <= 0xff9f)
+}
+
+// truncateText は、幅に収まらない文字列を「…」で切り詰めます。
+func truncateText(s string, size, maxWidth float64) string {
+	if textWidth(s, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// wrapText は、文字列を幅に収まるように折り返します。
+func wrapText(s string, size, maxWidth float64) []string {
+	var lines []string
+	var current []rune
+	for _, r := range s {
+		if len(current) > 0 && textWidth(string(append(current, r)), size) > maxWidth {
+			lines = append(lines, string(current))
+			current = current[:0]
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
+
+// encodeUTF16Hex は、文字列をフォントのエンコーディング（UniJIS-UTF16-H）に合わせてUTF-16BEの16進文字列にします。
+func encodeUTF16Hex(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	return b.String()
+}
+
+// writePDF は、ページの描画命令からPDFのファイルを組み立てます。
+// オブジェクトは 1: カタログ、2: ページツリー、3〜5: フォント、6以降: ページと描画命令 の順に並べます。
+func writePDF(w io.Writer, pages []*pdfPage) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type0 /BaseFont /" + pdfFontName + " /Encoding /UniJIS-UTF16-H /DescendantFonts [4 0 R] >>")
+	object("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /" + pdfFontName +
+		" /CIDSystemInfo << /Registry (Adobe) /Ordering (Japan1) /Supplement 2 >> /FontDescriptor 5 0 R /DW 1000 /W " + pdfFontWidths + " >>")
+	object("<< /Type /FontDescriptor /FontName /" + pdfFontName +
+		" /Flags 4 /FontBBox [-92 -250 1010 922] /ItalicAngle 0 /Ascent 752 /Descent -221 /CapHeight 737 /StemV 114 >>")
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 7+i*2))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,100 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// responseDTOs は、レスポンスとして返すユースケースのDTOです。キーはAPI仕様の components.schemas での名前です。
+// ハンドラーが返すDTOを増やしたら、ここにも加えます。
+var responseDTOs = map[string]any{
+	"CreatePlanOutput":               usecase.CreatePlanOutput{},
+	"MenuOutput":                     usecase.MenuOutput{},
+	"ShoppingListOutput":             usecase.ShoppingListOutput{},
+	"IngredientListOutput":           usecase.IngredientListOutput{},
+	"BatchUpdateShoppingItemsOutput": usecase.BatchUpdateShoppingItemsOutput{},
+	"MergedShoppingListOutput":       usecase.MergedShoppingListOutput{},
+	"MergedShoppingLineOutput":       usecase.MergedShoppingLineOutput{},
+	"LeftoverOutput":                 usecase.LeftoverOutput{},
+	"PlanListOutput":                 usecase.PlanListOutput{},
+	"PlanDetailOutput":               usecase.PlanDetailOutput{},
+	"SharedPlanOutput":               usecase.SharedPlanOutput{},
+	"ReceiptOutput":                  usecase.ReceiptOutput{},
+	"PlanSpendOutput":                usecase.PlanSpendOutput{},
+	"MonthlySpendOutput":             usecase.MonthlySpendOutput{},
+	"ShareTokenOutput":               usecase.ShareTokenOutput{},
+	"StoreLayoutOutput":              usecase.StoreLayoutOutput{},
+	"MenuDetailOutput":               usecase.MenuDetailOutput{},
+	"MenuVersionOutput":              usecase.MenuVersionOutput{},
+	"FindCookableMenusOutput":        usecase.FindCookableMenusOutput{},
+	"IngredientSearchResult":         usecase.IngredientSearchResult{},
+	"IngredientAliasesOutput":        usecase.IngredientAliasesOutput{},
+	"ImportRecipesOutput":            usecase.ImportRecipesOutput{},
+	"DraftRecipeOutput":              usecase.DraftRecipeOutput{},
+}
+
+// generateSchemas は、responseDTOs のスキーマを json タグから生成して schemas に加えます。
+// 入れ子の構造体はそれぞれのスキーマの中に展開します。
+func generateSchemas(schemas openapi3.Schemas) error {
+	for name, dto := range responseDTOs {
+		if _, ok := schemas[name]; ok {
+			return fmt.Errorf("スキーマ %s がAPI仕様にすでに定義されています", name)
+		}
+		ref, err := openapi3gen.NewSchemaRefForValue(dto, nil, openapi3gen.SchemaCustomizer(customizeSchema))
+		if err != nil {
+			return fmt.Errorf("スキーマ %s の生成に失敗しました: %w", name, err)
+		}
+		schemas[name] = ref
+	}
+	return nil
+}
+
+// customizeSchema は、生成したスキーマを encoding/json の出力に合わせます。
+// nil のスライスとマップは null になるため nullable とし、omitempty の無い項目は必ず出力されるため required とします。
+func customizeSchema(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map:
+		schema.Nullable = true
+	case reflect.Struct:
+		if schema.Type.Is(openapi3.TypeObject) {
+			schema.Required = requiredFields(t)
+		}
+	}
+	return nil
+}
+
+// requiredFields は、構造体の json タグから、必ず出力される項目の名前を返します。埋め込まれた構造体の項目も含みます。
+func requiredFields(t reflect.Type) []string {
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("json")
+		if field.Anonymous && !hasTag {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				required = append(required, requiredFields(ft)...)
+			}
+			continue
+		}
+		if !field.IsExported() || !hasTag || tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			required = append(required, name)
+		}
+	}
+	return required
+}
@@ -0,0 +1,112 @@
+// Package openapi は、APIの仕様（OpenAPI 3）を読み込み、Ginのルートと照らし合わせます。
+// 仕様のうちパスとリクエストは openapi.yaml に書き、レスポンスのスキーマはユースケースのDTOから生成します。
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+)
+
+// BasePath は、API仕様のパスの前に付くGinのルートグループのパスです（servers の url と同じ）。
+const BasePath = "/api"
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// problemResponseRef は、エラーのレスポンス（application/problem+json）の参照です。
+const problemResponseRef = "#/components/responses/Problem"
+
+func init() {
+	// 検証エラーのメッセージにスキーマ全体を含めない（Problem Details の detail に入れるため）
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// Load は、API仕様を読み込み、レスポンスのスキーマを生成して検証済みの仕様を返します。
+// default のレスポンスを書いていない操作には、エラーのレスポンス（Problem）を加えます。
+func Load() (*openapi3.T, error) {
+	var raw any
+	if err := yaml.Unmarshal(specYAML, &raw); err != nil {
+		return nil, fmt.Errorf("API仕様の読み込みに失敗しました: %w", err)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("API仕様の読み込みに失敗しました: %w", err)
+	}
+	doc := &openapi3.T{}
+	if err := doc.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("API仕様の読み込みに失敗しました: %w", err)
+	}
+
+	if err := generateSchemas(doc.Components.Schemas); err != nil {
+		return nil, err
+	}
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			if op.Responses.Default() == nil {
+				op.Responses.Set("default", &openapi3.ResponseRef{Ref: problemResponseRef})
+			}
+		}
+	}
+
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return nil, fmt.Errorf("API仕様の参照を解決できません: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("API仕様が不正です: %w", err)
+	}
+	return doc, nil
+}
+
+// PathFromRoute は、Ginのルートのパス（/api/plans/:shopping_plan_id など）をAPI仕様のパス（/plans/{shopping_plan_id}）に変換します。
+// API仕様に含まないルート（BasePath の外）の場合は ok に false を返します。
+func PathFromRoute(route string) (path string, ok bool) {
+	path, ok = strings.CutPrefix(route, BasePath)
+	if !ok || !strings.HasPrefix(path, "/") {
+		return "", false
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, found := strings.CutPrefix(segment, ":"); found {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), true
+}
+
+// CheckRoutes は、Ginに登録したAPIのルートとAPI仕様の操作が過不足なく対応しているかを確かめます。
+// 対応しないものがあれば、そのすべてを1つのエラーにまとめて返します。
+func CheckRoutes(doc *openapi3.T, routes gin.RoutesInfo) error {
+	registered := make(map[string]bool)
+	var problems []string
+	for _, route := range routes {
+		path, ok := PathFromRoute(route.Path)
+		if !ok {
+			continue
+		}
+		key := route.Method + " " + path
+		registered[key] = true
+		if item := doc.Paths.Value(path); item == nil || item.GetOperation(route.Method) == nil {
+			problems = append(problems, "API仕様にないルートです: "+key)
+		}
+	}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if key := method + " " + path; !registered[key] {
+				problems = append(problems, "ルートが登録されていない操作です: "+key)
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("API仕様とルートが一致しません:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,623 @@
+// Package recipeio は、レシピカタログ（食材とメニュー）をYAML/JSON/CSVのドキュメントと相互変換します。
+package recipeio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+// Format は、ドキュメントのファイル形式を表す型です。
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+// ParseFormat は、文字列（"yaml", "yml", "json", "csv"）から Format を返します。
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("未対応の形式です: %q", s)
+}
+
+// FormatFromContentType は、Content-Typeヘッダーの値から Format を推測します。
+func FormatFromContentType(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("Content-Typeを解釈できません: %q", contentType)
+	}
+	switch mediaType {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML, nil
+	case "application/json":
+		return FormatJSON, nil
+	case "text/csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("未対応のContent-Typeです: %q", contentType)
+}
+
+// ContentType は、Format に対応するContent-Typeを返します。
+func (f Format) ContentType() string {
+	switch f {
+	case FormatYAML:
+		return "application/yaml; charset=utf-8"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// RowErrors は、ドキュメントの解析中に見つかった行ごとのエラーの一覧です。
+type RowErrors []*usecase.ImportRowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, rowErr := range e {
+		msgs[i] = fmt.Sprintf("%d行目: %s", rowErr.Row, rowErr.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Decode は、ドキュメントを読み込んでカタログに変換します。
+// 各レコードの Row には、YAMLとCSVでは行番号、JSONではドキュメント先頭からのレコードの通し番号が入ります。
+// 値の形式が不正な行がある場合は RowErrors を返します。
+func Decode(r io.Reader, format Format) (*usecase.Catalog, error) {
+	switch format {
+	case FormatYAML:
+		var doc document
+		if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return &usecase.Catalog{}, nil
+			}
+			return nil, fmt.Errorf("YAMLの解析に失敗しました: %w", err)
+		}
+		return doc.toCatalog(), nil
+	case FormatJSON:
+		var doc document
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("JSONの解析に失敗しました: %w", err)
+		}
+		doc.numberRecords()
+		return doc.toCatalog(), nil
+	case FormatCSV:
+		return decodeCSV(r)
+	}
+	return nil, fmt.Errorf("未対応の形式です: %q", format)
+}
+
+// Encode は、カタログを指定された形式で書き出します。
+func Encode(w io.Writer, format Format, catalog *usecase.Catalog) error {
+	switch format {
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(fromCatalog(catalog)); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(fromCatalog(catalog))
+	case FormatCSV:
+		return encodeCSV(w, catalog)
+	}
+	return fmt.Errorf("未対応の形式です: %q", format)
+}
+
+// --- YAML / JSON ---
+
+type document struct {
+	Ingredients   []*ingredientDoc   `yaml:"ingredients" json:"ingredients"`
+	Menus         []*menuDoc         `yaml:"menus" json:"menus"`
+	Substitutions []*substitutionDoc `yaml:"substitutions,omitempty" json:"substitutions,omitempty"`
+}
+
+type ingredientDoc struct {
+	Row                     int      `yaml:"-" json:"-"`
+	Name                    string   `yaml:"name" json:"name"`
+	Type                    string   `yaml:"type" json:"type"`
+	BaseAmount              float64  `yaml:"base_amount" json:"base_amount"`
+	Unit                    string   `yaml:"unit" json:"unit"`
+	ShelfLifeDaysUnopened   *int     `yaml:"shelf_life_days_unopened,omitempty" json:"shelf_life_days_unopened,omitempty"`
+	ShelfLifeDaysOpened     *int     `yaml:"shelf_life_days_opened,omitempty" json:"shelf_life_days_opened,omitempty"`
+	Aliases                 []string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
+	SeasonMonths            []int    `yaml:"season_months,omitempty,flow" json:"season_months,omitempty"`
+	SeasonalPriceMultiplier *float64 `yaml:"seasonal_price_multiplier,omitempty" json:"seasonal_price_multiplier,omitempty"`
+}
+
+type menuDoc struct {
+	Row         int        `yaml:"-" json:"-"`
+	Name        string     `yaml:"name" json:"name"`
+	Servings    int        `yaml:"servings,omitempty" json:"servings,omitempty"`
+	SourceURL   string     `yaml:"source_url,omitempty" json:"source_url,omitempty"`
+	Ingredients []*lineDoc `yaml:"ingredients" json:"ingredients"`
+	Steps       []string   `yaml:"steps,omitempty" json:"steps,omitempty"`
+	Tips        string     `yaml:"tips,omitempty" json:"tips,omitempty"`
+}
+
+type lineDoc struct {
+	Row        int     `yaml:"-" json:"-"`
+	Name       string  `yaml:"name" json:"name"`
+	Amount     float64 `yaml:"amount" json:"amount"`
+	Unit       string  `yaml:"unit,omitempty" json:"unit,omitempty"`
+	Type       string  `yaml:"type,omitempty" json:"type,omitempty"`
+	BaseAmount float64 `yaml:"base_amount,omitempty" json:"base_amount,omitempty"`
+}
+
+type substitutionDoc struct {
+	Row           int     `yaml:"-" json:"-"`
+	From          string  `yaml:"from" json:"from"`
+	To            string  `yaml:"to" json:"to"`
+	Ratio         float64 `yaml:"ratio" json:"ratio"`
+	Bidirectional bool    `yaml:"bidirectional,omitempty" json:"bidirectional,omitempty"`
+}
+
+// UnmarshalYAML は、エラー報告のためにレコードの行番号を記録します。
+func (d *ingredientDoc) UnmarshalYAML(node *yaml.Node) error {
+	type plain ingredientDoc
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	d.Row = node.Line
+	return nil
+}
+
+// UnmarshalYAML は、エラー報告のためにレコードの行番号を記録します。
+func (d *menuDoc) UnmarshalYAML(node *yaml.Node) error {
+	type plain menuDoc
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	d.Row = node.Line
+	return nil
+}
+
+// UnmarshalYAML は、エラー報告のためにレコードの行番号を記録します。
+func (d *lineDoc) UnmarshalYAML(node *yaml.Node) error {
+	type plain lineDoc
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	d.Row = node.Line
+	return nil
+}
+
+// UnmarshalYAML は、エラー報告のためにレコードの行番号を記録します。
+func (d *substitutionDoc) UnmarshalYAML(node *yaml.Node) error {
+	type plain substitutionDoc
+	if err := node.Decode((*plain)(d)); err != nil {
+		return err
+	}
+	d.Row = node.Line
+	return nil
+}
+
+// numberRecords は、行番号を持たないJSONのレコードに通し番号を振ります。
+func (d *document) numberRecords() {
+	row := 0
+	for _, ing := range d.Ingredients {
+		row++
+		ing.Row = row
+	}
+	for _, menu := range d.Menus {
+		row++
+		menu.Row = row
+		for _, line := range menu.Ingredients {
+			row++
+			line.Row = row
+		}
+	}
+	for _, sub := range d.Substitutions {
+		row++
+		sub.Row = row
+	}
+}
+
+func (d *document) toCatalog() *usecase.Catalog {
+	catalog := &usecase.Catalog{}
+	for _, ing := range d.Ingredients {
+		if ing == nil {
+			continue
+		}
+		catalog.Ingredients = append(catalog.Ingredients, &usecase.CatalogIngredientRecord{
+			Row:                     ing.Row,
+			Name:                    strings.TrimSpace(ing.Name),
+			TypeName:                strings.TrimSpace(ing.Type),
+			BaseAmount:              ing.BaseAmount,
+			Unit:                    strings.TrimSpace(ing.Unit),
+			ShelfLifeDaysUnopened:   ing.ShelfLifeDaysUnopened,
+			ShelfLifeDaysOpened:     ing.ShelfLifeDaysOpened,
+			Aliases:                 trimAll(ing.Aliases),
+			SeasonMonths:            ing.SeasonMonths,
+			SeasonalPriceMultiplier: ing.SeasonalPriceMultiplier,
+		})
+	}
+	for _, menu := range d.Menus {
+		if menu == nil {
+			continue
+		}
+		recipe := &usecase.RecipeRecord{
+			Row:       menu.Row,
+			MenuName:  strings.TrimSpace(menu.Name),
+			Servings:  menu.Servings,
+			Tips:      strings.TrimSpace(menu.Tips),
+			SourceURL: strings.TrimSpace(menu.SourceURL),
+		}
+		for _, step := range menu.Steps {
+			if step = strings.TrimSpace(step); step != "" {
+				recipe.Steps = append(recipe.Steps, step)
+			}
+		}
+		for _, line := range menu.Ingredients {
+			if line == nil {
+				continue
+			}
+			recipe.Lines = append(recipe.Lines, &usecase.RecipeLineRecord{
+				Row:            line.Row,
+				IngredientName: strings.TrimSpace(line.Name),
+				Amount:         line.Amount,
+				Unit:           strings.TrimSpace(line.Unit),
+				TypeName:       strings.TrimSpace(line.Type),
+				BaseAmount:     line.BaseAmount,
+			})
+		}
+		catalog.Recipes = append(catalog.Recipes, recipe)
+	}
+	for _, sub := range d.Substitutions {
+		if sub == nil {
+			continue
+		}
+		catalog.Substitutions = append(catalog.Substitutions, &usecase.SubstitutionRecord{
+			Row:           sub.Row,
+			From:          strings.TrimSpace(sub.From),
+			To:            strings.TrimSpace(sub.To),
+			Ratio:         sub.Ratio,
+			Bidirectional: sub.Bidirectional,
+		})
+	}
+	return catalog
+}
+
+func fromCatalog(catalog *usecase.Catalog) *document {
+	doc := &document{
+		Ingredients: make([]*ingredientDoc, len(catalog.Ingredients)),
+		Menus:       make([]*menuDoc, len(catalog.Recipes)),
+	}
+	for i, ing := range catalog.Ingredients {
+		doc.Ingredients[i] = &ingredientDoc{
+			Name:                    ing.Name,
+			Type:                    ing.TypeName,
+			BaseAmount:              ing.BaseAmount,
+			Unit:                    ing.Unit,
+			ShelfLifeDaysUnopened:   ing.ShelfLifeDaysUnopened,
+			ShelfLifeDaysOpened:     ing.ShelfLifeDaysOpened,
+			Aliases:                 ing.Aliases,
+			SeasonMonths:            ing.SeasonMonths,
+			SeasonalPriceMultiplier: ing.SeasonalPriceMultiplier,
+		}
+	}
+	for i, recipe := range catalog.Recipes {
+		lines := make([]*lineDoc, len(recipe.Lines))
+		for j, line := range recipe.Lines {
+			lines[j] = &lineDoc{
+				Name:       line.IngredientName,
+				Amount:     line.Amount,
+				Unit:       line.Unit,
+				Type:       line.TypeName,
+				BaseAmount: line.BaseAmount,
+			}
+		}
+		doc.Menus[i] = &menuDoc{
+			Name:        recipe.MenuName,
+			Servings:    recipe.Servings,
+			SourceURL:   recipe.SourceURL,
+			Ingredients: lines,
+			Steps:       recipe.Steps,
+			Tips:        recipe.Tips,
+		}
+	}
+	for _, sub := range catalog.Substitutions {
+		doc.Substitutions = append(doc.Substitutions, &substitutionDoc{
+			From:          sub.From,
+			To:            sub.To,
+			Ratio:         sub.Ratio,
+			Bidirectional: sub.Bidirectional,
+		})
+	}
+	return doc
+}
+
+// --- CSV ---
+
+// CSVは1行が1レコードで、menu列が空の行は食材定義、menu列がある行はレシピの材料行として扱います。
+// menu列があり ingredient列が空の行はメニューの情報行で、servings/tips/source_url を設定し、step列を調理手順として順に追加します。
+// 食材定義の aliases 列には別名を、season_months 列には旬の月（1〜12）を、それぞれ "|" で区切って並べます。
+// 列はヘッダー行の名前で識別するため、表計算ソフトで列を並べ替えても読み込めます。
+// 食材の代用ルールはCSVでは扱いません（書き出し時も含まれません）。YAMLまたはJSONを使用してください。
+var csvHeader = []string{"menu", "ingredient", "amount", "unit", "type", "base_amount", "shelf_life_days_unopened", "shelf_life_days_opened", "servings", "tips", "source_url", "step", "aliases", "season_months", "seasonal_price_multiplier"}
+
+// aliasSeparator は、CSVの aliases 列と season_months 列で値を区切る文字です。
+const aliasSeparator = "|"
+
+func decodeCSV(r io.Reader) (*usecase.Catalog, error) {
+	// Excelが付与するBOMを取り除く
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		_, _ = br.Discard(3)
+	}
+
+	reader := csv.NewReader(br)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &usecase.Catalog{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("CSVのヘッダー行の読み込みに失敗しました: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"menu", "ingredient"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSVのヘッダーに %q 列がありません", required)
+		}
+	}
+
+	catalog := &usecase.Catalog{}
+	recipes := make(map[string]*usecase.RecipeRecord)
+	var rowErrs RowErrors
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSVの読み込みに失敗しました: %w", err)
+		}
+		row, _ := reader.FieldPos(0)
+
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		var fieldErr string
+		getFloat := func(name string) float64 {
+			v := get(name)
+			if v == "" {
+				return 0
+			}
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil && fieldErr == "" {
+				fieldErr = fmt.Sprintf("%s列の値 %q は数値ではありません", name, v)
+			}
+			return f
+		}
+		getInt := func(name string) *int {
+			v := get(name)
+			if v == "" {
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				if fieldErr == "" {
+					fieldErr = fmt.Sprintf("%s列の値 %q は整数ではありません", name, v)
+				}
+				return nil
+			}
+			return &n
+		}
+		getMonths := func(name string) []int {
+			var months []int
+			for _, v := range trimAll(strings.Split(get(name), aliasSeparator)) {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					if fieldErr == "" {
+						fieldErr = fmt.Sprintf("%s列の値 %q は整数ではありません", name, v)
+					}
+					return nil
+				}
+				months = append(months, n)
+			}
+			return months
+		}
+
+		menuName, ingredientName := get("menu"), get("ingredient")
+		if menuName == "" && ingredientName == "" {
+			// 空行は読み飛ばす
+			continue
+		}
+
+		recipeFor := func(menuName string) *usecase.RecipeRecord {
+			recipe, ok := recipes[menuName]
+			if !ok {
+				recipe = &usecase.RecipeRecord{Row: row, MenuName: menuName}
+				recipes[menuName] = recipe
+				catalog.Recipes = append(catalog.Recipes, recipe)
+			}
+			return recipe
+		}
+
+		if ingredientName == "" {
+			servings := getInt("servings")
+			if fieldErr != "" {
+				rowErrs = append(rowErrs, &usecase.ImportRowError{Row: row, Menu: menuName, Message: fieldErr})
+				continue
+			}
+			recipe := recipeFor(menuName)
+			if servings != nil {
+				recipe.Servings = *servings
+			}
+			if tips := get("tips"); tips != "" {
+				recipe.Tips = tips
+			}
+			if sourceURL := get("source_url"); sourceURL != "" {
+				recipe.SourceURL = sourceURL
+			}
+			if step := get("step"); step != "" {
+				recipe.Steps = append(recipe.Steps, step)
+			}
+			continue
+		}
+
+		if menuName == "" {
+			def := &usecase.CatalogIngredientRecord{
+				Row:                   row,
+				Name:                  ingredientName,
+				TypeName:              get("type"),
+				BaseAmount:            getFloat("base_amount"),
+				Unit:                  get("unit"),
+				ShelfLifeDaysUnopened: getInt("shelf_life_days_unopened"),
+				ShelfLifeDaysOpened:   getInt("shelf_life_days_opened"),
+				Aliases:               trimAll(strings.Split(get("aliases"), aliasSeparator)),
+				SeasonMonths:          getMonths("season_months"),
+			}
+			if get("seasonal_price_multiplier") != "" {
+				m := getFloat("seasonal_price_multiplier")
+				def.SeasonalPriceMultiplier = &m
+			}
+			if fieldErr != "" {
+				rowErrs = append(rowErrs, &usecase.ImportRowError{Row: row, Ingredient: ingredientName, Message: fieldErr})
+				continue
+			}
+			catalog.Ingredients = append(catalog.Ingredients, def)
+			continue
+		}
+
+		line := &usecase.RecipeLineRecord{
+			Row:            row,
+			IngredientName: ingredientName,
+			Amount:         getFloat("amount"),
+			Unit:           get("unit"),
+			TypeName:       get("type"),
+			BaseAmount:     getFloat("base_amount"),
+		}
+		if fieldErr != "" {
+			rowErrs = append(rowErrs, &usecase.ImportRowError{Row: row, Menu: menuName, Ingredient: ingredientName, Message: fieldErr})
+			continue
+		}
+		recipe := recipeFor(menuName)
+		recipe.Lines = append(recipe.Lines, line)
+	}
+
+	if len(rowErrs) > 0 {
+		return nil, rowErrs
+	}
+	return catalog, nil
+}
+
+func encodeCSV(w io.Writer, catalog *usecase.Catalog) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	newRecord := func() []string {
+		return make([]string, len(csvHeader))
+	}
+	for _, ing := range catalog.Ingredients {
+		record := newRecord()
+		record[1], record[3], record[4] = ing.Name, ing.Unit, ing.TypeName
+		record[5], record[6], record[7] = formatFloat(ing.BaseAmount), formatInt(ing.ShelfLifeDaysUnopened), formatInt(ing.ShelfLifeDaysOpened)
+		record[12] = strings.Join(ing.Aliases, aliasSeparator)
+		months := make([]string, len(ing.SeasonMonths))
+		for i, m := range ing.SeasonMonths {
+			months[i] = strconv.Itoa(m)
+		}
+		record[13] = strings.Join(months, aliasSeparator)
+		if ing.SeasonalPriceMultiplier != nil {
+			record[14] = formatFloat(*ing.SeasonalPriceMultiplier)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	for _, recipe := range catalog.Recipes {
+		// メニューの情報行。手順は1行に1つずつ書き出す
+		infoRows := len(recipe.Steps)
+		if infoRows == 0 && (recipe.Servings > 0 || recipe.Tips != "" || recipe.SourceURL != "") {
+			infoRows = 1
+		}
+		for i := 0; i < infoRows; i++ {
+			record := newRecord()
+			record[0] = recipe.MenuName
+			if i == 0 {
+				if recipe.Servings > 0 {
+					record[8] = strconv.Itoa(recipe.Servings)
+				}
+				record[9], record[10] = recipe.Tips, recipe.SourceURL
+			}
+			if i < len(recipe.Steps) {
+				record[11] = recipe.Steps[i]
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		for _, line := range recipe.Lines {
+			record := newRecord()
+			record[0], record[1], record[2], record[3], record[4] = recipe.MenuName, line.IngredientName, formatFloat(line.Amount), line.Unit, line.TypeName
+			if line.BaseAmount > 0 {
+				record[5] = formatFloat(line.BaseAmount)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatInt(v *int) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(*v)
+}
+
+// trimAll は、前後の空白を取り除き、空になった要素を除いたスライスを返します。
+func trimAll(values []string) []string {
+	var trimmed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			trimmed = append(trimmed, v)
+		}
+	}
+	return trimmed
+}
@@ -0,0 +1,322 @@
+package recipeio
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"meal-compass/backend/internal/usecase"
+)
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *usecase.Catalog
+		wantErr bool
+	}{
+		{
+			name:  "空のドキュメント",
+			input: "",
+			want:  &usecase.Catalog{},
+		},
+		{
+			name: "食材とメニュー、代用ルール",
+			input: `ingredients:
+  - name: " 玉ねぎ "
+    type: 野菜
+    base_amount: 1
+    unit: 個
+    shelf_life_days_unopened: 30
+    aliases: [タマネギ, " ", たまねぎ]
+    season_months: [4, 5]
+    seasonal_price_multiplier: 0.8
+menus:
+  - name: 肉じゃが
+    servings: 2
+    steps: ["切る", "  ", "煮る"]
+    tips: " 弱火で "
+    ingredients:
+      - name: 玉ねぎ
+        amount: 0.5
+      - name: 豚肉
+        amount: 100
+        unit: g
+        type: 肉類
+        base_amount: 100
+substitutions:
+  - from: 豚肉
+    to: 鶏肉
+    ratio: 1
+    bidirectional: true
+`,
+			want: &usecase.Catalog{
+				Ingredients: []*usecase.CatalogIngredientRecord{{
+					Row:                     2,
+					Name:                    "玉ねぎ",
+					TypeName:                "野菜",
+					BaseAmount:              1,
+					Unit:                    "個",
+					ShelfLifeDaysUnopened:   intPtr(30),
+					Aliases:                 []string{"タマネギ", "たまねぎ"},
+					SeasonMonths:            []int{4, 5},
+					SeasonalPriceMultiplier: floatPtr(0.8),
+				}},
+				Recipes: []*usecase.RecipeRecord{{
+					Row:      11,
+					MenuName: "肉じゃが",
+					Servings: 2,
+					Tips:     "弱火で",
+					Steps:    []string{"切る", "煮る"},
+					Lines: []*usecase.RecipeLineRecord{
+						{Row: 16, IngredientName: "玉ねぎ", Amount: 0.5},
+						{Row: 18, IngredientName: "豚肉", Amount: 100, Unit: "g", TypeName: "肉類", BaseAmount: 100},
+					},
+				}},
+				Substitutions: []*usecase.SubstitutionRecord{
+					{Row: 24, From: "豚肉", To: "鶏肉", Ratio: 1, Bidirectional: true},
+				},
+			},
+		},
+		{
+			name:    "YAMLとして解析できない",
+			input:   "ingredients: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(strings.NewReader(tt.input), FormatYAML)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %s, want %s", dumpCatalog(got), dumpCatalog(tt.want))
+			}
+		})
+	}
+}
+
+func TestDecodeJSONNumbersRecords(t *testing.T) {
+	input := `{
+		"ingredients": [{"name": "卵", "type": "卵類", "base_amount": 1, "unit": "個"}],
+		"menus": [{"name": "卵焼き", "ingredients": [{"name": "卵", "amount": 2}, {"name": "砂糖", "amount": 5}]}],
+		"substitutions": [{"from": "砂糖", "to": "みりん", "ratio": 3}]
+	}`
+	got, err := Decode(strings.NewReader(input), FormatJSON)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	// JSONには行番号が無いため、ドキュメントの先頭からの通し番号になる
+	rows := []int{got.Ingredients[0].Row, got.Recipes[0].Row, got.Recipes[0].Lines[0].Row, got.Recipes[0].Lines[1].Row, got.Substitutions[0].Row}
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %v, want %v", rows, want)
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    *usecase.Catalog
+		wantErr string    // RowErrors 以外のエラーに含まれる文字列
+		rowErrs RowErrors // 期待する行ごとのエラー
+	}{
+		{
+			name:  "空のドキュメント",
+			input: "",
+			want:  &usecase.Catalog{},
+		},
+		{
+			name: "BOM付き、列の並べ替え、食材定義と情報行と材料行",
+			input: "\xEF\xBB\xBFingredient,menu,amount,unit,type,base_amount,aliases,season_months,seasonal_price_multiplier,servings,step\n" +
+				"玉ねぎ,,,個,野菜,1,タマネギ| たまねぎ |,4|5,0.8,,\n" +
+				",,,,,,,,,,\n" +
+				",肉じゃが,,,,,,,,2,切る\n" +
+				",肉じゃが,,,,,,,,,煮る\n" +
+				"玉ねぎ,肉じゃが,0.5,,,,,,,,\n" +
+				"豚肉,肉じゃが,100,g,肉類,100,,,,,\n",
+			want: &usecase.Catalog{
+				Ingredients: []*usecase.CatalogIngredientRecord{{
+					Row:                     2,
+					Name:                    "玉ねぎ",
+					TypeName:                "野菜",
+					BaseAmount:              1,
+					Unit:                    "個",
+					Aliases:                 []string{"タマネギ", "たまねぎ"},
+					SeasonMonths:            []int{4, 5},
+					SeasonalPriceMultiplier: floatPtr(0.8),
+				}},
+				Recipes: []*usecase.RecipeRecord{{
+					Row:      4,
+					MenuName: "肉じゃが",
+					Servings: 2,
+					Steps:    []string{"切る", "煮る"},
+					Lines: []*usecase.RecipeLineRecord{
+						{Row: 6, IngredientName: "玉ねぎ", Amount: 0.5},
+						{Row: 7, IngredientName: "豚肉", Amount: 100, Unit: "g", TypeName: "肉類", BaseAmount: 100},
+					},
+				}},
+			},
+		},
+		{
+			name:    "必須の列が無い",
+			input:   "name,amount\n玉ねぎ,1\n",
+			wantErr: `"menu" 列がありません`,
+		},
+		{
+			name: "値の形式が不正な行はすべて報告する",
+			input: "menu,ingredient,amount,shelf_life_days_opened,servings,season_months\n" +
+				",卵,,3日,,\n" +
+				"卵焼き,卵,二,,,\n" +
+				"卵焼き,,,,二人前,\n" +
+				",砂糖,,,,4|春\n",
+			rowErrs: RowErrors{
+				{Row: 2, Ingredient: "卵", Message: `shelf_life_days_opened列の値 "3日" は整数ではありません`},
+				{Row: 3, Menu: "卵焼き", Ingredient: "卵", Message: `amount列の値 "二" は数値ではありません`},
+				{Row: 4, Menu: "卵焼き", Message: `servings列の値 "二人前" は整数ではありません`},
+				{Row: 5, Ingredient: "砂糖", Message: `season_months列の値 "春" は整数ではありません`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(strings.NewReader(tt.input), FormatCSV)
+			switch {
+			case tt.rowErrs != nil:
+				var rowErrs RowErrors
+				if !errors.As(err, &rowErrs) {
+					t.Fatalf("Decode() error = %v, want RowErrors", err)
+				}
+				if !reflect.DeepEqual(rowErrs, tt.rowErrs) {
+					t.Errorf("Decode() error = %v, want %v", rowErrs, tt.rowErrs)
+				}
+				return
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Decode() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %s, want %s", dumpCatalog(got), dumpCatalog(tt.want))
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	catalog := &usecase.Catalog{
+		Ingredients: []*usecase.CatalogIngredientRecord{{
+			Name:                  "玉ねぎ",
+			TypeName:              "野菜",
+			BaseAmount:            1,
+			Unit:                  "個",
+			ShelfLifeDaysUnopened: intPtr(30),
+			Aliases:               []string{"タマネギ"},
+			SeasonMonths:          []int{4, 5},
+		}},
+		Recipes: []*usecase.RecipeRecord{{
+			MenuName:  "肉じゃが",
+			Servings:  2,
+			Tips:      "弱火で",
+			SourceURL: "https://example.com/nikujaga",
+			Steps:     []string{"切る", "煮る"},
+			Lines:     []*usecase.RecipeLineRecord{{IngredientName: "玉ねぎ", Amount: 0.5, Unit: "個"}},
+		}},
+	}
+
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf strings.Builder
+			if err := Encode(&buf, format, catalog); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got, err := Decode(strings.NewReader(buf.String()), format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v\n%s", err, buf.String())
+			}
+			clearRows(got)
+			if !reflect.DeepEqual(got, catalog) {
+				t.Errorf("round trip = %s, want %s", dumpCatalog(got), dumpCatalog(catalog))
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "yaml", want: FormatYAML},
+		{input: " YML ", want: FormatYAML},
+		{input: "JSON", want: FormatJSON},
+		{input: "csv", want: FormatCSV},
+		{input: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Format
+		wantErr bool
+	}{
+		{input: "application/yaml", want: FormatYAML},
+		{input: "text/x-yaml; charset=utf-8", want: FormatYAML},
+		{input: "application/json", want: FormatJSON},
+		{input: "text/csv; charset=utf-8", want: FormatCSV},
+		{input: "text/plain", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := FormatFromContentType(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FormatFromContentType(%q) = %q, %v, want %q (error %v)", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// clearRows は、比較のためにカタログのレコードの位置（Row）を消します。
+func clearRows(catalog *usecase.Catalog) {
+	for _, ing := range catalog.Ingredients {
+		ing.Row = 0
+	}
+	for _, recipe := range catalog.Recipes {
+		recipe.Row = 0
+		for _, line := range recipe.Lines {
+			line.Row = 0
+		}
+	}
+	for _, sub := range catalog.Substitutions {
+		sub.Row = 0
+	}
+}
+
+// dumpCatalog は、失敗したときの表示のためにカタログをYAMLで書き出します。
+func dumpCatalog(catalog *usecase.Catalog) string {
+	if catalog == nil {
+		return "<nil>"
+	}
+	var buf strings.Builder
+	_ = Encode(&buf, FormatYAML, catalog)
+	return "\n" + buf.String()
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// translateError は、GORM のエラーのうち、呼び出し側で区別するものをリポジトリのエラーに置き換えます。
+// 行が見つからない場合は repository.ErrNotFound を返し、それ以外はそのまま返します。
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository.ErrNotFound
+	}
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"meal-compass/backend/internal/domain/model"
 	"meal-compass/backend/internal/domain/repository"
@@ -25,4 +26,79 @@ func (r *ingredientRepository) CreateIngredientTypes(ctx context.Context, ingred
 
 func (r *ingredientRepository) CreateIngredients(ctx context.Context, ingredients []*model.Ingredient) error {
 	return r.db.WithContext(ctx).Create(ingredients).Error
-}
\ No newline at end of file
+}
+
+func (r *ingredientRepository) UpdateIngredientSeason(ctx context.Context, ingredient *model.Ingredient) error {
+	return r.db.WithContext(ctx).
+		Model(ingredient).
+		Select("season_months", "seasonal_price_multiplier").
+		Updates(ingredient).Error
+}
+
+func (r *ingredientRepository) CreateIngredientAliases(ctx context.Context, aliases []*model.IngredientAlias) error {
+	return r.db.WithContext(ctx).Create(aliases).Error
+}
+
+func (r *ingredientRepository) SaveSubstitutions(ctx context.Context, substitutions []*model.IngredientSubstitution) error {
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_ingredient_id"}, {Name: "to_ingredient_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"ratio"}),
+		}).
+		Create(substitutions).Error
+}
+
+func (r *ingredientRepository) FindSubstitutions(ctx context.Context) ([]*model.IngredientSubstitution, error) {
+	var substitutions []*model.IngredientSubstitution
+	err := r.db.WithContext(ctx).
+		Preload("FromIngredient").
+		Preload("ToIngredient.IngredientType").
+		Find(&substitutions).Error
+	return substitutions, err
+}
+
+func (r *ingredientRepository) FindIngredientsByIDs(ctx context.Context, ingredientIDs []string) ([]*model.Ingredient, error) {
+	var ingredients []*model.Ingredient
+	if len(ingredientIDs) == 0 {
+		return ingredients, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("id IN ?", ingredientIDs).
+		Find(&ingredients).Error
+	return ingredients, err
+}
+
+func (r *ingredientRepository) FindIngredientTypes(ctx context.Context) ([]*model.IngredientType, error) {
+	var types []*model.IngredientType
+	err := r.db.WithContext(ctx).
+		Order("name ASC").
+		Find(&types).Error
+	return types, err
+}
+
+func (r *ingredientRepository) FindIngredients(ctx context.Context) ([]*model.Ingredient, error) {
+	var ingredients []*model.Ingredient
+	err := r.db.WithContext(ctx).
+		Preload("IngredientType").
+		Preload("Aliases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("alias ASC")
+		}).
+		Order("name ASC").
+		Find(&ingredients).Error
+	return ingredients, err
+}
+
+func (r *ingredientRepository) FindIngredientByID(ctx context.Context, ingredientID string) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.WithContext(ctx).
+		Preload("IngredientType").
+		Preload("Aliases", func(db *gorm.DB) *gorm.DB {
+			return db.Order("alias ASC")
+		}).
+		First(&ingredient, "id = ?", ingredientID).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &ingredient, nil
+}
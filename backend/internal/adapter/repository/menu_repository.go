@@ -19,6 +19,18 @@ func NewMenuRepository(db *gorm.DB) repository.MenuRepository {
 	return &menuRepository{db: db}
 }
 
+// Transaction は、引数で受け取った関数をトランザクション内で実行します。
+func (r *menuRepository) Transaction(ctx context.Context, fn func(txRepo repository.MenuRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewMenuRepository(tx))
+	})
+}
+
+// IngredientRepository は、このリポジトリと同じ接続（トランザクション内ではそのトランザクション）を使う食材のリポジトリを返します。
+func (r *menuRepository) IngredientRepository() repository.IngredientRepository {
+	return NewIngredientRepository(r.db)
+}
+
 // FindRandomMenus は、指定された件数分のメニューをランダムに取得します。
 func (r *menuRepository) FindRandomMenus(ctx context.Context, count int) ([]*model.Menu, error) {
 	var menus []*model.Menu
@@ -26,14 +38,149 @@ func (r *menuRepository) FindRandomMenus(ctx context.Context, count int) ([]*mod
 	// 注意: ORDER BY RAND() はテーブルサイズが大きくなるとパフォーマンスが低下する可能性があります。
 	// MVPではシンプルさを優先しますが、将来的にデータ件数が増える場合は、
 	// 全IDを取得してからランダムにIDを選び、IN句で取得するなどの代替案を検討する必要があります。
-	err := r.db.WithContext(ctx).
+	db := r.db.WithContext(ctx)
+	if count > 0 {
+		db = db.Limit(count)
+	}
+	err := db.
 		Order("RAND()").
-		Limit(count).
 		Preload("MenuIngredientItems.Ingredient.IngredientType"). // レシピと食材情報も合わせて取得
+		Preload("Steps", orderPosition).
+		Preload("CurrentVersion").
 		Find(&menus).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("ランダムなメニューの取得に失敗しました: %w", err)
 	}
 	return menus, nil
-}
\ No newline at end of file
+}
+
+func (r *menuRepository) FindAllMenus(ctx context.Context) ([]*model.Menu, error) {
+	var menus []*model.Menu
+	err := r.db.WithContext(ctx).
+		Preload("MenuIngredientItems", func(db *gorm.DB) *gorm.DB {
+			// 取り込み時の行の並びを保つため、作成順で取得
+			return db.Order("created_at ASC")
+		}).
+		Preload("MenuIngredientItems.Ingredient.IngredientType").
+		Preload("Steps", orderPosition).
+		Preload("CurrentVersion").
+		Order("name ASC").
+		Find(&menus).Error
+	return menus, err
+}
+
+func (r *menuRepository) FindMenuByID(ctx context.Context, menuID string) (*model.Menu, error) {
+	var menu model.Menu
+	err := r.db.WithContext(ctx).
+		Preload("MenuIngredientItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("MenuIngredientItems.Ingredient.IngredientType").
+		Preload("Steps", orderPosition).
+		Preload("CurrentVersion").
+		First(&menu, "id = ?", menuID).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &menu, nil
+}
+
+func (r *menuRepository) FindMenusByIDs(ctx context.Context, menuIDs []string) ([]*model.Menu, error) {
+	var menus []*model.Menu
+	if len(menuIDs) == 0 {
+		return menus, nil
+	}
+	err := r.db.WithContext(ctx).
+		Preload("MenuIngredientItems", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("MenuIngredientItems.Ingredient.IngredientType").
+		Where("id IN ?", menuIDs).
+		Order("name ASC").
+		Find(&menus).Error
+	return menus, err
+}
+
+func (r *menuRepository) FindMenuIDsByIngredientIDs(ctx context.Context, ingredientIDs []string) ([]string, error) {
+	var menuIDs []string
+	if len(ingredientIDs) == 0 {
+		return menuIDs, nil
+	}
+	err := r.db.WithContext(ctx).
+		Model(&model.MenuIngredientItem{}).
+		Distinct("menu_id").
+		Where("ingredient_id IN ?", ingredientIDs).
+		Pluck("menu_id", &menuIDs).Error
+	return menuIDs, err
+}
+
+func (r *menuRepository) CreateMenu(ctx context.Context, menu *model.Menu) error {
+	// レシピと調理手順、版は ReplaceMenuIngredientItems / ReplaceMenuSteps / CreateMenuVersion で別途保存するため、関連の自動保存は行わない
+	return r.db.WithContext(ctx).Omit("MenuIngredientItems", "Steps", "CurrentVersion").Create(menu).Error
+}
+
+func (r *menuRepository) UpdateMenuDetails(ctx context.Context, menu *model.Menu) error {
+	return r.db.WithContext(ctx).
+		Model(menu).
+		Select("servings", "tips", "source_url").
+		Updates(menu).Error
+}
+
+func (r *menuRepository) ReplaceMenuIngredientItems(ctx context.Context, menuID string, items []*model.MenuIngredientItem) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("menu_id = ?", menuID).Delete(&model.MenuIngredientItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	for _, item := range items {
+		item.MenuID = menuID
+	}
+	return db.Omit("Menu", "Ingredient").Create(items).Error
+}
+
+func (r *menuRepository) ReplaceMenuSteps(ctx context.Context, menuID string, steps []*model.MenuStep) error {
+	db := r.db.WithContext(ctx)
+	if err := db.Where("menu_id = ?", menuID).Delete(&model.MenuStep{}).Error; err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return nil
+	}
+	for i, step := range steps {
+		step.MenuID = menuID
+		step.Position = i + 1
+	}
+	return db.Create(steps).Error
+}
+
+func (r *menuRepository) CreateMenuVersion(ctx context.Context, version *model.MenuVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 材料と調理手順（has many）も合わせて保存される
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Menu{}).
+			Where("id = ?", version.MenuID).
+			Update("current_version_id", version.ID).Error
+	})
+}
+
+func (r *menuRepository) FindMenuVersions(ctx context.Context, menuID string) ([]*model.MenuVersion, error) {
+	var versions []*model.MenuVersion
+	err := r.db.WithContext(ctx).
+		Preload("Ingredients", orderPosition).
+		Preload("Steps", orderPosition).
+		Where("menu_id = ?", menuID).
+		Order("version DESC").
+		Find(&versions).Error
+	return versions, err
+}
+
+
+// orderPosition は、調理手順や版の材料を並び順（手順の番号）どおりにPreloadするための条件です。
+func orderPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
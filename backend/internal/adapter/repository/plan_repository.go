@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"gorm.io/gorm"
 
 	"meal-compass/backend/internal/domain/model"
@@ -27,22 +29,127 @@ func (r *planRepository) Transaction(ctx context.Context, fn func(txRepo reposit
 	})
 }
 
+// MenuRepository は、このリポジトリと同じ接続（トランザクション内ではそのトランザクション）を使うメニューのリポジトリを返します。
+func (r *planRepository) MenuRepository() repository.MenuRepository {
+	return NewMenuRepository(r.db)
+}
+
 func (r *planRepository) CreateShoppingPlan(ctx context.Context, plan *model.ShoppingPlan) error {
+	if plan.Version == 0 {
+		plan.Version = 1
+	}
 	return r.db.WithContext(ctx).Create(plan).Error
 }
 
 func (r *planRepository) CreatePlanningMealItems(ctx context.Context, meals []*model.PlanningMealItem) error {
-	return r.db.WithContext(ctx).Create(meals).Error
+	// メニューとその版は作成済みのものを参照するだけなので、関連の自動保存は行わない。代用の記録（Substitutions）は合わせて保存される
+	return r.db.WithContext(ctx).Omit("Menu", "MenuVersion").Create(meals).Error
 }
 
 func (r *planRepository) CreateShoppingIngredientItems(ctx context.Context, ingredients []*model.ShoppingIngredientItem) error {
+	for _, item := range ingredients {
+		if item.Version == 0 {
+			item.Version = 1
+		}
+	}
 	return r.db.WithContext(ctx).Create(ingredients).Error
 }
 
+func (r *planRepository) FindShoppingPlanByID(ctx context.Context, planID string) (*model.ShoppingPlan, error) {
+	var plan model.ShoppingPlan
+	if err := r.db.WithContext(ctx).First(&plan, "id = ?", planID).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &plan, nil
+}
+
+func (r *planRepository) FindShoppingPlans(ctx context.Context, query repository.ShoppingPlanQuery) ([]*model.ShoppingPlan, error) {
+	db := r.db.WithContext(ctx)
+	if query.StartFrom != nil {
+		db = db.Where("period_start_at >= ?", query.StartFrom.Format("2006-01-02"))
+	}
+	if query.StartTo != nil {
+		db = db.Where("period_start_at <= ?", query.StartTo.Format("2006-01-02"))
+	}
+	if query.Archived != nil {
+		if *query.Archived {
+			db = db.Where("archived_at IS NOT NULL")
+		} else {
+			db = db.Where("archived_at IS NULL")
+		}
+	}
+	if after := query.After; after != nil {
+		// 並び順（開始日、作成日時、IDの降順）で、カーソルの計画より後の計画だけを取得する
+		start := after.PeriodStartAt.Format("2006-01-02")
+		db = db.Where(
+			"period_start_at < ? OR (period_start_at = ? AND (created_at < ? OR (created_at = ? AND id < ?)))",
+			start, start, after.CreatedAt, after.CreatedAt, after.ID,
+		)
+	}
+	var plans []*model.ShoppingPlan
+	err := db.
+		Order("period_start_at DESC, created_at DESC, id DESC").
+		Limit(query.Limit).
+		Find(&plans).Error
+	return plans, err
+}
+
+func (r *planRepository) SummarizeShoppingPlans(ctx context.Context, planIDs []string) (map[string]*repository.ShoppingPlanSummary, error) {
+	summaries := make(map[string]*repository.ShoppingPlanSummary, len(planIDs))
+	for _, planID := range planIDs {
+		summaries[planID] = &repository.ShoppingPlanSummary{PlanID: planID}
+	}
+	if len(planIDs) == 0 {
+		return summaries, nil
+	}
+
+	var meals []struct {
+		PlanID        string
+		MealCount     int
+		FirstMealDate *time.Time
+		LastMealDate  *time.Time
+	}
+	err := r.db.WithContext(ctx).Model(&model.PlanningMealItem{}).
+		Select("plan_id, COUNT(*) AS meal_count, MIN(date) AS first_meal_date, MAX(date) AS last_meal_date").
+		Where("plan_id IN ?", planIDs).
+		Group("plan_id").
+		Scan(&meals).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range meals {
+		s := summaries[m.PlanID]
+		s.MealCount, s.FirstMealDate, s.LastMealDate = m.MealCount, m.FirstMealDate, m.LastMealDate
+	}
+
+	var items []struct {
+		PlanID      string
+		ItemCount   int
+		BoughtCount int
+	}
+	err = r.db.WithContext(ctx).Model(&model.ShoppingIngredientItem{}).
+		Select("plan_id, COUNT(*) AS item_count, SUM(CASE WHEN bought THEN 1 ELSE 0 END) AS bought_count").
+		Where("plan_id IN ?", planIDs).
+		Group("plan_id").
+		Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range items {
+		s := summaries[i.PlanID]
+		s.ItemCount, s.BoughtCount = i.ItemCount, i.BoughtCount
+	}
+	return summaries, nil
+}
+
 func (r *planRepository) FindMealsByPlanID(ctx context.Context, planID string) ([]*model.PlanningMealItem, error) {
 	var meals []*model.PlanningMealItem
 	err := r.db.WithContext(ctx).
 		Preload("Menu.MenuIngredientItems.Ingredient"). // IngredientTypeのPreloadを削除
+		Preload("Menu.Steps", orderPosition).
+		Preload("MenuVersion.Ingredients", orderPosition).
+		Preload("MenuVersion.Steps", orderPosition).
+		Preload("Substitutions").
 		Where("plan_id = ?", planID).
 		Order("date ASC, meal_period ASC").
 		Find(&meals).Error
@@ -64,12 +171,182 @@ func (r *planRepository) FindShoppingIngredientItemByID(ctx context.Context, ite
 		Preload("Ingredient.IngredientType"). // レスポンス生成に必要な情報をPreload
 		First(&item, "id = ?", itemID).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 	return &item, nil
 }
 
 func (r *planRepository) UpdateShoppingIngredientItem(ctx context.Context, item *model.ShoppingIngredientItem) error {
-	// Saveは全フィールドを更新します。特定のフィールドのみ更新したい場合はUpdateを使用します。
-	return r.db.WithContext(ctx).Save(item).Error
-}
\ No newline at end of file
+	return r.updateShoppingIngredientItem(r.db.WithContext(ctx), item, map[string]any{
+		"amount":           item.Amount,
+		"bought":           item.Bought,
+		"purchased_amount": item.PurchasedAmount,
+		"purchased_at":     item.PurchasedAt,
+		"note":             item.Note,
+	})
+}
+
+func (r *planRepository) UpdateShoppingIngredientItemStates(ctx context.Context, items []*model.ShoppingIngredientItem) error {
+	db := r.db.WithContext(ctx)
+	for _, item := range items {
+		err := r.updateShoppingIngredientItem(db, item, map[string]any{
+			"amount":           item.Amount,
+			"bought":           item.Bought,
+			"purchased_amount": item.PurchasedAmount,
+			"purchased_at":     item.PurchasedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateShoppingIngredientItem は、アイテムの指定された列だけを、読み込んだときの版と一致する場合に更新し、版を1つ進めます。
+// 他の列（関連や、同時に他の利用者が変更した列）は上書きしません。
+func (r *planRepository) updateShoppingIngredientItem(db *gorm.DB, item *model.ShoppingIngredientItem, columns map[string]any) error {
+	columns["version"] = gorm.Expr("version + 1")
+	result := db.Model(&model.ShoppingIngredientItem{}).
+		Where("id = ? AND version = ?", item.ID, item.Version).
+		Updates(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFoundOrConflict(db.Model(&model.ShoppingIngredientItem{}).Where("id = ?", item.ID))
+	}
+	item.Version++
+	return nil
+}
+
+func (r *planRepository) IncrementShoppingPlanVersion(ctx context.Context, planID string, expectedVersion int) (int, error) {
+	db := r.db.WithContext(ctx)
+	query := db.Model(&model.ShoppingPlan{}).Where("id = ?", planID)
+	if expectedVersion != 0 {
+		query = query.Where("version = ?", expectedVersion)
+	}
+	result := query.Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, notFoundOrConflict(db.Model(&model.ShoppingPlan{}).Where("id = ?", planID))
+	}
+	var version int
+	if err := db.Model(&model.ShoppingPlan{}).Select("version").Where("id = ?", planID).Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// notFoundOrConflict は、版を指定した更新で行が更新されなかった理由として、行が存在しなければ repository.ErrNotFound を、
+// 存在すれば（版が変わっていたので）repository.ErrVersionConflict を返します。
+func notFoundOrConflict(query *gorm.DB) error {
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return repository.ErrNotFound
+	}
+	return repository.ErrVersionConflict
+}
+
+func (r *planRepository) SetShoppingPlansArchivedAt(ctx context.Context, planIDs []string, archivedAt *time.Time) (int64, error) {
+	if len(planIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Model(&model.ShoppingPlan{}).
+		Where("id IN ?", planIDs).
+		Update("archived_at", archivedAt)
+	return result.RowsAffected, result.Error
+}
+
+func (r *planRepository) DeleteShoppingPlan(ctx context.Context, planID string, expectedVersion int) error {
+	// 献立、買い物リスト、レシート、共有リンクは外部キーの ON DELETE CASCADE で削除される
+	db := r.db.WithContext(ctx)
+	query := db.Where("id = ?", planID)
+	if expectedVersion != 0 {
+		query = query.Where("version = ?", expectedVersion)
+	}
+	result := query.Delete(&model.ShoppingPlan{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return notFoundOrConflict(db.Model(&model.ShoppingPlan{}).Where("id = ?", planID))
+	}
+	return nil
+}
+
+func (r *planRepository) DeleteShoppingPlans(ctx context.Context, planIDs []string) (int64, error) {
+	if len(planIDs) == 0 {
+		return 0, nil
+	}
+	result := r.db.WithContext(ctx).Delete(&model.ShoppingPlan{}, "id IN ?", planIDs)
+	return result.RowsAffected, result.Error
+}
+
+func (r *planRepository) DeleteShoppingIngredientItem(ctx context.Context, itemID string) error {
+	result := r.db.WithContext(ctx).Delete(&model.ShoppingIngredientItem{}, "id = ?", itemID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *planRepository) CreateReceipt(ctx context.Context, receipt *model.Receipt) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Lines").Create(receipt).Error; err != nil {
+			return err
+		}
+		if len(receipt.Lines) == 0 {
+			return nil
+		}
+		for i := range receipt.Lines {
+			receipt.Lines[i].ReceiptID = receipt.ID
+		}
+		// 行の食材は登録済みのものを参照するだけなので、関連の自動保存は行わない
+		return tx.Omit("Ingredient").Create(&receipt.Lines).Error
+	})
+}
+
+func (r *planRepository) FindReceiptsByPlanID(ctx context.Context, planID string) ([]*model.Receipt, error) {
+	var receipts []*model.Receipt
+	err := r.preloadReceiptLines(r.db.WithContext(ctx)).
+		Where("plan_id = ?", planID).
+		Order("purchased_at ASC, created_at ASC").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+func (r *planRepository) FindReceiptsPurchasedBetween(ctx context.Context, from, to time.Time) ([]*model.Receipt, error) {
+	var receipts []*model.Receipt
+	err := r.preloadReceiptLines(r.db.WithContext(ctx)).
+		Where("purchased_at >= ? AND purchased_at < ?", from, to).
+		Order("purchased_at ASC, created_at ASC").
+		Find(&receipts).Error
+	return receipts, err
+}
+
+// preloadReceiptLines は、レシートの行を順番どおりに、行の食材と食材分類とともに読み込むよう指定します。
+func (r *planRepository) preloadReceiptLines(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Lines", orderPosition).
+		Preload("Lines.Ingredient.IngredientType")
+}
+
+func (r *planRepository) DeleteReceipt(ctx context.Context, planID, receiptID string) error {
+	// 行は外部キーの ON DELETE CASCADE で削除される
+	result := r.db.WithContext(ctx).Delete(&model.Receipt{}, "id = ? AND plan_id = ?", receiptID, planID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
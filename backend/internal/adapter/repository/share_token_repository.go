@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// shareTokenRepository は repository.ShareTokenRepository の実装です。
+type shareTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewShareTokenRepository は新しい shareTokenRepository のインスタンスを生成します。
+func NewShareTokenRepository(db *gorm.DB) repository.ShareTokenRepository {
+	return &shareTokenRepository{db: db}
+}
+
+func (r *shareTokenRepository) CreateShareToken(ctx context.Context, token *model.ShareToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *shareTokenRepository) RevokeShareToken(ctx context.Context, planID, tokenID string, revokedAt time.Time) error {
+	var token model.ShareToken
+	if err := r.db.WithContext(ctx).First(&token, "id = ? AND plan_id = ?", tokenID, planID).Error; err != nil {
+		return translateError(err)
+	}
+	if token.RevokedAt != nil {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(&token).Update("revoked_at", revokedAt).Error
+}
+
+func (r *shareTokenRepository) FindShareTokensByPlanID(ctx context.Context, planID string) ([]*model.ShareToken, error) {
+	var tokens []*model.ShareToken
+	err := r.db.WithContext(ctx).
+		Where("plan_id = ?", planID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+func (r *shareTokenRepository) FindShareTokenByHash(ctx context.Context, tokenHash string) (*model.ShareToken, error) {
+	var token model.ShareToken
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
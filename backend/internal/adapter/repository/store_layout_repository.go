@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+type storeLayoutRepository struct {
+	db *gorm.DB
+}
+
+// NewStoreLayoutRepository は新しい storeLayoutRepository のインスタンスを生成します。
+func NewStoreLayoutRepository(db *gorm.DB) repository.StoreLayoutRepository {
+	return &storeLayoutRepository{db: db}
+}
+
+func (r *storeLayoutRepository) CreateStoreLayout(ctx context.Context, layout *model.StoreLayout) error {
+	// 売り場と売り場の食材分類・食材（has many）も合わせて保存される。分類・食材そのものは参照するだけ
+	return r.db.WithContext(ctx).
+		Omit("Sections.Entries.IngredientType", "Sections.Entries.Ingredient").
+		Create(layout).Error
+}
+
+func (r *storeLayoutRepository) ReplaceStoreLayout(ctx context.Context, layout *model.StoreLayout) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(layout).Update("name", layout.Name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("layout_id = ?", layout.ID).Delete(&model.StoreSectionEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("layout_id = ?", layout.ID).Delete(&model.StoreSection{}).Error; err != nil {
+			return err
+		}
+		if len(layout.Sections) == 0 {
+			return nil
+		}
+		for i := range layout.Sections {
+			layout.Sections[i].LayoutID = layout.ID
+		}
+		return tx.Omit("Entries.IngredientType", "Entries.Ingredient").Create(&layout.Sections).Error
+	})
+}
+
+func (r *storeLayoutRepository) DeleteStoreLayout(ctx context.Context, layoutID string) error {
+	// 売り場と売り場の食材分類・食材は外部キーの ON DELETE CASCADE で削除される
+	result := r.db.WithContext(ctx).Delete(&model.StoreLayout{}, "id = ?", layoutID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *storeLayoutRepository) FindStoreLayouts(ctx context.Context) ([]*model.StoreLayout, error) {
+	var layouts []*model.StoreLayout
+	err := preloadStoreSections(r.db.WithContext(ctx)).
+		Order("name ASC").
+		Find(&layouts).Error
+	return layouts, err
+}
+
+func (r *storeLayoutRepository) FindStoreLayoutByID(ctx context.Context, layoutID string) (*model.StoreLayout, error) {
+	var layout model.StoreLayout
+	err := preloadStoreSections(r.db.WithContext(ctx)).
+		First(&layout, "id = ?", layoutID).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &layout, nil
+}
+
+// preloadStoreSections は、売り場と売り場の食材分類・食材を歩く順にPreloadします。
+func preloadStoreSections(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Sections", orderPosition).
+		Preload("Sections.Entries", orderPosition).
+		Preload("Sections.Entries.IngredientType").
+		Preload("Sections.Entries.Ingredient")
+}
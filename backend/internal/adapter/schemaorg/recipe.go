@@ -0,0 +1,166 @@
+// Package schemaorg は、保存されたWebページに埋め込まれた schema.org/Recipe の JSON-LD を読み取ります。
+package schemaorg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrRecipeNotFound は、ドキュメント内に schema.org/Recipe が見つからない場合に返されます。
+var ErrRecipeNotFound = errors.New("schema.org/Recipe が見つかりません")
+
+// Recipe は、JSON-LD から取り出したレシピ情報です。
+type Recipe struct {
+	Name         string
+	URL          string
+	Yield        string
+	Ingredients  []string
+	Instructions []string
+}
+
+// scriptPattern は、HTML内の <script type="application/ld+json"> 要素にマッチします。
+var scriptPattern = regexp.MustCompile(`(?is)<script[^>]*type\s*=\s*["']?application/ld\+json["']?[^>]*>(.*?)</script>`)
+
+// ExtractRecipe は、HTMLまたはJSON-LDのドキュメントから最初に見つかった Recipe を返します。
+func ExtractRecipe(data []byte) (*Recipe, error) {
+	trimmed := bytes.TrimSpace(data)
+	trimmed = bytes.TrimPrefix(trimmed, []byte("\xEF\xBB\xBF"))
+
+	var blocks [][]byte
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		blocks = append(blocks, trimmed)
+	} else {
+		for _, m := range scriptPattern.FindAllSubmatch(trimmed, -1) {
+			blocks = append(blocks, m[1])
+		}
+	}
+
+	for _, block := range blocks {
+		var node any
+		if err := json.Unmarshal(bytes.TrimSpace(block), &node); err != nil {
+			// 壊れたブロックがあっても、他のブロックにレシピがあれば読み取れるようにする
+			continue
+		}
+		if obj := findRecipe(node); obj != nil {
+			return toRecipe(obj), nil
+		}
+	}
+	return nil, ErrRecipeNotFound
+}
+
+// findRecipe は、JSON-LD のノードを再帰的に探索し、@type が Recipe のオブジェクトを返します。
+// @graph や配列の中に入れ子になっている場合にも対応します。
+func findRecipe(node any) map[string]any {
+	switch v := node.(type) {
+	case []any:
+		for _, child := range v {
+			if obj := findRecipe(child); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		if isRecipeType(v["@type"]) {
+			return v
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findRecipe(graph)
+		}
+		if entity, ok := v["mainEntity"]; ok {
+			return findRecipe(entity)
+		}
+	}
+	return nil
+}
+
+func isRecipeType(t any) bool {
+	switch v := t.(type) {
+	case string:
+		return v == "Recipe" || strings.HasSuffix(v, "/Recipe")
+	case []any:
+		for _, item := range v {
+			if isRecipeType(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func toRecipe(obj map[string]any) *Recipe {
+	recipe := &Recipe{
+		Name:  text(obj["name"]),
+		URL:   text(obj["url"]),
+		Yield: text(obj["recipeYield"]),
+	}
+	switch v := obj["recipeIngredient"].(type) {
+	case []any:
+		for _, item := range v {
+			if line := text(item); line != "" {
+				recipe.Ingredients = append(recipe.Ingredients, line)
+			}
+		}
+	case string:
+		if line := text(v); line != "" {
+			recipe.Ingredients = append(recipe.Ingredients, line)
+		}
+	}
+	recipe.Instructions = instructions(obj["recipeInstructions"])
+	return recipe
+}
+
+// instructions は、recipeInstructions を手順の文字列のリストに変換します。
+// 文字列、文字列の配列、HowToStep の配列、HowToSection で区切られた HowToStep のいずれにも対応します。
+func instructions(v any) []string {
+	var steps []string
+	switch t := v.(type) {
+	case string:
+		for _, line := range strings.Split(html.UnescapeString(t), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				steps = append(steps, line)
+			}
+		}
+	case []any:
+		for _, item := range t {
+			steps = append(steps, instructions(item)...)
+		}
+	case map[string]any:
+		if elements, ok := t["itemListElement"]; ok {
+			return instructions(elements)
+		}
+		if step := text(t["text"]); step != "" {
+			steps = append(steps, step)
+		} else if step := text(t["name"]); step != "" {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// text は、JSON-LD の値を文字列として取り出します。
+// 配列の場合は最初の要素を使い、HTMLエンティティはデコードします。
+func text(v any) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(html.UnescapeString(t))
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case []any:
+		if len(t) > 0 {
+			return text(t[0])
+		}
+	case map[string]any:
+		// {"@id": "..."} のような参照の場合
+		if id, ok := t["@id"]; ok {
+			return text(id)
+		}
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
@@ -16,6 +16,11 @@ type Config struct {
 	// バックエンドアプリケーションがリッスンするポート
 	GoAppPort string `envconfig:"GO_APP_PORT" default:"8080"`
 
+	// 計画の持ち主のトークン。共有リンク以外のAPIは、このトークンを Authorization ヘッダー (Bearer) で指定するか、
+	// POST /api/session でこのトークンと引き換えに発行したセッションのクッキーを付けて呼び出す
+	// 開発環境 (debug) でのみ省略でき、省略した場合は確かめない
+	OwnerToken string `envconfig:"OWNER_TOKEN"`
+
 	// データベース接続設定
 	DBHost      string `envconfig:"DB_HOST" required:"true"`
 	DBPort      string `envconfig:"DB_PORT" required:"true"`
@@ -23,6 +28,19 @@ type Config struct {
 	DBPassword  string `envconfig:"DB_PASSWORD" required:"true"`
 	DBName      string `envconfig:"DB_NAME" required:"true"`
 	DBDsnParams string `envconfig:"DB_DSN_PARAMS" required:"true"`
+
+	// 献立のカレンダー（.ics）の予定の既定の時刻 (HH:MM) と長さ、タイムゾーン
+	MealTimeMorning     string `envconfig:"MEAL_TIME_MORNING" default:"07:30"`
+	MealTimeLunch       string `envconfig:"MEAL_TIME_LUNCH" default:"12:00"`
+	MealTimeDinner      string `envconfig:"MEAL_TIME_DINNER" default:"19:00"`
+	MealDurationMinutes int    `envconfig:"MEAL_DURATION_MINUTES" default:"60"`
+	CalendarTimeZone    string `envconfig:"CALENDAR_TIME_ZONE" default:"Asia/Tokyo"`
+
+	// 古い計画の整理：開始日からの日数でアーカイブ・削除する（0 の場合は行わない）。dry-run では対象を記録するだけで変更しない
+	PlanArchiveAfterDays         int  `envconfig:"PLAN_ARCHIVE_AFTER_DAYS" default:"0"`
+	PlanDeleteAfterDays          int  `envconfig:"PLAN_DELETE_AFTER_DAYS" default:"0"`
+	PlanRetentionIntervalMinutes int  `envconfig:"PLAN_RETENTION_INTERVAL_MINUTES" default:"1440"`
+	PlanRetentionDryRun          bool `envconfig:"PLAN_RETENTION_DRY_RUN" default:"false"`
 }
 
 // Load は、環境変数を読み込み、Config構造体にマッピングして返します。
@@ -1,18 +1,33 @@
 package model
 
+import "time"
+
 // Ingredient は、個別の食材情報を表すモデルです。
 type Ingredient struct {
 	BaseModel
-	TypeID                 string          `gorm:"type:char(36);not null" json:"type_id"`
-	Name                   string          `gorm:"type:varchar(255);not null;unique" json:"name"`
-	BaseAmount             float64         `gorm:"type:decimal(10,2);not null" json:"base_amount"`
-	Unit                   string          `gorm:"type:varchar(50);not null" json:"unit"`
-	ShelfLifeDaysUnopened *int            `gorm:"default:null" json:"shelf_life_days_unopened"`
-	ShelfLifeDaysOpened   *int            `gorm:"default:null" json:"shelf_life_days_opened"`
-	IngredientType        IngredientType  `gorm:"foreignKey:TypeID" json:"type"` // Ingredient belongs to IngredientType
+	TypeID                  string            `gorm:"type:char(36);not null" json:"type_id"`
+	Name                    string            `gorm:"type:varchar(255);not null;unique" json:"name"`
+	BaseAmount              float64           `gorm:"type:decimal(10,2);not null" json:"base_amount"`
+	Unit                    string            `gorm:"type:varchar(50);not null" json:"unit"`
+	ShelfLifeDaysUnopened   *int              `gorm:"default:null" json:"shelf_life_days_unopened"`
+	ShelfLifeDaysOpened     *int              `gorm:"default:null" json:"shelf_life_days_opened"`
+	SeasonMonths            SeasonMonths      `gorm:"type:smallint unsigned;not null;default:0" json:"season_months"`  // 旬の月
+	SeasonalPriceMultiplier *float64          `gorm:"type:decimal(4,2);default:null" json:"seasonal_price_multiplier"` // 旬の時期の価格の目安（通常を1とした倍率）
+	IngredientType          IngredientType    `gorm:"foreignKey:TypeID" json:"type"`                                   // Ingredient belongs to IngredientType
+	Aliases                 []IngredientAlias `gorm:"foreignKey:IngredientID" json:"-"`                                // Ingredient has many IngredientAliases
 }
 
 // TableName は、GORMにテーブル名を明示的に指定します。
 func (Ingredient) TableName() string {
 	return "ingredients"
-}
\ No newline at end of file
+}
+
+// HasSeason は、食材に旬が設定されているかどうかを返します。
+func (i *Ingredient) HasSeason() bool {
+	return i.SeasonMonths != 0
+}
+
+// InSeason は、指定された月が食材の旬かどうかを返します。旬が設定されていない食材は常に false です。
+func (i *Ingredient) InSeason(month time.Month) bool {
+	return i.SeasonMonths.Contains(month)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+	"gorm.io/gorm"
+)
+
+// IngredientAlias は、食材の別名（表記ゆれ）を表すモデルです。
+// "たまねぎ" や "玉葱" のような表記から、登録済みの食材を引けるようにします。
+type IngredientAlias struct {
+	BaseModel
+	IngredientID   string `gorm:"type:char(36);not null;index" json:"ingredient_id"`
+	Alias          string `gorm:"type:varchar(255);not null" json:"alias"`
+	NormalizedName string `gorm:"type:varchar(255);not null;uniqueIndex:uq_normalized_name" json:"-"` // NormalizeIngredientName で正規化した別名。検索のキーになります
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (IngredientAlias) TableName() string {
+	return "ingredient_aliases"
+}
+
+// BeforeSave は、GORMのフックで、レコード保存前に呼び出されます。
+// 正規化した別名を常に Alias から作り直します。
+func (a *IngredientAlias) BeforeSave(tx *gorm.DB) (err error) {
+	a.NormalizedName = NormalizeIngredientName(a.Alias)
+	return
+}
+
+// NormalizeIngredientName は、食材名を表記ゆれを吸収した比較用の文字列に変換します。
+// 全角英数字と半角カナを揃え、カタカナをひらがなに、英字を小文字にしたうえで、空白と中黒を取り除きます。
+// "タマネギ", "ﾀﾏﾈｷﾞ", "たま ねぎ" はいずれも "たまねぎ" になります。漢字表記（"玉葱"）は別名として登録します。
+func NormalizeIngredientName(name string) string {
+	folded := width.Fold.String(name)
+	var b strings.Builder
+	b.Grow(len(folded))
+	for _, r := range folded {
+		switch {
+		case unicode.IsSpace(r) || r == '・':
+			continue
+		case r >= 'ァ' && r <= 'ヶ':
+			// カタカナをひらがなに変換する（"ヴ" も "ゔ" になる）
+			r -= 'ァ' - 'ぁ'
+		default:
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package model
+
+// IngredientSubstitution は、ある食材を別の食材で代用するルールを表すモデルです。
+// 代用元の分量に Ratio を掛けたものが、代用先の食材の単位での分量になります。
+// ルールには向きがあり、相互に代用できる場合は逆向きのルールも登録します。
+type IngredientSubstitution struct {
+	BaseModel
+	FromIngredientID string     `gorm:"type:char(36);not null;uniqueIndex:uq_substitution" json:"from_ingredient_id"`
+	ToIngredientID   string     `gorm:"type:char(36);not null;uniqueIndex:uq_substitution" json:"to_ingredient_id"`
+	Ratio            float64    `gorm:"type:decimal(10,4);not null" json:"ratio"`
+	FromIngredient   Ingredient `gorm:"foreignKey:FromIngredientID" json:"-"`
+	ToIngredient     Ingredient `gorm:"foreignKey:ToIngredientID" json:"-"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (IngredientSubstitution) TableName() string {
+	return "ingredient_substitutions"
+}
@@ -4,10 +4,16 @@ package model
 type Menu struct {
 	BaseModel
 	Name                string               `gorm:"type:varchar(255);not null;unique" json:"name"`
-	MenuIngredientItems []MenuIngredientItem `gorm:"foreignKey:MenuID" json:"-"` // Menu has many MenuIngredientItems
+	Servings            int                  `gorm:"not null;default:1" json:"servings"`                   // 手順どおりに作ったときの出来上がり量（何人前か）
+	Tips                *string              `gorm:"type:text;default:null" json:"tips"`                   // 調理のコツやメモ
+	SourceURL           *string              `gorm:"type:varchar(2048);default:null" json:"source_url"`    // レシピの出典
+	CurrentVersionID    *string              `gorm:"type:char(36);default:null" json:"current_version_id"` // 最新の版
+	CurrentVersion      *MenuVersion         `gorm:"foreignKey:CurrentVersionID" json:"-"`                 // Menu belongs to its latest MenuVersion
+	MenuIngredientItems []MenuIngredientItem `gorm:"foreignKey:MenuID" json:"-"`                           // Menu has many MenuIngredientItems
+	Steps               []MenuStep           `gorm:"foreignKey:MenuID" json:"-"`                           // Menu has many MenuSteps
 }
 
 // TableName は、GORMにテーブル名を明示的に指定します。
 func (Menu) TableName() string {
 	return "menus"
-}
\ No newline at end of file
+}
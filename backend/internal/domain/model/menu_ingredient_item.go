@@ -3,9 +3,9 @@ package model
 // MenuIngredientItem は、メニューと食材の関連（レシピ）を表すモデルです。
 type MenuIngredientItem struct {
 	BaseModel
-	MenuID       string     `gorm:"type:char(36);not null;uniqueIndex:uq_menu_ingredient" json:"menu_id"`
-	IngredientID string     `gorm:"type:char(36);not null;uniqueIndex:uq_menu_ingredient" json:"ingredient_id"`
-	Amount       float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
+	MenuID       string     `gorm:"type:char(36);not null;uniqueIndex:uq_menu_ingredient;index:idx_ingredient_menu,priority:2" json:"menu_id"`
+	IngredientID string     `gorm:"type:char(36);not null;uniqueIndex:uq_menu_ingredient;index:idx_ingredient_menu,priority:1" json:"ingredient_id"` // idx_ingredient_menu は食材からメニューを引く転置インデックス
+	Amount       float64    `gorm:"type:decimal(10,2);not null;index:idx_ingredient_menu,priority:3" json:"amount"`
 	Menu         Menu       `gorm:"foreignKey:MenuID" json:"-"`
 	Ingredient   Ingredient `gorm:"foreignKey:IngredientID" json:"-"`
 }
@@ -0,0 +1,14 @@
+package model
+
+// MenuStep は、メニューの調理手順の1ステップを表すモデルです。
+type MenuStep struct {
+	BaseModel
+	MenuID      string `gorm:"type:char(36);not null;uniqueIndex:uq_menu_step_position" json:"menu_id"`
+	Position    int    `gorm:"not null;uniqueIndex:uq_menu_step_position" json:"position"` // 1から始まる手順の番号
+	Instruction string `gorm:"type:text;not null" json:"instruction"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (MenuStep) TableName() string {
+	return "menu_steps"
+}
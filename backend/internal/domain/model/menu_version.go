@@ -0,0 +1,116 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+)
+
+// MenuVersion は、ある時点のメニューの内容（レシピと調理手順）を固定したスナップショットです。
+// メニューの内容が変わるたびに新しい版が作られ、作成済みの版は変更しません。
+// 食事計画は版を参照するため、後からレシピを編集しても計画の表示は作成時のまま保たれます。
+type MenuVersion struct {
+	BaseModel
+	MenuID      string                  `gorm:"type:char(36);not null;uniqueIndex:uq_menu_version" json:"menu_id"`
+	Version     int                     `gorm:"not null;uniqueIndex:uq_menu_version" json:"version"` // メニューごとに1から始まる版の番号
+	Name        string                  `gorm:"type:varchar(255);not null" json:"name"`
+	Servings    int                     `gorm:"not null;default:1" json:"servings"`
+	Tips        *string                 `gorm:"type:text;default:null" json:"tips"`
+	SourceURL   *string                 `gorm:"type:varchar(2048);default:null" json:"source_url"`
+	ContentHash string                  `gorm:"type:char(64);not null" json:"-"` // 内容が前の版から変わったかを判定するためのハッシュ
+	Ingredients []MenuVersionIngredient `gorm:"foreignKey:MenuVersionID" json:"-"`
+	Steps       []MenuVersionStep       `gorm:"foreignKey:MenuVersionID" json:"-"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (MenuVersion) TableName() string {
+	return "menu_versions"
+}
+
+// MenuVersionIngredient は、版に含まれるレシピの材料1行です。
+// 食材名と単位も写し取るため、食材マスターが変わっても版の内容は変わりません。
+type MenuVersionIngredient struct {
+	BaseModel
+	MenuVersionID  string  `gorm:"type:char(36);not null;index" json:"menu_version_id"`
+	Position       int     `gorm:"not null" json:"position"`
+	IngredientID   string  `gorm:"type:char(36);not null" json:"ingredient_id"`
+	IngredientName string  `gorm:"type:varchar(255);not null" json:"ingredient_name"`
+	Unit           string  `gorm:"type:varchar(50);not null" json:"unit"`
+	Amount         float64 `gorm:"type:decimal(10,2);not null" json:"amount"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (MenuVersionIngredient) TableName() string {
+	return "menu_version_ingredients"
+}
+
+// MenuVersionStep は、版に含まれる調理手順の1ステップです。
+type MenuVersionStep struct {
+	BaseModel
+	MenuVersionID string `gorm:"type:char(36);not null;index" json:"menu_version_id"`
+	Position      int    `gorm:"not null" json:"position"`
+	Instruction   string `gorm:"type:text;not null" json:"instruction"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (MenuVersionStep) TableName() string {
+	return "menu_version_steps"
+}
+
+// NewMenuVersion は、メニューの現在の内容から版を作成します。版の番号は設定しません。
+// menu の MenuIngredientItems（Ingredient を含む）と Steps は読み込み済みである必要があります。
+func NewMenuVersion(menu *Menu) *MenuVersion {
+	version := &MenuVersion{
+		MenuID:    menu.ID,
+		Name:      menu.Name,
+		Servings:  menu.Servings,
+		Tips:      menu.Tips,
+		SourceURL: menu.SourceURL,
+	}
+	for i, item := range menu.MenuIngredientItems {
+		version.Ingredients = append(version.Ingredients, MenuVersionIngredient{
+			Position:       i + 1,
+			IngredientID:   item.IngredientID,
+			IngredientName: item.Ingredient.Name,
+			Unit:           item.Ingredient.Unit,
+			Amount:         item.Amount,
+		})
+	}
+	for i, step := range menu.Steps {
+		version.Steps = append(version.Steps, MenuVersionStep{Position: i + 1, Instruction: step.Instruction})
+	}
+	version.ContentHash = version.hash()
+	return version
+}
+
+// hash は、版の内容（番号やIDを除く）から ContentHash を計算します。
+func (v *MenuVersion) hash() string {
+	type ingredient struct {
+		ID     string  `json:"id"`
+		Name   string  `json:"name"`
+		Unit   string  `json:"unit"`
+		Amount float64 `json:"amount"`
+	}
+	content := struct {
+		Name        string       `json:"name"`
+		Servings    int          `json:"servings"`
+		Tips        *string      `json:"tips"`
+		SourceURL   *string      `json:"source_url"`
+		Ingredients []ingredient `json:"ingredients"`
+		Steps       []string     `json:"steps"`
+	}{Name: v.Name, Servings: v.Servings, Tips: v.Tips, SourceURL: v.SourceURL}
+	for _, ing := range v.Ingredients {
+		content.Ingredients = append(content.Ingredients, ingredient{ID: ing.IngredientID, Name: ing.IngredientName, Unit: ing.Unit, Amount: ing.Amount})
+	}
+	// 材料の並び順は内容の変更とみなさない
+	sort.Slice(content.Ingredients, func(i, j int) bool {
+		return content.Ingredients[i].ID < content.Ingredients[j].ID
+	})
+	for _, step := range v.Steps {
+		content.Steps = append(content.Steps, step.Instruction)
+	}
+	data, _ := json.Marshal(content)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -14,14 +14,17 @@ const (
 // PlanningMealItem は、計画された個々の食事を表すモデルです。
 type PlanningMealItem struct {
 	BaseModel
-	PlanID     string     `gorm:"type:char(36);not null" json:"plan_id"`
-	MenuID     string     `gorm:"type:char(36);not null" json:"menu_id"`
-	Date       time.Time  `gorm:"type:date;not null" json:"date"`
-	MealPeriod MealPeriod `gorm:"type:enum('MORNING', 'LUNCH', 'DINNER');not null" json:"meal_period"`
-	Menu       Menu       `gorm:"foreignKey:MenuID" json:"-"`
+	PlanID        string                     `gorm:"type:char(36);not null" json:"plan_id"`
+	MenuID        string                     `gorm:"type:char(36);not null" json:"menu_id"`
+	MenuVersionID *string                    `gorm:"type:char(36);default:null" json:"menu_version_id"` // 計画作成時のメニューの版。表示はこの版の内容で行います
+	Date          time.Time                  `gorm:"type:date;not null" json:"date"`
+	MealPeriod    MealPeriod                 `gorm:"type:enum('MORNING', 'LUNCH', 'DINNER');not null" json:"meal_period"`
+	Menu          Menu                       `gorm:"foreignKey:MenuID" json:"-"`
+	MenuVersion   *MenuVersion               `gorm:"foreignKey:MenuVersionID" json:"-"`
+	Substitutions []PlanningMealSubstitution `gorm:"foreignKey:MealID" json:"-"` // PlanningMealItem has many PlanningMealSubstitutions
 }
 
 // TableName は、GORMにテーブル名を明示的に指定します。
 func (PlanningMealItem) TableName() string {
 	return "planning_meal_items"
-}
\ No newline at end of file
+}
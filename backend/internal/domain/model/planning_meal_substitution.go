@@ -0,0 +1,32 @@
+package model
+
+// SubstitutionReason は、食材を代用した理由を表す型です。
+type SubstitutionReason string
+
+const (
+	// SubstitutionAvoid は、避けたい食材として指定されたために代用したことを表します。
+	SubstitutionAvoid SubstitutionReason = "AVOID"
+	// SubstitutionOnHand は、手持ちの食材を使うために代用したことを表します。
+	SubstitutionOnHand SubstitutionReason = "ON_HAND"
+)
+
+// PlanningMealSubstitution は、計画された食事で行った食材の代用1件を表すモデルです。
+// 分量は1人前で、食材名と単位も写し取るため、食材マスターが変わっても内容は変わりません。
+type PlanningMealSubstitution struct {
+	BaseModel
+	MealID           string             `gorm:"type:char(36);not null;index" json:"meal_id"`
+	FromIngredientID string             `gorm:"type:char(36);not null" json:"from_ingredient_id"`
+	FromName         string             `gorm:"type:varchar(255);not null" json:"from_name"`
+	FromAmount       float64            `gorm:"type:decimal(10,2);not null" json:"from_amount"`
+	FromUnit         string             `gorm:"type:varchar(50);not null" json:"from_unit"`
+	ToIngredientID   string             `gorm:"type:char(36);not null" json:"to_ingredient_id"`
+	ToName           string             `gorm:"type:varchar(255);not null" json:"to_name"`
+	ToAmount         float64            `gorm:"type:decimal(10,2);not null" json:"to_amount"`
+	ToUnit           string             `gorm:"type:varchar(50);not null" json:"to_unit"`
+	Reason           SubstitutionReason `gorm:"type:enum('AVOID', 'ON_HAND');not null" json:"reason"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (PlanningMealSubstitution) TableName() string {
+	return "planning_meal_substitutions"
+}
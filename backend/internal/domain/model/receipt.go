@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+// Receipt は、買い物計画に記録した1回分の実際の買い物（レシート）を表すモデルです。
+type Receipt struct {
+	BaseModel
+	PlanID      string        `gorm:"type:char(36);not null;index:idx_receipt_plan" json:"plan_id"`
+	StoreName   *string       `gorm:"type:varchar(255);default:null" json:"store_name"`
+	PurchasedAt time.Time     `gorm:"type:datetime(6);not null;index:idx_receipt_purchased_at" json:"purchased_at"`
+	Total       int           `gorm:"not null" json:"total"`         // 支払った金額の合計（円）
+	Lines       []ReceiptLine `gorm:"foreignKey:ReceiptID" json:"-"` // Receipt has many ReceiptLines
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (Receipt) TableName() string {
+	return "receipts"
+}
+
+// ReceiptLine は、レシートの1行（購入した品物と支払った金額）を表すモデルです。
+// 食材に対応付けた行は、食材ごとの実際の価格として集計します。
+type ReceiptLine struct {
+	BaseModel
+	ReceiptID      string      `gorm:"type:char(36);not null;index:idx_receipt_line_receipt" json:"receipt_id"`
+	Position       int         `gorm:"not null" json:"position"`
+	Name           string      `gorm:"type:varchar(255);not null" json:"name"` // レシートの品名
+	IngredientID   *string     `gorm:"type:char(36);default:null;index:idx_receipt_line_ingredient" json:"ingredient_id"`
+	ShoppingItemID *string     `gorm:"type:char(36);default:null" json:"shopping_item_id"` // 購入済みにした買い物リストのアイテム
+	Quantity       *float64    `gorm:"type:decimal(10,2);default:null" json:"quantity"`
+	Unit           *string     `gorm:"type:varchar(50);default:null" json:"unit"`
+	Price          int         `gorm:"not null" json:"price"` // 支払った金額（円、値引き後）
+	Ingredient     *Ingredient `gorm:"foreignKey:IngredientID" json:"-"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (ReceiptLine) TableName() string {
+	return "receipt_lines"
+}
+
+// UnitPrice は、食材の単位あたりの価格を返します。
+// 食材に対応付けられていない場合や、量が食材の単位で記録されていない場合は nil です。
+func (l *ReceiptLine) UnitPrice() *float64 {
+	if l.Ingredient == nil || l.Quantity == nil || *l.Quantity <= 0 {
+		return nil
+	}
+	if l.Unit != nil && *l.Unit != l.Ingredient.Unit {
+		return nil
+	}
+	price := float64(l.Price) / *l.Quantity
+	return &price
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SeasonMonths は、食材の旬の月の集合です。1月をbit0、12月をbit11とするビットマスクで保存します。
+// ゼロ値は旬が設定されていない（通年出回る、または季節を問わない）ことを表します。
+type SeasonMonths uint16
+
+// NewSeasonMonths は、月の番号（1〜12）の一覧から SeasonMonths を作成します。
+func NewSeasonMonths(months []int) (SeasonMonths, error) {
+	var s SeasonMonths
+	for _, m := range months {
+		if m < 1 || m > 12 {
+			return 0, fmt.Errorf("月は1〜12で指定してください: %d", m)
+		}
+		s |= 1 << (m - 1)
+	}
+	return s, nil
+}
+
+// Contains は、指定された月が旬に含まれるかどうかを返します。
+func (s SeasonMonths) Contains(month time.Month) bool {
+	return s&(1<<(month-1)) != 0
+}
+
+// Months は、旬の月の番号を昇順で返します。
+func (s SeasonMonths) Months() []int {
+	months := []int{}
+	for m := 1; m <= 12; m++ {
+		if s.Contains(time.Month(m)) {
+			months = append(months, m)
+		}
+	}
+	return months
+}
+
+// MarshalJSON は、旬の月を番号の配列として出力します。
+func (s SeasonMonths) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Months())
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// ShareScope は、共有リンクで許可する操作の範囲を表す型です。
+type ShareScope string
+
+const (
+	// ShareReadOnly は、献立と買い物リストの閲覧だけを許可します。
+	ShareReadOnly ShareScope = "READ_ONLY"
+	// ShareCheckOff は、閲覧に加えて、買い物リストのアイテムの購入済みのチェック（購入した量の記録を含む）を許可します。
+	ShareCheckOff ShareScope = "CHECK_OFF"
+)
+
+// Allows は、この範囲で required の操作が許可されているかどうかを返します。
+func (s ShareScope) Allows(required ShareScope) bool {
+	switch s {
+	case ShareCheckOff:
+		return required == ShareReadOnly || required == ShareCheckOff
+	case ShareReadOnly:
+		return required == ShareReadOnly
+	}
+	return false
+}
+
+// ShareToken は、買い物計画を共有するリンクのトークンを表すモデルです。
+// トークンそのものは発行時に一度だけ返し、保存するのはハッシュ（TokenHash）だけです。
+type ShareToken struct {
+	BaseModel
+	PlanID    string     `gorm:"type:char(36);not null;index:idx_share_token_plan" json:"plan_id"`
+	TokenHash string     `gorm:"type:char(64);not null;uniqueIndex:uq_share_token_hash" json:"-"`
+	Scope     ShareScope `gorm:"type:enum('READ_ONLY', 'CHECK_OFF');not null" json:"scope"`
+	ExpiresAt time.Time  `gorm:"type:datetime(6);not null" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"type:datetime(6);default:null" json:"revoked_at"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (ShareToken) TableName() string {
+	return "share_tokens"
+}
+
+// ActiveAt は、指定した時刻にトークンが有効（無効にされておらず、期限内）かどうかを返します。
+func (t *ShareToken) ActiveAt(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -1,16 +1,75 @@
 package model
 
+import "time"
+
 // ShoppingIngredientItem は、買い物リストの個々のアイテムを表すモデルです。
+// 献立のレシピから計算したアイテムのほかに、利用者が手動で追加したアイテム（Manual）があります。
+// 手動のアイテムはカタログの食材を指すか、食材IDを持たない自由入力（Name と Unit）のどちらかです。
+// レシピから計算したアイテムは計画の作成時にだけ作り、手動のアイテムは利用者が削除するまで残ります。
+// 買い物を複数回に分ける場合は、同じ食材でも買いに行く日（TripDate）ごとに別のアイテムになります。
 type ShoppingIngredientItem struct {
 	BaseModel
-	PlanID       string     `gorm:"type:char(36);not null;uniqueIndex:uq_plan_ingredient" json:"plan_id"`
-	IngredientID string     `gorm:"type:char(36);not null;uniqueIndex:uq_plan_ingredient" json:"ingredient_id"`
-	Amount       float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
-	Bought       bool       `gorm:"not null;default:false" json:"bought"`
-	Ingredient   Ingredient `gorm:"foreignKey:IngredientID" json:"-"`
+	PlanID          string     `gorm:"type:char(36);not null;uniqueIndex:uq_plan_ingredient_manual_trip" json:"plan_id"`
+	IngredientID    *string    `gorm:"type:char(36);uniqueIndex:uq_plan_ingredient_manual_trip" json:"ingredient_id"`
+	Name            *string    `gorm:"type:varchar(255)" json:"name"`
+	Amount          float64    `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Unit            *string    `gorm:"type:varchar(50)" json:"unit"`
+	TripDate        time.Time  `gorm:"type:date;not null;uniqueIndex:uq_plan_ingredient_manual_trip" json:"trip_date"` // 買いに行く日（この日までに買う）
+	FirstUseDate    *time.Time `gorm:"type:date;default:null" json:"first_use_date"`                                   // 献立で最初に使う日。手動のアイテムは nil
+	PurchasedAmount *float64   `gorm:"type:decimal(10,2);default:null" json:"purchased_amount"`                        // 実際に購入した量。未記録の場合は nil
+	PurchasedAt     *time.Time `gorm:"type:datetime(6);default:null" json:"purchased_at"`
+	Bought          bool       `gorm:"not null;default:false" json:"bought"`
+	Note            *string    `gorm:"type:varchar(255)" json:"note"`
+	Manual          bool       `gorm:"not null;default:false;uniqueIndex:uq_plan_ingredient_manual_trip" json:"manual"`
+	Version         int        `gorm:"not null;default:1" json:"version"` // 更新のたびに1つ進む版（楽観的排他制御に使う）
+	Ingredient      Ingredient `gorm:"foreignKey:IngredientID" json:"-"`
 }
 
 // TableName は、GORMにテーブル名を明示的に指定します。
 func (ShoppingIngredientItem) TableName() string {
 	return "shopping_ingredient_items"
-}
\ No newline at end of file
+}
+
+// DisplayName は、アイテムの表示名を返します。カタログの食材は食材名、自由入力はその名前です。
+func (i *ShoppingIngredientItem) DisplayName() string {
+	if i.IngredientID == nil && i.Name != nil {
+		return *i.Name
+	}
+	return i.Ingredient.Name
+}
+
+// DisplayUnit は、アイテムの単位を返します。
+func (i *ShoppingIngredientItem) DisplayUnit() string {
+	if i.IngredientID == nil {
+		if i.Unit != nil {
+			return *i.Unit
+		}
+		return ""
+	}
+	return i.Ingredient.Unit
+}
+
+// Purchased は、購入した量を返します。購入量が記録されていない場合、購入済みなら必要量をちょうど購入したものとみなします。
+func (i *ShoppingIngredientItem) Purchased() float64 {
+	switch {
+	case i.PurchasedAmount != nil:
+		return *i.PurchasedAmount
+	case i.Bought:
+		return i.Amount
+	default:
+		return 0
+	}
+}
+
+// Remaining は、まだ買う必要がある量を返します。購入済みのアイテムは、必要量に足りなくても0です。
+func (i *ShoppingIngredientItem) Remaining() float64 {
+	if i.Bought {
+		return 0
+	}
+	return max(i.Amount-i.Purchased(), 0)
+}
+
+// Excess は、必要量を超えて購入した量（残りもの）を返します。
+func (i *ShoppingIngredientItem) Excess() float64 {
+	return max(i.Purchased()-i.Amount, 0)
+}
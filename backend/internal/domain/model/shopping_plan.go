@@ -6,6 +6,8 @@ import "time"
 type ShoppingPlan struct {
 	BaseModel
 	PeriodStartAt           time.Time                `gorm:"type:date;not null" json:"period_start_at"`
+	Version                 int                      `gorm:"not null;default:1" json:"version"`   // 買い物リストが変わるたびに1つ進む版
+	ArchivedAt              *time.Time               `gorm:"type:datetime(6)" json:"archived_at"` // アーカイブした日時。アーカイブしていない場合は nil
 	PlanningMealItems       []PlanningMealItem       `gorm:"foreignKey:PlanID" json:"-"`
 	ShoppingIngredientItems []ShoppingIngredientItem `gorm:"foreignKey:PlanID" json:"-"`
 }
@@ -0,0 +1,57 @@
+package model
+
+import "gorm.io/gorm"
+
+// StoreLayout は、店舗の売り場の並びを表すモデルです。
+// 買い物リストを売り場ごとにまとめ、店内を歩く順に並べるために使います。
+type StoreLayout struct {
+	BaseModel
+	Name     string         `gorm:"type:varchar(255);not null;unique" json:"name"`
+	Sections []StoreSection `gorm:"foreignKey:LayoutID" json:"-"` // 歩く順（Position の昇順）に並んだ売り場
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (StoreLayout) TableName() string {
+	return "store_layouts"
+}
+
+// StoreSection は、店舗の売り場1つを表すモデルです。
+type StoreSection struct {
+	BaseModel
+	LayoutID string              `gorm:"type:char(36);not null;uniqueIndex:uq_store_section_position" json:"layout_id"`
+	Position int                 `gorm:"not null;uniqueIndex:uq_store_section_position" json:"position"` // 歩く順の番号（1始まり）
+	Name     string              `gorm:"type:varchar(255);not null" json:"name"`
+	Entries  []StoreSectionEntry `gorm:"foreignKey:SectionID" json:"-"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (StoreSection) TableName() string {
+	return "store_sections"
+}
+
+// BeforeCreate は、GORMのフックで、レコード作成前に呼び出されます。
+// 売り場の食材分類・食材は売り場の後に保存されるため、ここで店舗レイアウトIDを引き継ぎます。
+func (s *StoreSection) BeforeCreate(tx *gorm.DB) error {
+	for i := range s.Entries {
+		s.Entries[i].LayoutID = s.LayoutID
+	}
+	return s.BaseModel.BeforeCreate(tx)
+}
+
+// StoreSectionEntry は、売り場に置かれている食材分類または食材です。IngredientTypeID と IngredientID のどちらか一方を持ちます。
+// 食材の指定は分類の指定より優先されるため、「調味料」の中でも「ごま油」だけ別の売り場に割り当てるといったことができます。
+type StoreSectionEntry struct {
+	BaseModel
+	SectionID        string          `gorm:"type:char(36);not null;index" json:"section_id"`
+	LayoutID         string          `gorm:"type:char(36);not null;uniqueIndex:uq_store_entry_type;uniqueIndex:uq_store_entry_ingredient" json:"layout_id"` // 同じ店舗で複数の売り場に割り当てないための一意制約に使う
+	Position         int             `gorm:"not null" json:"position"`                                                                                      // 売り場の中での並び順（1始まり）
+	IngredientTypeID *string         `gorm:"type:char(36);default:null;uniqueIndex:uq_store_entry_type" json:"ingredient_type_id"`
+	IngredientID     *string         `gorm:"type:char(36);default:null;uniqueIndex:uq_store_entry_ingredient" json:"ingredient_id"`
+	IngredientType   *IngredientType `gorm:"foreignKey:IngredientTypeID" json:"-"`
+	Ingredient       *Ingredient     `gorm:"foreignKey:IngredientID" json:"-"`
+}
+
+// TableName は、GORMにテーブル名を明示的に指定します。
+func (StoreSectionEntry) TableName() string {
+	return "store_section_entries"
+}
@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+// ErrNotFound は、指定された行が存在しない場合のエラーです。
+var ErrNotFound = errors.New("record not found")
+
+// ErrVersionConflict は、更新しようとした行が、読み込んだ後に他の処理で更新されていた（版が一致しない）場合のエラーです。
+var ErrVersionConflict = errors.New("version conflict")
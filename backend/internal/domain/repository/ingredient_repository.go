@@ -10,6 +10,23 @@ import (
 type IngredientRepository interface {
 	// CreateIngredientTypes は、複数の食材分類を保存します。Seederでの利用を想定しています。
 	CreateIngredientTypes(ctx context.Context, ingredientTypes []*model.IngredientType) error
-	// CreateIngredients は、複数の食材を保存します。Seederでの利用を想定しています。
+	// CreateIngredients は、複数の食材を保存します。Seederやレシピの一括取り込みでの利用を想定しています。
 	CreateIngredients(ctx context.Context, ingredients []*model.Ingredient) error
-}
\ No newline at end of file
+	// SaveSubstitutions は、食材の代用ルールを保存します。同じ向きのルールが既にあれば換算比率を更新します。
+	SaveSubstitutions(ctx context.Context, substitutions []*model.IngredientSubstitution) error
+	// UpdateIngredientSeason は、食材の旬の月と旬の時期の価格の倍率を更新します。
+	UpdateIngredientSeason(ctx context.Context, ingredient *model.Ingredient) error
+	// CreateIngredientAliases は、複数の食材の別名を保存します。
+	CreateIngredientAliases(ctx context.Context, aliases []*model.IngredientAlias) error
+
+	// FindIngredientTypes は、登録済みの食材分類をすべて取得します。
+	FindIngredientTypes(ctx context.Context) ([]*model.IngredientType, error)
+	// FindIngredients は、登録済みの食材をすべて取得します。食材分類と別名もEager Loadingします。
+	FindIngredients(ctx context.Context) ([]*model.Ingredient, error)
+	// FindSubstitutions は、登録済みの代用ルールをすべて取得します。代用元・代用先の食材（代用先は食材分類も）をEager Loadingします。
+	FindSubstitutions(ctx context.Context) ([]*model.IngredientSubstitution, error)
+	// FindIngredientsByIDs は、IDに一致する食材をまとめて取得します。
+	FindIngredientsByIDs(ctx context.Context, ingredientIDs []string) ([]*model.Ingredient, error)
+	// FindIngredientByID は、IDに一致する食材を別名とともに取得します。
+	FindIngredientByID(ctx context.Context, ingredientID string) (*model.Ingredient, error)
+}
@@ -8,7 +8,35 @@ import (
 
 // MenuRepository は、メニューに関連する永続化を担当するリポジトリです。
 type MenuRepository interface {
-	// FindRandomMenus は、指定された件数分のメニューをランダムに取得します。
-	// 各メニューに必要な食材情報も合わせてEager Loadingすることを想定します。
+	// Transaction は、引数で受け取った関数をトランザクション内で実行します。
+	Transaction(ctx context.Context, fn func(txRepo MenuRepository) error) error
+	// IngredientRepository は、このリポジトリと同じ接続を使う食材のリポジトリを返します。
+	// Transaction の txRepo から取得すると、食材の保存も同じトランザクション内で行えます。
+	IngredientRepository() IngredientRepository
+
+	// FindRandomMenus は、指定された件数分のメニューをランダムに取得します。count が0以下の場合はすべてのメニューをランダムな順で取得します。
+	// 各メニューに必要な食材情報と最新の版も合わせてEager Loadingすることを想定します。
 	FindRandomMenus(ctx context.Context, count int) ([]*model.Menu, error)
-}
\ No newline at end of file
+	// FindAllMenus は、登録済みのメニューをすべて名前順に取得します。レシピと食材情報、調理手順、最新の版もEager Loadingします。
+	FindAllMenus(ctx context.Context) ([]*model.Menu, error)
+	// FindMenuByID は、指定されたIDのメニューを1件取得します。レシピと食材情報、調理手順、最新の版もEager Loadingします。
+	FindMenuByID(ctx context.Context, menuID string) (*model.Menu, error)
+	// FindMenusByIDs は、指定されたIDのメニューを名前順に取得します。レシピと食材情報もEager Loadingします。
+	FindMenusByIDs(ctx context.Context, menuIDs []string) ([]*model.Menu, error)
+	// FindMenuIDsByIngredientIDs は、指定された食材のいずれかをレシピに含むメニューのIDを取得します。
+	// 食材IDからメニューを引く転置インデックス（idx_ingredient_menu）だけで解決するため、メニュー数が増えても高速です。
+	FindMenuIDsByIngredientIDs(ctx context.Context, ingredientIDs []string) ([]string, error)
+
+	// CreateMenu は、新しいメニューを保存します。
+	CreateMenu(ctx context.Context, menu *model.Menu) error
+	// UpdateMenuDetails は、メニューの出来上がり量・コツ・出典を更新します。
+	UpdateMenuDetails(ctx context.Context, menu *model.Menu) error
+	// ReplaceMenuIngredientItems は、指定されたメニューのレシピを丸ごと置き換えます。
+	ReplaceMenuIngredientItems(ctx context.Context, menuID string, items []*model.MenuIngredientItem) error
+	// CreateMenuVersion は、メニューの版を材料・調理手順とともに保存し、メニューの最新の版として設定します。
+	CreateMenuVersion(ctx context.Context, version *model.MenuVersion) error
+	// FindMenuVersions は、指定されたメニューの版を新しい順にすべて取得します。材料と調理手順もEager Loadingします。
+	FindMenuVersions(ctx context.Context, menuID string) ([]*model.MenuVersion, error)
+	// ReplaceMenuSteps は、指定されたメニューの調理手順を丸ごと置き換えます。手順の番号は引数の順に振り直します。
+	ReplaceMenuSteps(ctx context.Context, menuID string, steps []*model.MenuStep) error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"meal-compass/backend/internal/domain/model"
 )
@@ -10,21 +11,84 @@ import (
 type PlanRepository interface {
 	// Transaction は、引数で受け取った関数をトランザクション内で実行します。
 	Transaction(ctx context.Context, fn func(txRepo PlanRepository) error) error
+	// MenuRepository は、このリポジトリと同じ接続を使うメニューのリポジトリを返します。
+	// Transaction の txRepo から取得すると、メニューの版の保存も同じトランザクション内で行えます。
+	MenuRepository() MenuRepository
 
 	// CreateShoppingPlan は、新しい買い物計画を保存します。
 	CreateShoppingPlan(ctx context.Context, plan *model.ShoppingPlan) error
-	// CreatePlanningMealItems は、複数の食事予定を保存します。
+	// CreatePlanningMealItems は、複数の食事予定を、食材の代用の記録とともに保存します。
 	CreatePlanningMealItems(ctx context.Context, meals []*model.PlanningMealItem) error
 	// CreateShoppingIngredientItems は、複数の買い物リストアイテムを保存します。
 	CreateShoppingIngredientItems(ctx context.Context, ingredients []*model.ShoppingIngredientItem) error
 
-	// FindMealsByPlanID は、指定された計画IDに紐づく食事予定のリストを取得します。メニュー情報もEager Loadingします。
+	// FindShoppingPlanByID は、指定されたIDの買い物計画を取得します。存在しない場合は ErrNotFound を返します。
+	FindShoppingPlanByID(ctx context.Context, planID string) (*model.ShoppingPlan, error)
+	// FindShoppingPlans は、条件に合う買い物計画を、開始日の新しい順（同じ日は作成日時の新しい順）に最大 query.Limit 件取得します。
+	FindShoppingPlans(ctx context.Context, query ShoppingPlanQuery) ([]*model.ShoppingPlan, error)
+	// SummarizeShoppingPlans は、指定された計画ごとに、食事と買い物リストのアイテムの件数を集計します。
+	// 食事もアイテムも無い計画は、件数が0の集計を返します。
+	SummarizeShoppingPlans(ctx context.Context, planIDs []string) (map[string]*ShoppingPlanSummary, error)
+	// FindMealsByPlanID は、指定された計画IDに紐づく食事予定のリストを取得します。メニュー情報と計画作成時の版、食材の代用もEager Loadingします。
 	FindMealsByPlanID(ctx context.Context, planID string) ([]*model.PlanningMealItem, error)
 	// FindShoppingIngredientsByPlanID は、指定された計画IDに紐づく買い物リストを取得します。食材情報もEager Loadingします。
 	FindShoppingIngredientsByPlanID(ctx context.Context, planID string) ([]*model.ShoppingIngredientItem, error)
 
 	// FindShoppingIngredientItemByID は、指定された買い物アイテムIDでアイテムを1件取得します。
 	FindShoppingIngredientItemByID(ctx context.Context, itemID string) (*model.ShoppingIngredientItem, error)
-	// UpdateShoppingIngredientItem は、買い物リストのアイテム情報（主に'bought'フラグ）を更新します。
+	// UpdateShoppingIngredientItem は、買い物リストのアイテムの必要量、購入状況（購入済み、購入した量、購入日時）、メモを更新し、版を1つ進めます。
+	// 読み込んだときの版（item.Version）から変わっている場合は ErrVersionConflict、存在しない場合は ErrNotFound を返します。
 	UpdateShoppingIngredientItem(ctx context.Context, item *model.ShoppingIngredientItem) error
-}
\ No newline at end of file
+	// UpdateShoppingIngredientItemStates は、複数の買い物リストのアイテムの必要量と購入状況を更新し、それぞれの版を1つ進めます。
+	// 読み込んだときの版から変わっているアイテムがある場合は ErrVersionConflict を返します。
+	UpdateShoppingIngredientItemStates(ctx context.Context, items []*model.ShoppingIngredientItem) error
+	// IncrementShoppingPlanVersion は、買い物計画の版を1つ進め、進めた後の版を返します。
+	// expectedVersion が0でない場合は、現在の版が一致する場合だけ進め、一致しなければ ErrVersionConflict を返します。
+	IncrementShoppingPlanVersion(ctx context.Context, planID string, expectedVersion int) (int, error)
+	// SetShoppingPlansArchivedAt は、指定された計画のアーカイブした日時を設定します（nil の場合はアーカイブを解除します）。更新した件数を返します。
+	SetShoppingPlansArchivedAt(ctx context.Context, planIDs []string, archivedAt *time.Time) (int64, error)
+	// DeleteShoppingPlan は、買い物計画を、献立、買い物リスト、レシート、共有リンクとともに削除します。
+	// expectedVersion が0でない場合は、現在の版が一致する場合だけ削除し、一致しなければ ErrVersionConflict を返します。
+	// 存在しない場合は ErrNotFound を返します。
+	DeleteShoppingPlan(ctx context.Context, planID string, expectedVersion int) error
+	// DeleteShoppingPlans は、指定された買い物計画を DeleteShoppingPlan と同じくまとめて削除し、削除した件数を返します。
+	DeleteShoppingPlans(ctx context.Context, planIDs []string) (int64, error)
+	// DeleteShoppingIngredientItem は、買い物リストのアイテムを削除します。存在しない場合は ErrNotFound を返します。
+	DeleteShoppingIngredientItem(ctx context.Context, itemID string) error
+
+	// CreateReceipt は、レシートをその行とともに保存します。
+	CreateReceipt(ctx context.Context, receipt *model.Receipt) error
+	// FindReceiptsByPlanID は、計画のレシートを買い物をした日時の順に取得します。行（順番どおり）と、行の食材・食材分類もEager Loadingします。
+	FindReceiptsByPlanID(ctx context.Context, planID string) ([]*model.Receipt, error)
+	// FindReceiptsPurchasedBetween は、買い物をした日時が from 以上 to 未満のすべての計画のレシートを、日時の順に取得します。
+	// 行と、行の食材・食材分類もEager Loadingします。
+	FindReceiptsPurchasedBetween(ctx context.Context, from, to time.Time) ([]*model.Receipt, error)
+	// DeleteReceipt は、計画のレシートをその行とともに削除します。存在しない場合は ErrNotFound を返します。
+	DeleteReceipt(ctx context.Context, planID, receiptID string) error
+}
+
+// ShoppingPlanQuery は、買い物計画の一覧を取得する条件です。
+type ShoppingPlanQuery struct {
+	StartFrom *time.Time // 開始日がこの日以降の計画に絞り込みます
+	StartTo   *time.Time // 開始日がこの日以前の計画に絞り込みます
+	Archived  *bool      // true の場合はアーカイブした計画、false の場合はアーカイブしていない計画に絞り込みます（nil の場合は両方）
+	After     *ShoppingPlanCursor
+	Limit     int
+}
+
+// ShoppingPlanCursor は、買い物計画の一覧の続きを取得するための位置です。この計画より後（古い方）の計画を取得します。
+type ShoppingPlanCursor struct {
+	PeriodStartAt time.Time
+	CreatedAt     time.Time
+	ID            string
+}
+
+// ShoppingPlanSummary は、買い物計画の食事と買い物リストのアイテムの件数の集計です。
+type ShoppingPlanSummary struct {
+	PlanID        string
+	MealCount     int
+	FirstMealDate *time.Time // 最初の食事の日付。食事が無い場合は nil
+	LastMealDate  *time.Time // 最後の食事の日付。食事が無い場合は nil
+	ItemCount     int
+	BoughtCount   int
+}
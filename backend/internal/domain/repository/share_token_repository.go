@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// ShareTokenRepository は、買い物計画の共有リンクのトークンに関連する永続化を担当するリポジトリです。
+type ShareTokenRepository interface {
+	// CreateShareToken は、共有トークンを保存します。
+	CreateShareToken(ctx context.Context, token *model.ShareToken) error
+	// RevokeShareToken は、計画の共有トークンを無効にします。該当するトークンがない場合は ErrNotFound を返します。
+	// 無効にしたトークンをもう一度無効にしても、無効にした日時は変わりません。
+	RevokeShareToken(ctx context.Context, planID, tokenID string, revokedAt time.Time) error
+
+	// FindShareTokensByPlanID は、計画の共有トークンを作成日時の新しい順に取得します。
+	FindShareTokensByPlanID(ctx context.Context, planID string) ([]*model.ShareToken, error)
+	// FindShareTokenByHash は、トークンのハッシュから共有トークンを1件取得します。存在しない場合は ErrNotFound を返します。
+	FindShareTokenByHash(ctx context.Context, tokenHash string) (*model.ShareToken, error)
+}
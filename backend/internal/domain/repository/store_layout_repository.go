@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// StoreLayoutRepository は、店舗の売り場の並びに関連する永続化を担当するリポジトリです。
+type StoreLayoutRepository interface {
+	// CreateStoreLayout は、店舗レイアウトを売り場と売り場に置かれている食材分類・食材とともに保存します。
+	CreateStoreLayout(ctx context.Context, layout *model.StoreLayout) error
+	// ReplaceStoreLayout は、店舗レイアウトの名前を更新し、売り場を丸ごと置き換えます。
+	ReplaceStoreLayout(ctx context.Context, layout *model.StoreLayout) error
+	// DeleteStoreLayout は、店舗レイアウトを削除します。該当するレイアウトがない場合は ErrNotFound を返します。
+	DeleteStoreLayout(ctx context.Context, layoutID string) error
+
+	// FindStoreLayouts は、登録済みの店舗レイアウトをすべて名前順に取得します。売り場と食材分類・食材もEager Loadingします。
+	FindStoreLayouts(ctx context.Context) ([]*model.StoreLayout, error)
+	// FindStoreLayoutByID は、指定されたIDの店舗レイアウトを1件取得します。売り場と食材分類・食材もEager Loadingします。
+	FindStoreLayoutByID(ctx context.Context, layoutID string) (*model.StoreLayout, error)
+}
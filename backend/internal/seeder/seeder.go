@@ -1,386 +1,527 @@
 package seeder
 
 import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"meal-compass/backend/internal/adapter/recipeio"
 	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/usecase"
 )
 
-// IngredientsSeeder は、食材分類と食材のマスターデータをデータベースに投入します。
-func IngredientsSeeder(db *gorm.DB) error {
-	log.Println("Seeding ingredient types...")
-	if err := createIngredientTypes(db); err != nil {
-		return err
+// catalogYAML は、食材分類・食材・メニュー（レシピ）の初期データです。
+// 形式は `server export` の出力と同じです。
+//
+//go:embed data/catalog.yaml
+var catalogYAML []byte
+
+// Action は、シードの適用で発生する変更の種類です。
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Change は、シードの適用で発生する変更1件を表します。
+type Change struct {
+	Action Action
+	Kind   string // "ingredient_type", "ingredient", "alias", "substitution", "menu", "recipe", "steps", "version"
+	Name   string
+	Detail string
+}
+
+func (c *Change) String() string {
+	mark := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}[c.Action]
+	if c.Detail == "" {
+		return fmt.Sprintf("%s %s %s", mark, c.Kind, c.Name)
 	}
+	return fmt.Sprintf("%s %s %s: %s", mark, c.Kind, c.Name, c.Detail)
+}
 
-	log.Println("Seeding ingredients...")
-	if err := createIngredients(db); err != nil {
-		return err
+// Run は、初期データをデータベースに反映します。何度実行しても結果は変わりません。
+//
+// データは名前をキーにして突き合わせ、存在しないものは作成、値が変わったもの（食材の基本量やレシピの分量など）は更新します。
+// シードに含まれるメニューのレシピから外れた材料は削除しますが、シードに含まれない食材やメニュー、利用者が追加した別名には触れません。
+// dryRun が true の場合は何も保存せず、発生する変更の一覧だけを返します。
+func Run(ctx context.Context, db *gorm.DB, dryRun bool) ([]*Change, error) {
+	catalog, err := recipeio.Decode(bytes.NewReader(catalogYAML), recipeio.FormatYAML)
+	if err != nil {
+		return nil, fmt.Errorf("シードデータの読み込みに失敗しました: %w", err)
 	}
 
-	return nil
+	s := &seeder{dryRun: dryRun}
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		s.db = tx
+		log.Println("Seeding ingredient types...")
+		if err := s.seedIngredientTypes(catalog.Ingredients); err != nil {
+			return err
+		}
+		log.Println("Seeding ingredients...")
+		if err := s.seedIngredients(catalog.Ingredients); err != nil {
+			return err
+		}
+		log.Println("Seeding ingredient aliases...")
+		if err := s.seedAliases(catalog.Ingredients); err != nil {
+			return err
+		}
+		log.Println("Seeding ingredient substitutions...")
+		if err := s.seedSubstitutions(catalog.Substitutions); err != nil {
+			return err
+		}
+		log.Println("Seeding menus and recipes...")
+		return s.seedMenus(catalog.Recipes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.changes, nil
 }
 
-// MenusSeeder は、メニューと、それに必要な食材（レシピ）のマスターデータをデータベースに投入します。
-// 必ず IngredientsSeeder の後に実行する必要があります。
-func MenusSeeder(db *gorm.DB) error {
-	log.Println("Seeding menus and recipes...")
-	return createMenusWithRecipes(db)
+// --- Private Functions ---
+
+type seeder struct {
+	db      *gorm.DB
+	dryRun  bool
+	changes []*Change
+
+	types       map[string]*model.IngredientType
+	ingredients map[string]*model.Ingredient
 }
 
-// --- Private Functions ---
+func (s *seeder) record(action Action, kind, name, detail string) {
+	s.changes = append(s.changes, &Change{Action: action, Kind: kind, Name: name, Detail: detail})
+}
 
-func createIngredientTypes(db *gorm.DB) error {
-	types := []model.IngredientType{
-		{Name: "生鮮食品"},   // 肉類、魚介類
-		{Name: "野菜/果物"}, // 野菜、果物
-		{Name: "乾物類"},     // 穀物、麺類、粉物
-		{Name: "パン類"},
-		{Name: "乳製品・卵"},
-		{Name: "調味料"},
-		{Name: "その他"},
+// create は、dryRun でなければレコードを作成します。
+func (s *seeder) create(value any) error {
+	if s.dryRun {
+		return nil
 	}
+	return s.db.Omit(clause.Associations).Create(value).Error
+}
 
+func (s *seeder) seedIngredientTypes(defs []*usecase.CatalogIngredientRecord) error {
+	var types []*model.IngredientType
+	if err := s.db.Find(&types).Error; err != nil {
+		return err
+	}
+	s.types = make(map[string]*model.IngredientType, len(types))
 	for _, t := range types {
-		// Nameをキーに、存在しなければ作成
-		if err := db.FirstOrCreate(&t, model.IngredientType{Name: t.Name}).Error; err != nil {
+		s.types[t.Name] = t
+	}
+
+	// 食材分類は、食材定義で使われている名前から作成する
+	for _, def := range defs {
+		if _, ok := s.types[def.TypeName]; ok {
+			continue
+		}
+		t := &model.IngredientType{Name: def.TypeName}
+		if err := s.create(t); err != nil {
 			return err
 		}
+		s.types[t.Name] = t
+		s.record(ActionCreate, "ingredient_type", t.Name, "")
 	}
 	return nil
 }
 
-func createIngredients(db *gorm.DB) error {
-	// 型名をキーとして、登録済みのIngredientTypeのIDをマップに保持
-	typeMap := make(map[string]string)
-	var types []model.IngredientType
-	if err := db.Find(&types).Error; err != nil {
+func (s *seeder) seedIngredients(defs []*usecase.CatalogIngredientRecord) error {
+	var ingredients []*model.Ingredient
+	if err := s.db.Preload("IngredientType").Preload("Aliases").Find(&ingredients).Error; err != nil {
 		return err
 	}
-	for _, t := range types {
-		typeMap[t.Name] = t.ID
-	}
-
-	ingredients := []struct {
-		TypeName string
-		model.Ingredient
-	}{
-		// --- 生鮮食品 ---
-		{"生鮮食品", model.Ingredient{Name: "豚バラ肉", BaseAmount: 200, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "豚ロース肉", BaseAmount: 200, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "鶏もも肉", BaseAmount: 250, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "鶏むね肉", BaseAmount: 250, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "鶏ひき肉", BaseAmount: 200, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "合いびき肉", BaseAmount: 200, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "牛肉", BaseAmount: 200, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "ベーコン", BaseAmount: 80, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "鮭", BaseAmount: 1, Unit: "切れ"}},
-		{"生鮮食品", model.Ingredient{Name: "エビ", BaseAmount: 100, Unit: "g"}},
-		{"生鮮食品", model.Ingredient{Name: "アジ", BaseAmount: 1, Unit: "尾"}},
-		{"生鮮食品", model.Ingredient{Name: "サバ", BaseAmount: 1, Unit: "切れ"}},
-		// --- 野菜/果物 ---
-		{"野菜/果物", model.Ingredient{Name: "玉ねぎ", BaseAmount: 3, Unit: "個"}},
-		{"野菜/果物", model.Ingredient{Name: "じゃがいも", BaseAmount: 3, Unit: "個"}},
-		{"野菜/果物", model.Ingredient{Name: "人参", BaseAmount: 2, Unit: "本"}},
-		{"野菜/果物", model.Ingredient{Name: "キャベツ", BaseAmount: 1, Unit: "玉"}},
-		{"野菜/果物", model.Ingredient{Name: "ピーマン", BaseAmount: 4, Unit: "個"}},
-		{"野菜/果物", model.Ingredient{Name: "なす", BaseAmount: 3, Unit: "本"}},
-		{"野菜/果物", model.Ingredient{Name: "トマト", BaseAmount: 3, Unit: "個"}},
-		{"野菜/果物", model.Ingredient{Name: "きゅうり", BaseAmount: 3, Unit: "本"}},
-		{"野菜/果物", model.Ingredient{Name: "レタス", BaseAmount: 1, Unit: "玉"}},
-		{"野菜/果物", model.Ingredient{Name: "大根", BaseAmount: 1, Unit: "本"}},
-		{"野菜/果物", model.Ingredient{Name: "長ねぎ", BaseAmount: 1, Unit: "本"}},
-		{"野菜/果物", model.Ingredient{Name: "にんにく", BaseAmount: 1, Unit: "玉"}},
-		{"野菜/果物", model.Ingredient{Name: "生姜", BaseAmount: 1, Unit: "個"}},
-		{"野菜/果物", model.Ingredient{Name: "しめじ", BaseAmount: 1, Unit: "パック"}},
-		// --- 乾物類 ---
-		{"乾物類", model.Ingredient{Name: "米", BaseAmount: 5000, Unit: "g"}},
-		{"乾物類", model.Ingredient{Name: "パスタ", BaseAmount: 500, Unit: "g"}},
-		{"乾物類", model.Ingredient{Name: "うどん", BaseAmount: 3, Unit: "玉"}},
-		{"乾物類", model.Ingredient{Name: "小麦粉", BaseAmount: 500, Unit: "g"}},
-		{"乾物類", model.Ingredient{Name: "片栗粉", BaseAmount: 200, Unit: "g"}},
-		{"乾物類", model.Ingredient{Name: "パン粉", BaseAmount: 100, Unit: "g"}},
-		// --- パン類 ---
-		{"パン類", model.Ingredient{Name: "食パン", BaseAmount: 6, Unit: "枚"}},
-		// --- 乳製品・卵 ---
-		{"乳製品・卵", model.Ingredient{Name: "卵", BaseAmount: 10, Unit: "個"}},
-		{"乳製品・卵", model.Ingredient{Name: "牛乳", BaseAmount: 1000, Unit: "ml"}},
-		{"乳製品・卵", model.Ingredient{Name: "バター", BaseAmount: 150, Unit: "g"}},
-		{"乳製品・卵", model.Ingredient{Name: "チーズ", BaseAmount: 100, Unit: "g"}},
-		// --- 調味料 ---
-		{"調味料", model.Ingredient{Name: "醤油", BaseAmount: 1000, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "みりん", BaseAmount: 500, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "酒", BaseAmount: 500, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "酢", BaseAmount: 500, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "味噌", BaseAmount: 750, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "砂糖", BaseAmount: 1000, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "塩", BaseAmount: 200, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "こしょう", BaseAmount: 50, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "サラダ油", BaseAmount: 1000, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "ごま油", BaseAmount: 200, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "オリーブオイル", BaseAmount: 500, Unit: "ml"}},
-		{"調味料", model.Ingredient{Name: "マヨネーズ", BaseAmount: 500, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "ケチャップ", BaseAmount: 500, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "コンソメ", BaseAmount: 50, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "鶏がらスープの素", BaseAmount: 50, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "豆板醤", BaseAmount: 50, Unit: "g"}},
-		{"調味料", model.Ingredient{Name: "オイスターソース", BaseAmount: 120, Unit: "g"}},
-		// --- その他 ---
-		{"その他", model.Ingredient{Name: "カレールー", BaseAmount: 1, Unit: "箱"}},
-		{"その他", model.Ingredient{Name: "豆腐", BaseAmount: 1, Unit: "丁"}},
-		{"その他", model.Ingredient{Name: "キムチ", BaseAmount: 200, Unit: "g"}},
-	}
-
-	for _, i := range ingredients {
-		ing := i.Ingredient
-		ing.TypeID = typeMap[i.TypeName]
-		if err := db.Where(model.Ingredient{Name: ing.Name}).FirstOrCreate(&ing).Error; err != nil {
+	s.ingredients = make(map[string]*model.Ingredient, len(ingredients))
+	for _, ing := range ingredients {
+		s.ingredients[ing.Name] = ing
+	}
+
+	for _, def := range defs {
+		t := s.types[def.TypeName]
+		seasonMonths, err := model.NewSeasonMonths(def.SeasonMonths)
+		if err != nil {
+			return fmt.Errorf("食材「%s」の旬の月が不正です: %w", def.Name, err)
+		}
+		existing, ok := s.ingredients[def.Name]
+		if !ok {
+			ing := &model.Ingredient{
+				TypeID:                  t.ID,
+				Name:                    def.Name,
+				BaseAmount:              def.BaseAmount,
+				Unit:                    def.Unit,
+				ShelfLifeDaysUnopened:   def.ShelfLifeDaysUnopened,
+				ShelfLifeDaysOpened:     def.ShelfLifeDaysOpened,
+				SeasonMonths:            seasonMonths,
+				SeasonalPriceMultiplier: def.SeasonalPriceMultiplier,
+			}
+			if err := s.create(ing); err != nil {
+				return err
+			}
+			s.ingredients[ing.Name] = ing
+			s.record(ActionCreate, "ingredient", ing.Name, "")
+			continue
+		}
+
+		// 値が変わったカラムだけを更新する
+		updates := make(map[string]any)
+		var details []string
+		diff := func(column string, before, after any, changed bool) {
+			if changed {
+				updates[column] = after
+				details = append(details, fmt.Sprintf("%s %s → %s", column, formatValue(before), formatValue(after)))
+			}
+		}
+		diff("type_id", existing.IngredientType.Name, def.TypeName, existing.IngredientType.Name != def.TypeName)
+		diff("base_amount", existing.BaseAmount, def.BaseAmount, existing.BaseAmount != def.BaseAmount)
+		diff("unit", existing.Unit, def.Unit, existing.Unit != def.Unit)
+		diff("shelf_life_days_unopened", existing.ShelfLifeDaysUnopened, def.ShelfLifeDaysUnopened, !equalIntPtr(existing.ShelfLifeDaysUnopened, def.ShelfLifeDaysUnopened))
+		diff("shelf_life_days_opened", existing.ShelfLifeDaysOpened, def.ShelfLifeDaysOpened, !equalIntPtr(existing.ShelfLifeDaysOpened, def.ShelfLifeDaysOpened))
+		diff("season_months", existing.SeasonMonths.Months(), seasonMonths.Months(), existing.SeasonMonths != seasonMonths)
+		diff("seasonal_price_multiplier", existing.SeasonalPriceMultiplier, def.SeasonalPriceMultiplier, !equalFloatPtr(existing.SeasonalPriceMultiplier, def.SeasonalPriceMultiplier))
+		if len(updates) == 0 {
+			continue
+		}
+		if _, ok := updates["type_id"]; ok {
+			updates["type_id"] = t.ID
+		}
+		if _, ok := updates["season_months"]; ok {
+			updates["season_months"] = seasonMonths
+		}
+		if !s.dryRun {
+			if err := s.db.Model(existing).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		s.record(ActionUpdate, "ingredient", def.Name, strings.Join(details, ", "))
+	}
+	return nil
+}
+
+// seedAliases は、シードの別名のうち未登録のものを作成します。
+// 正規化すると食材名や登録済みの別名と同じになるものは作成しません。
+func (s *seeder) seedAliases(defs []*usecase.CatalogIngredientRecord) error {
+	owners := make(map[string]*model.Ingredient)
+	for _, ing := range s.ingredients {
+		owners[model.NormalizeIngredientName(ing.Name)] = ing
+		for _, alias := range ing.Aliases {
+			owners[alias.NormalizedName] = ing
+		}
+	}
+
+	for _, def := range defs {
+		ing := s.ingredients[def.Name]
+		for _, alias := range def.Aliases {
+			key := model.NormalizeIngredientName(alias)
+			if owner, ok := owners[key]; ok {
+				if owner != ing {
+					return fmt.Errorf("食材「%s」の別名「%s」は食材「%s」と重複しています", def.Name, alias, owner.Name)
+				}
+				continue
+			}
+			if err := s.create(&model.IngredientAlias{IngredientID: ing.ID, Alias: alias}); err != nil {
+				return err
+			}
+			owners[key] = ing
+			s.record(ActionCreate, "alias", def.Name+" / "+alias, "")
+		}
+	}
+	return nil
+}
+
+// seedSubstitutions は、シードの代用ルールを作成し、比率が変わったものを更新します。
+// シードに含まれない代用ルールには触れません。
+func (s *seeder) seedSubstitutions(rules []*usecase.SubstitutionRecord) error {
+	var substitutions []*model.IngredientSubstitution
+	if err := s.db.Find(&substitutions).Error; err != nil {
+		return err
+	}
+	existing := make(map[[2]string]*model.IngredientSubstitution, len(substitutions))
+	for _, sub := range substitutions {
+		existing[[2]string{sub.FromIngredientID, sub.ToIngredientID}] = sub
+	}
+
+	sync := func(from, to *model.Ingredient, ratio float64) error {
+		name := from.Name + " → " + to.Name
+		sub, ok := existing[[2]string{from.ID, to.ID}]
+		if !ok {
+			if err := s.create(&model.IngredientSubstitution{FromIngredientID: from.ID, ToIngredientID: to.ID, Ratio: ratio}); err != nil {
+				return err
+			}
+			s.record(ActionCreate, "substitution", name, "ratio "+formatValue(ratio))
+			return nil
+		}
+		if sub.Ratio == ratio {
+			return nil
+		}
+		if !s.dryRun {
+			if err := s.db.Model(sub).Update("ratio", ratio).Error; err != nil {
+				return err
+			}
+		}
+		s.record(ActionUpdate, "substitution", name, fmt.Sprintf("ratio %s → %s", formatValue(sub.Ratio), formatValue(ratio)))
+		return nil
+	}
+
+	for _, rule := range rules {
+		from, ok := s.ingredients[rule.From]
+		if !ok {
+			return fmt.Errorf("代用ルールの食材「%s」がシードデータに定義されていません", rule.From)
+		}
+		to, ok := s.ingredients[rule.To]
+		if !ok {
+			return fmt.Errorf("代用ルールの食材「%s」がシードデータに定義されていません", rule.To)
+		}
+		if err := sync(from, to, rule.Ratio); err != nil {
 			return err
 		}
+		if rule.Bidirectional {
+			if err := sync(to, from, roundRatio(1/rule.Ratio)); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
-func createMenusWithRecipes(db *gorm.DB) error {
-	// レシピ情報を定義
-	recipes := []struct {
-		MenuName    string
-		Ingredients []struct {
-			Name   string
-			Amount float64
-		}
-	}{
-		// --- 定番料理 ---
-		{
-			MenuName: "豚の生姜焼き",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚ロース肉", Amount: 150}, {Name: "玉ねぎ", Amount: 0.5}, {Name: "生姜", Amount: 15}, {Name: "醤油", Amount: 30}, {Name: "みりん", Amount: 30}, {Name: "酒", Amount: 15}, {Name: "サラダ油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "カレーライス",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚バラ肉", Amount: 100}, {Name: "じゃがいも", Amount: 1}, {Name: "人参", Amount: 0.5}, {Name: "玉ねぎ", Amount: 0.5}, {Name: "カレールー", Amount: 0.5}, {Name: "米", Amount: 150}, {Name: "サラダ油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "親子丼",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "鶏もも肉", Amount: 100}, {Name: "玉ねぎ", Amount: 0.25}, {Name: "卵", Amount: 2}, {Name: "醤油", Amount: 20}, {Name: "みりん", Amount: 20}, {Name: "米", Amount: 150},
-			},
-		},
-		{
-			MenuName: "肉じゃが",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "牛肉", Amount: 100}, {Name: "じゃがいも", Amount: 2}, {Name: "人参", Amount: 0.5}, {Name: "玉ねぎ", Amount: 1}, {Name: "醤油", Amount: 45}, {Name: "砂糖", Amount: 20}, {Name: "みりん", Amount: 30},
-			},
-		},
-		{
-			MenuName: "鶏の唐揚げ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "鶏もも肉", Amount: 250}, {Name: "醤油", Amount: 30}, {Name: "酒", Amount: 15}, {Name: "にんにく", Amount: 10}, {Name: "生姜", Amount: 10}, {Name: "片栗粉", Amount: 30}, {Name: "サラダ油", Amount: 100},
-			},
-		},
-		{
-			MenuName: "ハンバーグ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "合いびき肉", Amount: 200}, {Name: "玉ねぎ", Amount: 0.5}, {Name: "卵", Amount: 1}, {Name: "パン粉", Amount: 20}, {Name: "牛乳", Amount: 30}, {Name: "塩", Amount: 2}, {Name: "こしょう", Amount: 0.5}, {Name: "サラダ油", Amount: 15}, {Name: "ケチャップ", Amount: 30},
-			},
-		},
-		{
-			MenuName: "とんかつ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚ロース肉", Amount: 150}, {Name: "小麦粉", Amount: 20}, {Name: "卵", Amount: 1}, {Name: "パン粉", Amount: 30}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5}, {Name: "サラダ油", Amount: 150},
-			},
-		},
-		{
-			MenuName: "牛丼",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "牛肉", Amount: 150}, {Name: "玉ねぎ", Amount: 0.5}, {Name: "醤油", Amount: 30}, {Name: "みりん", Amount: 30}, {Name: "砂糖", Amount: 10}, {Name: "酒", Amount: 15}, {Name: "米", Amount: 150},
-			},
-		},
-		{
-			MenuName: "豚汁",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚バラ肉", Amount: 80}, {Name: "大根", Amount: 50}, {Name: "人参", Amount: 30}, {Name: "長ねぎ", Amount: 0.25}, {Name: "豆腐", Amount: 0.25}, {Name: "味噌", Amount: 30}, {Name: "ごま油", Amount: 5},
-			},
-		},
-		// --- 中華 ---
-		{
-			MenuName: "麻婆豆腐",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豆腐", Amount: 1}, {Name: "鶏ひき肉", Amount: 100}, {Name: "長ねぎ", Amount: 0.5}, {Name: "にんにく", Amount: 10}, {Name: "生姜", Amount: 10}, {Name: "豆板醤", Amount: 10}, {Name: "醤油", Amount: 15}, {Name: "鶏がらスープの素", Amount: 5}, {Name: "片栗粉", Amount: 10}, {Name: "ごま油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "回鍋肉",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚バラ肉", Amount: 150}, {Name: "キャベツ", Amount: 150}, {Name: "ピーマン", Amount: 1}, {Name: "味噌", Amount: 20}, {Name: "砂糖", Amount: 10}, {Name: "醤油", Amount: 10}, {Name: "豆板醤", Amount: 5}, {Name: "ごま油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "青椒肉絲",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "牛肉", Amount: 150}, {Name: "ピーマン", Amount: 2}, {Name: "醤油", Amount: 20}, {Name: "酒", Amount: 10}, {Name: "片栗粉", Amount: 10}, {Name: "オイスターソース", Amount: 15}, {Name: "ごま油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "エビチリ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "エビ", Amount: 150}, {Name: "長ねぎ", Amount: 0.5}, {Name: "生姜", Amount: 10}, {Name: "にんにく", Amount: 10}, {Name: "ケチャップ", Amount: 45}, {Name: "豆板醤", Amount: 10}, {Name: "鶏がらスープの素", Amount: 5}, {Name: "片栗粉", Amount: 10},
-			},
-		},
-		{
-			MenuName: "チャーハン",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "米", Amount: 180}, {Name: "卵", Amount: 1}, {Name: "長ねぎ", Amount: 0.25}, {Name: "ベーコン", Amount: 20}, {Name: "醤油", Amount: 10}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5}, {Name: "ごま油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "豚キムチ炒め",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豚バラ肉", Amount: 150}, {Name: "キムチ", Amount: 100}, {Name: "玉ねぎ", Amount: 0.25}, {Name: "醤油", Amount: 5}, {Name: "ごま油", Amount: 10},
-			},
-		},
-		// --- 洋食・パスタ ---
-		{
-			MenuName: "ミートソースパスタ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "パスタ", Amount: 100}, {Name: "合いびき肉", Amount: 100}, {Name: "玉ねぎ", Amount: 0.25}, {Name: "人参", Amount: 0.25}, {Name: "にんにく", Amount: 10}, {Name: "トマト", Amount: 1}, {Name: "ケチャップ", Amount: 30}, {Name: "コンソメ", Amount: 5}, {Name: "オリーブオイル", Amount: 10},
-			},
-		},
-		{
-			MenuName: "カルボナーラ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "パスタ", Amount: 100}, {Name: "ベーコン", Amount: 50}, {Name: "卵", Amount: 2}, {Name: "牛乳", Amount: 50}, {Name: "チーズ", Amount: 30}, {Name: "にんにく", Amount: 10}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5}, {Name: "オリーブオイル", Amount: 10},
-			},
-		},
-		{
-			MenuName: "オムライス",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "米", Amount: 150}, {Name: "鶏もも肉", Amount: 50}, {Name: "玉ねぎ", Amount: 0.25}, {Name: "ケチャップ", Amount: 45}, {Name: "卵", Amount: 2}, {Name: "牛乳", Amount: 15}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5}, {Name: "サラダ油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "チキングラタン",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "鶏もも肉", Amount: 100}, {Name: "玉ねぎ", Amount: 0.25}, {Name: "しめじ", Amount: 0.5}, {Name: "小麦粉", Amount: 20}, {Name: "牛乳", Amount: 200}, {Name: "バター", Amount: 20}, {Name: "チーズ", Amount: 30}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5},
-			},
-		},
-		{
-			MenuName: "焼きうどん",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "うどん", Amount: 1}, {Name: "豚バラ肉", Amount: 50}, {Name: "キャベツ", Amount: 100}, {Name: "人参", Amount: 20}, {Name: "ピーマン", Amount: 0.5}, {Name: "醤油", Amount: 15}, {Name: "みりん", Amount: 10}, {Name: "サラダ油", Amount: 10},
-			},
-		},
-		// --- 魚料理 ---
-		{
-			MenuName: "鮭の塩焼き",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "鮭", Amount: 1}, {Name: "塩", Amount: 2},
-			},
-		},
-		{
-			MenuName: "サバの味噌煮",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "サバ", Amount: 1}, {Name: "生姜", Amount: 10}, {Name: "味噌", Amount: 30}, {Name: "砂糖", Amount: 20}, {Name: "酒", Amount: 30}, {Name: "みりん", Amount: 15},
-			},
-		},
-		{
-			MenuName: "アジフライ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "アジ", Amount: 1}, {Name: "小麦粉", Amount: 15}, {Name: "卵", Amount: 0.5}, {Name: "パン粉", Amount: 20}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5}, {Name: "サラダ油", Amount: 100},
-			},
-		},
-		// --- 簡単な一品 ---
-		{
-			MenuName: "バタートースト",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "食パン", Amount: 1}, {Name: "バター", Amount: 10},
-			},
-		},
-		{
-			MenuName: "目玉焼き",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "卵", Amount: 1}, {Name: "サラダ油", Amount: 5}, {Name: "塩", Amount: 0.5}, {Name: "こしょう", Amount: 0.2},
-			},
-		},
-		{
-			MenuName: "冷奴",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "豆腐", Amount: 0.5}, {Name: "長ねぎ", Amount: 0.1}, {Name: "生姜", Amount: 5}, {Name: "醤油", Amount: 10},
-			},
-		},
-		{
-			MenuName: "きゅうりの塩昆布和え",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "きゅうり", Amount: 1}, {Name: "ごま油", Amount: 5},
-			},
-		},
-		{
-			MenuName: "トマトサラダ",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "トマト", Amount: 1}, {Name: "玉ねぎ", Amount: 0.1}, {Name: "酢", Amount: 15}, {Name: "オリーブオイル", Amount: 10}, {Name: "塩", Amount: 1}, {Name: "こしょう", Amount: 0.5},
-			},
-		},
-		{
-			MenuName: "鶏むね肉のレンジ蒸し",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "鶏むね肉", Amount: 250}, {Name: "酒", Amount: 15}, {Name: "塩", Amount: 2}, {Name: "こしょう", Amount: 0.5},
-			},
-		},
-		{
-			MenuName: "無限ピーマン",
-			Ingredients: []struct { Name string; Amount float64 }{
-				{Name: "ピーマン", Amount: 3}, {Name: "ベーコン", Amount: 20}, {Name: "鶏がらスープの素", Amount: 3}, {Name: "ごま油", Amount: 5},
-			},
-		},
-	}
-
-	// レシピ情報を元にDBに登録
+func (s *seeder) seedMenus(recipes []*usecase.RecipeRecord) error {
 	for _, r := range recipes {
-		menu := model.Menu{Name: r.MenuName}
-		if err := db.Where(model.Menu{Name: menu.Name}).FirstOrCreate(&menu).Error; err != nil {
+		changesBefore := len(s.changes)
+		var menu model.Menu
+		err := s.db.
+			Preload("MenuIngredientItems.Ingredient").
+			Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+			Preload("CurrentVersion").
+			Where("name = ?", r.MenuName).
+			First(&menu).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			menu = model.Menu{Name: r.MenuName, Servings: r.Servings, Tips: optionalString(r.Tips), SourceURL: optionalString(r.SourceURL)}
+			if err := s.create(&menu); err != nil {
+				return err
+			}
+			s.record(ActionCreate, "menu", menu.Name, "")
+		} else if err != nil {
+			return err
+		} else if err := s.syncMenuDetails(&menu, r); err != nil {
 			return err
 		}
 
-		for _, ingInfo := range r.Ingredients {
-			var ingredient model.Ingredient
-			if err := db.First(&ingredient, "name = ?", ingInfo.Name).Error; err != nil {
-				log.Printf("Ingredient not found: %s. Skipping for menu: %s. Error: %v", ingInfo.Name, r.MenuName, err)
-				continue
+		if err := s.syncSteps(&menu, r.Steps); err != nil {
+			return err
+		}
+
+		existingItems := make(map[string]*model.MenuIngredientItem, len(menu.MenuIngredientItems))
+		for i := range menu.MenuIngredientItems {
+			item := &menu.MenuIngredientItems[i]
+			existingItems[item.Ingredient.Name] = item
+		}
+
+		for _, line := range r.Lines {
+			name := r.MenuName + " / " + line.IngredientName
+			ingredient, ok := s.ingredients[line.IngredientName]
+			if !ok {
+				return fmt.Errorf("メニュー「%s」の食材「%s」がシードデータに定義されていません", r.MenuName, line.IngredientName)
 			}
 
-			item := model.MenuIngredientItem{
-				MenuID:       menu.ID,
-				IngredientID: ingredient.ID,
-				Amount:       ingInfo.Amount, // Create時にAmountも設定
+			existing, ok := existingItems[line.IngredientName]
+			delete(existingItems, line.IngredientName)
+			if !ok {
+				item := &model.MenuIngredientItem{MenuID: menu.ID, IngredientID: ingredient.ID, Amount: line.Amount}
+				if err := s.create(item); err != nil {
+					return err
+				}
+				s.record(ActionCreate, "recipe", name, "amount "+formatValue(line.Amount))
+				continue
 			}
-			// 複合主キー(MenuID, IngredientID)で存在チェック
-			var existingItem model.MenuIngredientItem
-			result := db.Where("menu_id = ? AND ingredient_id = ?", item.MenuID, item.IngredientID).First(&existingItem)
-
-			if result.Error != nil {
-				if result.Error == gorm.ErrRecordNotFound {
-					// 存在しないので作成
-					if err := db.Create(&item).Error; err != nil {
+			if existing.Amount != line.Amount {
+				if !s.dryRun {
+					if err := s.db.Model(existing).Update("amount", line.Amount).Error; err != nil {
 						return err
 					}
-				} else {
-					// その他のDBエラー
-					return result.Error
 				}
-			} else {
-				// 存在する場合、Amountが異なる場合のみ更新
-				if existingItem.Amount != item.Amount {
-					if err := db.Model(&existingItem).Update("amount", item.Amount).Error; err != nil {
-						return err
-					}
+				s.record(ActionUpdate, "recipe", name, fmt.Sprintf("amount %s → %s", formatValue(existing.Amount), formatValue(line.Amount)))
+			}
+		}
+
+		// シードのレシピから外れた材料を削除する
+		for i := range menu.MenuIngredientItems {
+			item := &menu.MenuIngredientItems[i]
+			if _, ok := existingItems[item.Ingredient.Name]; !ok {
+				continue
+			}
+			if !s.dryRun {
+				if err := s.db.Delete(item).Error; err != nil {
+					return err
 				}
 			}
+			s.record(ActionDelete, "recipe", r.MenuName+" / "+item.Ingredient.Name, "")
+		}
+
+		if err := s.syncVersion(&menu, len(s.changes) > changesBefore); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncVersion は、メニューの内容が最新の版と異なる場合（版がまだ無い場合を含む）に新しい版を作成します。
+// dryRun の場合はメニューを読み直せないため、このメニューに変更があったかどうかで判定します。
+func (s *seeder) syncVersion(menu *model.Menu, changed bool) error {
+	next := 1
+	if menu.CurrentVersion != nil {
+		next = menu.CurrentVersion.Version + 1
+	}
+	if s.dryRun {
+		if changed || menu.CurrentVersion == nil {
+			s.record(ActionCreate, "version", menu.Name, fmt.Sprintf("v%d", next))
+		}
+		return nil
+	}
+
+	// 反映後の内容で版を作るため、レシピと調理手順を読み直す
+	var current model.Menu
+	err := s.db.
+		Preload("MenuIngredientItems", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("MenuIngredientItems.Ingredient").
+		Preload("Steps", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		First(&current, "id = ?", menu.ID).Error
+	if err != nil {
+		return err
+	}
+	version := model.NewMenuVersion(&current)
+	if menu.CurrentVersion != nil && menu.CurrentVersion.ContentHash == version.ContentHash {
+		return nil
+	}
+	version.Version = next
+	if err := s.db.Create(version).Error; err != nil {
+		return err
+	}
+	if err := s.db.Model(&current).Update("current_version_id", version.ID).Error; err != nil {
+		return err
+	}
+	s.record(ActionCreate, "version", menu.Name, fmt.Sprintf("v%d", next))
+	return nil
+}
+
+// syncMenuDetails は、既存メニューの出来上がり量・コツ・出典をシードの値に合わせます。
+func (s *seeder) syncMenuDetails(menu *model.Menu, r *usecase.RecipeRecord) error {
+	servings := r.Servings
+	if servings == 0 {
+		servings = 1
+	}
+	updates := make(map[string]any)
+	var details []string
+	if menu.Servings != servings {
+		updates["servings"] = servings
+		details = append(details, fmt.Sprintf("servings %d → %d", menu.Servings, servings))
+	}
+	if derefString(menu.Tips) != r.Tips {
+		updates["tips"] = optionalString(r.Tips)
+		details = append(details, "tips")
+	}
+	if derefString(menu.SourceURL) != r.SourceURL {
+		updates["source_url"] = optionalString(r.SourceURL)
+		details = append(details, fmt.Sprintf("source_url %q → %q", derefString(menu.SourceURL), r.SourceURL))
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	if !s.dryRun {
+		if err := s.db.Model(menu).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	s.record(ActionUpdate, "menu", menu.Name, strings.Join(details, ", "))
+	return nil
+}
+
+// syncSteps は、調理手順がシードと異なる場合に丸ごと置き換えます。
+func (s *seeder) syncSteps(menu *model.Menu, instructions []string) error {
+	same := len(menu.Steps) == len(instructions)
+	for i := 0; same && i < len(instructions); i++ {
+		same = menu.Steps[i].Instruction == instructions[i]
+	}
+	if same {
+		return nil
+	}
+
+	if !s.dryRun {
+		if err := s.db.Where("menu_id = ?", menu.ID).Delete(&model.MenuStep{}).Error; err != nil {
+			return err
+		}
+		for i, instruction := range instructions {
+			step := &model.MenuStep{MenuID: menu.ID, Position: i + 1, Instruction: instruction}
+			if err := s.create(step); err != nil {
+				return err
+			}
 		}
 	}
+	action := ActionUpdate
+	if len(menu.Steps) == 0 {
+		action = ActionCreate
+	}
+	s.record(action, "steps", menu.Name, fmt.Sprintf("%d → %d steps", len(menu.Steps), len(instructions)))
 	return nil
 }
+
+// roundRatio は、比率をカラムの精度（小数点以下4桁）に丸めます。
+// 丸めないと、逆数の比率が保存後の値と一致せず毎回更新と判定されてしまいます。
+func roundRatio(ratio float64) float64 {
+	return math.Round(ratio*10000) / 10000
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func formatValue(v any) string {
+	switch t := v.(type) {
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case *int:
+		if t == nil {
+			return "null"
+		}
+		return strconv.Itoa(*t)
+	case *float64:
+		if t == nil {
+			return "null"
+		}
+		return strconv.FormatFloat(*t, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,626 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+// Catalog は、レシピの一括取り込み/書き出しで扱う食材とメニューの集合です。
+// YAML/JSON/CSVといったファイル形式には依存しません。
+type Catalog struct {
+	Ingredients   []*CatalogIngredientRecord
+	Recipes       []*RecipeRecord
+	Substitutions []*SubstitutionRecord
+}
+
+// CatalogIngredientRecord は、食材定義1件を表します。
+// Row は取り込み元ドキュメント内の位置で、エラー報告にのみ使用します。
+type CatalogIngredientRecord struct {
+	Row                   int
+	Name                  string
+	TypeName              string
+	BaseAmount            float64
+	Unit                  string
+	ShelfLifeDaysUnopened *int
+	ShelfLifeDaysOpened   *int
+	Aliases               []string // 食材の別名。取り込み時は登録済みの別名に追加します
+	// SeasonMonths は旬の月（1〜12）、SeasonalPriceMultiplier は旬の時期の価格の目安（通常を1とした倍率）です。
+	// 登録済みの食材では、指定された場合にだけ更新します。
+	SeasonMonths            []int
+	SeasonalPriceMultiplier *float64
+}
+
+// RecipeRecord は、メニュー1件とそのレシピを表します。
+// Servings, Tips, SourceURL, Steps はゼロ値の場合、取り込み時に既存の値を変更しません。
+type RecipeRecord struct {
+	Row       int
+	MenuName  string
+	Servings  int
+	Tips      string
+	SourceURL string
+	Steps     []string
+	Lines     []*RecipeLineRecord
+}
+
+// RecipeLineRecord は、レシピの材料1行を表します。
+// IngredientName は食材名のほか、登録済みの別名や表記ゆれ（"タマネギ" など）でも指定できます。
+// TypeName と BaseAmount は、未登録の食材をその場で作成する場合にのみ使用します。
+type RecipeLineRecord struct {
+	Row            int
+	IngredientName string
+	Amount         float64
+	Unit           string
+	TypeName       string
+	BaseAmount     float64
+}
+
+// SubstitutionRecord は、食材の代用ルール1件を表します。
+// From の分量に Ratio を掛けたものが To の分量になります。Bidirectional が true の場合は逆向き（比率は逆数）のルールも登録します。
+type SubstitutionRecord struct {
+	Row           int
+	From          string
+	To            string
+	Ratio         float64
+	Bidirectional bool
+}
+
+type ImportRecipesInput struct {
+	Catalog *Catalog
+	// Strict が true の場合、未登録の食材を作成せずにエラーとして報告します。
+	Strict bool
+}
+
+type ImportRecipesOutput struct {
+	Applied            bool              `json:"applied"`
+	CreatedMenus       int               `json:"created_menus"`
+	UpdatedMenus       int               `json:"updated_menus"`
+	CreatedIngredients []string          `json:"created_ingredients"`
+	CreatedAliases     []string          `json:"created_aliases"`
+	UpdatedSeasons     []string          `json:"updated_seasons"`     // 旬の設定を更新した登録済みの食材
+	SavedSubstitutions int               `json:"saved_substitutions"` // 登録・更新した代用ルールの数（逆向きのルールを含む）
+	Errors             []*ImportRowError `json:"errors"`
+}
+
+type MenuDetailOutput struct {
+	ID          string                `json:"id"`
+	Version     int                   `json:"version"` // 最新の版の番号。版がまだ無い場合は0
+	Name        string                `json:"name"`
+	Servings    int                   `json:"servings"`
+	Steps       []string              `json:"steps"`
+	Tips        *string               `json:"tips"`
+	SourceURL   *string               `json:"source_url"`
+	Ingredients []*MenuIngredientInfo `json:"ingredients"`
+}
+
+// ImportRowError は、取り込みドキュメントの1行に対するエラーです。
+type ImportRowError struct {
+	Row        int    `json:"row"`
+	Menu       string `json:"menu,omitempty"`
+	Ingredient string `json:"ingredient,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ErrEmptyCatalog は、取り込むデータが1件も含まれていない場合に返されます。
+var ErrEmptyCatalog = newError(ErrInvalidRequest, "empty_catalog", "取り込むデータがありません")
+
+// --- Usecase Interface ---
+
+// CatalogUsecase は、メニューと食材のマスターデータ（カタログ）に関するビジネスロジックのインターフェースです。
+type CatalogUsecase interface {
+	ImportRecipes(ctx context.Context, input ImportRecipesInput) (*ImportRecipesOutput, error)
+	ExportRecipes(ctx context.Context) (*Catalog, error)
+	DraftRecipe(ctx context.Context, input DraftRecipeInput) (*DraftRecipeOutput, error)
+	GetMenu(ctx context.Context, menuID string) (*MenuDetailOutput, error)
+	GetMenuVersions(ctx context.Context, menuID string) ([]*MenuVersionOutput, error)
+	FindCookableMenus(ctx context.Context, input FindCookableMenusInput) (*FindCookableMenusOutput, error)
+	SearchIngredients(ctx context.Context, input SearchIngredientsInput) ([]*IngredientSearchResult, error)
+	AddIngredientAliases(ctx context.Context, input AddIngredientAliasesInput) (*IngredientAliasesOutput, error)
+}
+
+// --- Usecase Implementation ---
+
+// catalogUsecase は CatalogUsecase インターフェースの実装です。
+type catalogUsecase struct {
+	menuRepo       repository.MenuRepository
+	ingredientRepo repository.IngredientRepository
+}
+
+// NewCatalogUsecase は新しい catalogUsecase のインスタンスを生成します。
+func NewCatalogUsecase(menuRepo repository.MenuRepository, ingredientRepo repository.IngredientRepository) CatalogUsecase {
+	return &catalogUsecase{
+		menuRepo:       menuRepo,
+		ingredientRepo: ingredientRepo,
+	}
+}
+
+// ImportRecipes は、カタログを検証したうえでメニューとレシピを登録します。
+// 1行でもエラーがあれば何も保存せず、行ごとのエラーを報告します。保存は1つのトランザクションで行い、途中で失敗した場合も何も残しません。
+// 既存のメニューはレシピが丸ごと置き換えられるため、書き出した内容を再度取り込んでも結果は変わりません。
+func (u *catalogUsecase) ImportRecipes(ctx context.Context, input ImportRecipesInput) (*ImportRecipesOutput, error) {
+	if input.Catalog == nil || (len(input.Catalog.Ingredients) == 0 && len(input.Catalog.Recipes) == 0 && len(input.Catalog.Substitutions) == 0) {
+		return nil, ErrEmptyCatalog
+	}
+
+	types, err := u.ingredientRepo.FindIngredientTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材分類の取得に失敗しました: %w", err)
+	}
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	menus, err := u.menuRepo.FindAllMenus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("メニューの取得に失敗しました: %w", err)
+	}
+
+	typeIDs := make(map[string]string, len(types))
+	for _, t := range types {
+		typeIDs[t.Name] = t.ID
+	}
+	resolver := newIngredientResolver(ingredients)
+	menuMap := make(map[string]*model.Menu, len(menus))
+	for _, m := range menus {
+		menuMap[m.Name] = m
+	}
+
+	output := &ImportRecipesOutput{CreatedIngredients: []string{}, CreatedAliases: []string{}, UpdatedSeasons: []string{}, Errors: []*ImportRowError{}}
+	addError := func(row int, menu, ingredient, message string) {
+		output.Errors = append(output.Errors, &ImportRowError{Row: row, Menu: menu, Ingredient: ingredient, Message: message})
+	}
+
+	// 追加する別名。新規作成する食材のIDは保存時に決まるため、食材への参照で持っておく
+	type pendingAlias struct {
+		alias      string
+		ingredient *model.Ingredient
+	}
+	var newAliases []pendingAlias
+	addAliases := func(row int, ing *model.Ingredient, aliases []string) {
+		for _, alias := range aliases {
+			if resolver.resolve(alias) == ing {
+				continue
+			}
+			if owner := resolver.register(alias, ing); owner != nil {
+				addError(row, "", ing.Name, fmt.Sprintf("別名「%s」は食材「%s」で使われています", alias, owner.Name))
+				continue
+			}
+			newAliases = append(newAliases, pendingAlias{alias: alias, ingredient: ing})
+		}
+	}
+
+	// 旬の設定を検証し、食材に反映する。変更があった場合は true を返す
+	applySeason := func(row int, ing *model.Ingredient, def *CatalogIngredientRecord) bool {
+		changed := false
+		if def.SeasonMonths != nil {
+			months, err := model.NewSeasonMonths(def.SeasonMonths)
+			if err != nil {
+				addError(row, "", def.Name, err.Error())
+				return false
+			}
+			changed = ing.SeasonMonths != months
+			ing.SeasonMonths = months
+		}
+		if m := def.SeasonalPriceMultiplier; m != nil {
+			if *m <= 0 {
+				addError(row, "", def.Name, "旬の時期の価格の倍率は正の数で指定してください")
+				return false
+			}
+			changed = changed || ing.SeasonalPriceMultiplier == nil || *ing.SeasonalPriceMultiplier != *m
+			ing.SeasonalPriceMultiplier = m
+		}
+		return changed
+	}
+
+	// 新規作成する食材。ドキュメント内で後から参照されても解決できるよう resolver にも登録する
+	var newIngredients []*model.Ingredient
+	var seasonUpdates []*model.Ingredient
+	seasonUpdated := make(map[*model.Ingredient]bool)
+	define := func(row int, menu string, def *CatalogIngredientRecord) {
+		if input.Strict {
+			addError(row, menu, def.Name, "未登録の食材です（strictモードでは食材を作成しません）")
+			return
+		}
+		typeID, ok := typeIDs[def.TypeName]
+		if !ok {
+			addError(row, menu, def.Name, fmt.Sprintf("食材分類「%s」が登録されていません", def.TypeName))
+			return
+		}
+		if def.Unit == "" {
+			addError(row, menu, def.Name, "未登録の食材には単位の指定が必要です")
+			return
+		}
+		ing := &model.Ingredient{
+			TypeID:                typeID,
+			Name:                  def.Name,
+			BaseAmount:            def.BaseAmount,
+			Unit:                  def.Unit,
+			ShelfLifeDaysUnopened: def.ShelfLifeDaysUnopened,
+			ShelfLifeDaysOpened:   def.ShelfLifeDaysOpened,
+		}
+		applySeason(row, ing, def)
+		resolver.register(ing.Name, ing)
+		newIngredients = append(newIngredients, ing)
+		addAliases(row, ing, def.Aliases)
+	}
+
+	for _, def := range input.Catalog.Ingredients {
+		if def.Name == "" {
+			addError(def.Row, "", "", "食材名が空です")
+			continue
+		}
+		if existing := resolver.resolve(def.Name); existing != nil {
+			if def.Unit != "" && def.Unit != existing.Unit {
+				addError(def.Row, "", def.Name, fmt.Sprintf("単位「%s」が登録済みの単位「%s」と異なります", def.Unit, existing.Unit))
+			}
+			addAliases(def.Row, existing, def.Aliases)
+			// ドキュメント内で定義した食材は、作成時に旬の設定も保存される
+			if applySeason(def.Row, existing, def) && existing.ID != "" && !seasonUpdated[existing] {
+				seasonUpdated[existing] = true
+				seasonUpdates = append(seasonUpdates, existing)
+			}
+			continue
+		}
+		define(def.Row, "", def)
+	}
+
+	seenMenus := make(map[string]bool)
+	for _, recipe := range input.Catalog.Recipes {
+		if recipe.MenuName == "" {
+			addError(recipe.Row, "", "", "メニュー名が空です")
+			continue
+		}
+		if seenMenus[recipe.MenuName] {
+			addError(recipe.Row, recipe.MenuName, "", "メニューが重複しています")
+			continue
+		}
+		seenMenus[recipe.MenuName] = true
+		if len(recipe.Lines) == 0 {
+			addError(recipe.Row, recipe.MenuName, "", "材料が1つも指定されていません")
+			continue
+		}
+		if recipe.Servings < 0 {
+			addError(recipe.Row, recipe.MenuName, "", "出来上がり量（人数）は正の数で指定してください")
+		}
+
+		// 別名で書かれていても同じ食材なら重複とみなす
+		seenLines := make(map[string]bool)
+		for _, line := range recipe.Lines {
+			if line.IngredientName == "" {
+				addError(line.Row, recipe.MenuName, "", "食材名が空です")
+				continue
+			}
+			existing := resolver.resolve(line.IngredientName)
+			lineKey := model.NormalizeIngredientName(line.IngredientName)
+			if existing != nil {
+				lineKey = model.NormalizeIngredientName(existing.Name)
+			}
+			if seenLines[lineKey] {
+				addError(line.Row, recipe.MenuName, line.IngredientName, "同じメニュー内で食材が重複しています")
+				continue
+			}
+			seenLines[lineKey] = true
+			if line.Amount <= 0 {
+				addError(line.Row, recipe.MenuName, line.IngredientName, "分量は正の数で指定してください")
+				continue
+			}
+
+			if existing == nil {
+				if line.TypeName == "" && !input.Strict {
+					addError(line.Row, recipe.MenuName, line.IngredientName, "未登録の食材です。作成するには分類と単位を指定してください")
+					continue
+				}
+				baseAmount := line.BaseAmount
+				if baseAmount <= 0 {
+					baseAmount = line.Amount
+				}
+				define(line.Row, recipe.MenuName, &CatalogIngredientRecord{
+					Name:       line.IngredientName,
+					TypeName:   line.TypeName,
+					BaseAmount: baseAmount,
+					Unit:       line.Unit,
+				})
+				continue
+			}
+			if line.Unit != "" && line.Unit != existing.Unit {
+				addError(line.Row, recipe.MenuName, line.IngredientName, fmt.Sprintf("単位「%s」が登録済みの単位「%s」と異なります", line.Unit, existing.Unit))
+			}
+		}
+	}
+
+	// 代用ルール。食材はドキュメント内で定義されたものも参照できるよう、食材とレシピの後に検証する
+	type pendingSubstitution struct {
+		from, to *model.Ingredient
+		ratio    float64
+	}
+	var newSubstitutions []pendingSubstitution
+	seenSubstitutions := make(map[[2]*model.Ingredient]bool)
+	for _, rule := range input.Catalog.Substitutions {
+		from, to := resolver.resolve(rule.From), resolver.resolve(rule.To)
+		switch {
+		case from == nil:
+			addError(rule.Row, "", rule.From, "代用元の食材が登録されていません")
+			continue
+		case to == nil:
+			addError(rule.Row, "", rule.To, "代用先の食材が登録されていません")
+			continue
+		case from == to:
+			addError(rule.Row, "", rule.From, "代用元と代用先が同じ食材です")
+			continue
+		case rule.Ratio <= 0:
+			addError(rule.Row, "", rule.From, "代用の比率は正の数で指定してください")
+			continue
+		}
+		pairs := []pendingSubstitution{{from: from, to: to, ratio: rule.Ratio}}
+		if rule.Bidirectional {
+			pairs = append(pairs, pendingSubstitution{from: to, to: from, ratio: 1 / rule.Ratio})
+		}
+		for _, pair := range pairs {
+			key := [2]*model.Ingredient{pair.from, pair.to}
+			if seenSubstitutions[key] {
+				addError(rule.Row, "", pair.from.Name, fmt.Sprintf("「%s」から「%s」への代用ルールが重複しています", pair.from.Name, pair.to.Name))
+				continue
+			}
+			seenSubstitutions[key] = true
+			newSubstitutions = append(newSubstitutions, pair)
+		}
+	}
+
+	if len(output.Errors) > 0 {
+		return output, nil
+	}
+
+	// 食材・別名・代用ルールとメニューは同じトランザクションで保存し、途中で失敗したら何も残さない
+	err = u.menuRepo.Transaction(ctx, func(txRepo repository.MenuRepository) error {
+		ingredientRepo := txRepo.IngredientRepository()
+		if len(newIngredients) > 0 {
+			if err := ingredientRepo.CreateIngredients(ctx, newIngredients); err != nil {
+				return fmt.Errorf("食材の作成に失敗しました: %w", err)
+			}
+			for _, ing := range newIngredients {
+				output.CreatedIngredients = append(output.CreatedIngredients, ing.Name)
+			}
+		}
+		for _, ing := range seasonUpdates {
+			if err := ingredientRepo.UpdateIngredientSeason(ctx, ing); err != nil {
+				return fmt.Errorf("食材の旬の更新に失敗しました: %w", err)
+			}
+			output.UpdatedSeasons = append(output.UpdatedSeasons, ing.Name)
+		}
+		if len(newAliases) > 0 {
+			aliases := make([]*model.IngredientAlias, len(newAliases))
+			for i, pending := range newAliases {
+				aliases[i] = &model.IngredientAlias{IngredientID: pending.ingredient.ID, Alias: pending.alias}
+			}
+			if err := ingredientRepo.CreateIngredientAliases(ctx, aliases); err != nil {
+				return fmt.Errorf("食材の別名の作成に失敗しました: %w", err)
+			}
+			for _, pending := range newAliases {
+				output.CreatedAliases = append(output.CreatedAliases, pending.alias)
+			}
+		}
+
+		if len(newSubstitutions) > 0 {
+			substitutions := make([]*model.IngredientSubstitution, len(newSubstitutions))
+			for i, pending := range newSubstitutions {
+				substitutions[i] = &model.IngredientSubstitution{
+					FromIngredientID: pending.from.ID,
+					ToIngredientID:   pending.to.ID,
+					Ratio:            pending.ratio,
+				}
+			}
+			if err := ingredientRepo.SaveSubstitutions(ctx, substitutions); err != nil {
+				return fmt.Errorf("代用ルールの保存に失敗しました: %w", err)
+			}
+			output.SavedSubstitutions = len(substitutions)
+		}
+
+		for _, recipe := range input.Catalog.Recipes {
+			menu, ok := menuMap[recipe.MenuName]
+			if ok {
+				applyRecipeDetails(menu, recipe)
+				if err := txRepo.UpdateMenuDetails(ctx, menu); err != nil {
+					return err
+				}
+				output.UpdatedMenus++
+			} else {
+				menu = &model.Menu{Name: recipe.MenuName}
+				applyRecipeDetails(menu, recipe)
+				if err := txRepo.CreateMenu(ctx, menu); err != nil {
+					return err
+				}
+				output.CreatedMenus++
+			}
+
+			if len(recipe.Steps) > 0 {
+				steps := make([]*model.MenuStep, len(recipe.Steps))
+				for i, instruction := range recipe.Steps {
+					steps[i] = &model.MenuStep{Instruction: instruction}
+				}
+				if err := txRepo.ReplaceMenuSteps(ctx, menu.ID, steps); err != nil {
+					return err
+				}
+				menu.Steps = make([]model.MenuStep, len(steps))
+				for i, step := range steps {
+					menu.Steps[i] = *step
+				}
+			}
+
+			items := make([]*model.MenuIngredientItem, len(recipe.Lines))
+			for i, line := range recipe.Lines {
+				ingredient := resolver.resolve(line.IngredientName)
+				items[i] = &model.MenuIngredientItem{
+					IngredientID: ingredient.ID,
+					Amount:       line.Amount,
+					Ingredient:   *ingredient,
+				}
+			}
+			if err := txRepo.ReplaceMenuIngredientItems(ctx, menu.ID, items); err != nil {
+				return err
+			}
+			menu.MenuIngredientItems = make([]model.MenuIngredientItem, len(items))
+			for i, item := range items {
+				menu.MenuIngredientItems[i] = *item
+			}
+
+			// 内容が変わったメニューは新しい版を作る。作成済みの食事計画は前の版を参照し続ける
+			if _, err := ensureCurrentVersion(ctx, txRepo, menu); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("レシピの保存に失敗しました: %w", err)
+	}
+
+	output.Applied = true
+	return output, nil
+}
+
+// ExportRecipes は、登録済みの食材とメニューをすべてカタログとして返します。
+func (u *catalogUsecase) ExportRecipes(ctx context.Context) (*Catalog, error) {
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	menus, err := u.menuRepo.FindAllMenus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("メニューの取得に失敗しました: %w", err)
+	}
+	substitutions, err := u.ingredientRepo.FindSubstitutions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("代用ルールの取得に失敗しました: %w", err)
+	}
+
+	catalog := &Catalog{
+		Ingredients: make([]*CatalogIngredientRecord, len(ingredients)),
+		Recipes:     make([]*RecipeRecord, len(menus)),
+	}
+	for i, ing := range ingredients {
+		catalog.Ingredients[i] = &CatalogIngredientRecord{
+			Name:                    ing.Name,
+			TypeName:                ing.IngredientType.Name,
+			BaseAmount:              ing.BaseAmount,
+			Unit:                    ing.Unit,
+			ShelfLifeDaysUnopened:   ing.ShelfLifeDaysUnopened,
+			ShelfLifeDaysOpened:     ing.ShelfLifeDaysOpened,
+			Aliases:                 toAliasTexts(ing.Aliases),
+			SeasonalPriceMultiplier: ing.SeasonalPriceMultiplier,
+		}
+		if ing.HasSeason() {
+			catalog.Ingredients[i].SeasonMonths = ing.SeasonMonths.Months()
+		}
+	}
+	for i, menu := range menus {
+		lines := make([]*RecipeLineRecord, len(menu.MenuIngredientItems))
+		for j, item := range menu.MenuIngredientItems {
+			lines[j] = &RecipeLineRecord{
+				IngredientName: item.Ingredient.Name,
+				Amount:         item.Amount,
+				Unit:           item.Ingredient.Unit,
+			}
+		}
+		catalog.Recipes[i] = &RecipeRecord{
+			MenuName:  menu.Name,
+			Servings:  menu.Servings,
+			Tips:      derefString(menu.Tips),
+			SourceURL: derefString(menu.SourceURL),
+			Steps:     toStepTexts(menu.Steps),
+			Lines:     lines,
+		}
+	}
+	catalog.Substitutions = toSubstitutionRecords(substitutions)
+	return catalog, nil
+}
+
+// toSubstitutionRecords は、代用ルールをカタログのレコードに変換します。
+// 比率が互いに逆数になっている逆向きのルールの組は、1件の相互代用のレコードにまとめます。
+func toSubstitutionRecords(substitutions []*model.IngredientSubstitution) []*SubstitutionRecord {
+	byPair := make(map[[2]string]*model.IngredientSubstitution, len(substitutions))
+	for _, sub := range substitutions {
+		byPair[[2]string{sub.FromIngredientID, sub.ToIngredientID}] = sub
+	}
+
+	var records []*SubstitutionRecord
+	merged := make(map[*model.IngredientSubstitution]bool)
+	for _, sub := range substitutions {
+		if merged[sub] {
+			continue
+		}
+		record := &SubstitutionRecord{From: sub.FromIngredient.Name, To: sub.ToIngredient.Name, Ratio: sub.Ratio}
+		if inverse, ok := byPair[[2]string{sub.ToIngredientID, sub.FromIngredientID}]; ok && !merged[inverse] &&
+			math.Abs(sub.Ratio*inverse.Ratio-1) < 1e-3 {
+			record.Bidirectional = true
+			merged[inverse] = true
+		}
+		merged[sub] = true
+		records = append(records, record)
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].From != records[j].From {
+			return records[i].From < records[j].From
+		}
+		return records[i].To < records[j].To
+	})
+	return records
+}
+
+// GetMenu は、指定されたIDのメニューを調理手順とともに取得します。メニューが存在しない場合は ErrMenuNotFound を返します。
+func (u *catalogUsecase) GetMenu(ctx context.Context, menuID string) (*MenuDetailOutput, error) {
+	menu, err := u.menuRepo.FindMenuByID(ctx, menuID)
+	if err != nil {
+		return nil, notFound(err, ErrMenuNotFound)
+	}
+	version := 0
+	if menu.CurrentVersion != nil {
+		version = menu.CurrentVersion.Version
+	}
+	return &MenuDetailOutput{
+		ID:          menu.ID,
+		Version:     version,
+		Name:        menu.Name,
+		Servings:    menu.Servings,
+		Steps:       toStepTexts(menu.Steps),
+		Tips:        menu.Tips,
+		SourceURL:   menu.SourceURL,
+		Ingredients: toMenuIngredientInfo(menu.MenuIngredientItems),
+	}, nil
+}
+
+// applyRecipeDetails は、取り込むレコードで指定された出来上がり量・コツ・出典をメニューに反映します。
+func applyRecipeDetails(menu *model.Menu, recipe *RecipeRecord) {
+	if recipe.Servings > 0 {
+		menu.Servings = recipe.Servings
+	}
+	if recipe.Tips != "" {
+		menu.Tips = &recipe.Tips
+	}
+	if recipe.SourceURL != "" {
+		menu.SourceURL = &recipe.SourceURL
+	}
+}
+
+func toAliasTexts(aliases []model.IngredientAlias) []string {
+	if len(aliases) == 0 {
+		return nil
+	}
+	texts := make([]string, len(aliases))
+	for i, alias := range aliases {
+		texts[i] = alias.Alias
+	}
+	return texts
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
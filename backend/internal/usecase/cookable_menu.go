@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+// FindCookableMenusInput は、手持ちの食材から作れるメニューを探すための条件です。
+type FindCookableMenusInput struct {
+	Ingredients []*OnHandIngredientInput
+	Servings    int // 作る人数。0 以下の場合は1人前
+	Limit       int // 0 以下の場合は DefaultCookableMenuLimit 件
+}
+
+// OnHandIngredientInput は、手持ちの食材1件です。
+// Name は食材名のほか別名や表記ゆれでも指定できます。Amount は食材の単位での手持ちの量で、nil の場合は十分にあるものとみなします。
+type OnHandIngredientInput struct {
+	Name   string
+	Amount *float64
+}
+
+type FindCookableMenusOutput struct {
+	Menus []*CookableMenuOutput `json:"menus"`
+	// Unknown は、登録済みの食材に対応付けられなかった名前です。
+	Unknown []string `json:"unknown"`
+}
+
+// CookableMenuOutput は、メニュー1件と手持ちの食材でどれだけ賄えるかを表します。
+// Coverage は材料ごとの充足率（手持ちの量 / 必要な量、最大1）の平均で、1 なら買い足さずに作れます。
+type CookableMenuOutput struct {
+	MenuID   string                     `json:"menu_id"`
+	MenuName string                     `json:"menu_name"`
+	Coverage float64                    `json:"coverage"`
+	Have     []string                   `json:"have"`
+	Missing  []*MissingIngredientOutput `json:"missing"`
+}
+
+// MissingIngredientOutput は、足りない材料です。Amount は必要な量、Shortage は不足している量です。
+type MissingIngredientOutput struct {
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Shortage float64 `json:"shortage"`
+	Unit     string  `json:"unit"`
+}
+
+const (
+	DefaultCookableMenuLimit = 10
+	MaxCookableMenuLimit     = 50
+)
+
+// ErrNoOnHandIngredients は、手持ちの食材が1つも指定されていない場合に返されます。
+var ErrNoOnHandIngredients = newError(ErrInvalidRequest, "no_on_hand_ingredients", "手持ちの食材が指定されていません")
+
+// --- Usecase Implementation ---
+
+// FindCookableMenus は、手持ちの食材でどれだけ材料を賄えるかでメニューを順位付けし、足りない材料とともに返します。
+// 候補は手持ちの食材を1つ以上使うメニューに限られ、食材からメニューを引く転置インデックスで絞り込みます。
+// 充足率が同じ場合は、足りない材料の少ないもの、メニュー名の順に並べます。
+func (u *catalogUsecase) FindCookableMenus(ctx context.Context, input FindCookableMenusInput) (*FindCookableMenusOutput, error) {
+	if len(input.Ingredients) == 0 {
+		return nil, ErrNoOnHandIngredients
+	}
+	servings := input.Servings
+	if servings <= 0 {
+		servings = 1
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultCookableMenuLimit
+	}
+	limit = min(limit, MaxCookableMenuLimit)
+
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	resolver := newIngredientResolver(ingredients)
+
+	output := &FindCookableMenusOutput{Menus: []*CookableMenuOutput{}, Unknown: []string{}}
+
+	// 食材IDごとの手持ちの量。量の指定が無いものは nil（十分にある）とし、同じ食材が複数回あれば合算する
+	onHand := make(map[string]*float64)
+	var ingredientIDs []string
+	for _, item := range input.Ingredients {
+		name := strings.TrimSpace(item.Name)
+		ing := resolver.resolve(name)
+		if ing == nil {
+			if name != "" {
+				output.Unknown = append(output.Unknown, name)
+			}
+			continue
+		}
+		current, seen := onHand[ing.ID]
+		if !seen {
+			ingredientIDs = append(ingredientIDs, ing.ID)
+		}
+		switch {
+		case item.Amount == nil || (seen && current == nil):
+			onHand[ing.ID] = nil
+		case seen:
+			total := *current + *item.Amount
+			onHand[ing.ID] = &total
+		default:
+			amount := *item.Amount
+			onHand[ing.ID] = &amount
+		}
+	}
+	if len(ingredientIDs) == 0 {
+		return output, nil
+	}
+
+	menuIDs, err := u.menuRepo.FindMenuIDsByIngredientIDs(ctx, ingredientIDs)
+	if err != nil {
+		return nil, fmt.Errorf("メニューの検索に失敗しました: %w", err)
+	}
+	menus, err := u.menuRepo.FindMenusByIDs(ctx, menuIDs)
+	if err != nil {
+		return nil, fmt.Errorf("メニューの取得に失敗しました: %w", err)
+	}
+
+	for _, menu := range menus {
+		if len(menu.MenuIngredientItems) == 0 {
+			continue
+		}
+		result := &CookableMenuOutput{MenuID: menu.ID, MenuName: menu.Name, Have: []string{}, Missing: []*MissingIngredientOutput{}}
+		var covered float64
+		for _, item := range menu.MenuIngredientItems {
+			need := item.Amount * float64(servings)
+			have, ok := onHand[item.IngredientID]
+			switch {
+			case ok && (have == nil || *have >= need):
+				covered++
+				result.Have = append(result.Have, item.Ingredient.Name)
+				continue
+			case ok:
+				covered += *have / need
+				result.Have = append(result.Have, item.Ingredient.Name)
+			}
+			shortage := need
+			if ok {
+				shortage -= *have
+			}
+			result.Missing = append(result.Missing, &MissingIngredientOutput{
+				Name:     item.Ingredient.Name,
+				Amount:   roundAmount(need),
+				Shortage: roundAmount(shortage),
+				Unit:     item.Ingredient.Unit,
+			})
+		}
+		result.Coverage = roundAmount(covered / float64(len(menu.MenuIngredientItems)))
+		output.Menus = append(output.Menus, result)
+	}
+
+	// menus は名前順なので、安定ソートで同順位のものは名前順のまま残す
+	sort.SliceStable(output.Menus, func(i, j int) bool {
+		a, b := output.Menus[i], output.Menus[j]
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		return len(a.Missing) < len(b.Missing)
+	})
+	if len(output.Menus) > limit {
+		output.Menus = output.Menus[:limit]
+	}
+	return output, nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// Error は、ユースケースが返すエラーです。
+// Code はエラーを識別する変わらない文字列で、API の応答でもそのまま返します。Message は利用者に表示できる説明です。
+// エラーは種類（ErrNotFound、ErrValidation など）に分類されていて、errors.Is(err, ErrNotFound) のように種類で判定できます。
+type Error struct {
+	Code    string
+	Message string
+	kind    *Error
+}
+
+// newErrorKind は、エラーの種類を生成します。
+func newErrorKind(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// newError は、kind の種類に分類されるエラーを生成します。
+func newError(kind *Error, code, message string) *Error {
+	return &Error{Code: code, Message: message, kind: kind}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is は、target がこのエラーの種類である場合に true を返します。
+func (e *Error) Is(target error) bool {
+	return e.kind != nil && e.kind == target
+}
+
+// Kind は、エラーの種類を返します。種類そのものの場合は自身を返します。
+func (e *Error) Kind() *Error {
+	if e.kind == nil {
+		return e
+	}
+	return e.kind
+}
+
+// エラーの種類です。
+var (
+	// ErrNotFound は、指定されたデータが存在しない場合のエラーの種類です。
+	ErrNotFound = newErrorKind("not_found", "指定されたデータが見つかりません")
+	// ErrInvalidRequest は、パラメーターの形式が不正など、リクエストを解釈できない場合のエラーの種類です。
+	ErrInvalidRequest = newErrorKind("invalid_request", "リクエストの形式が不正です")
+	// ErrValidation は、リクエストは解釈できたが、内容が不正な場合のエラーの種類です。
+	ErrValidation = newErrorKind("validation_failed", "入力内容が不正です")
+	// ErrConflict は、現在のデータの状態と矛盾するため処理できない場合のエラーの種類です。
+	ErrConflict = newErrorKind("conflict", "現在の状態と矛盾するため処理できません")
+	// ErrPreconditionFailed は、指定された版（If-Match）が現在の版と一致しない場合のエラーの種類です。
+	ErrPreconditionFailed = newErrorKind("precondition_failed", "指定された版が現在の版と一致しません")
+	// ErrInsufficientMenus は、条件に合うメニューが足りず、献立を作成できない場合のエラーです。
+	ErrInsufficientMenus = newErrorKind("insufficient_menus", "条件に合うメニューが足りません")
+	// ErrPayloadTooLarge は、リクエストボディが受け付けられる大きさを超えている場合のエラーの種類です。
+	ErrPayloadTooLarge = newErrorKind("payload_too_large", "リクエストボディが大きすぎます")
+	// ErrUnauthorized は、計画の持ち主のトークンが指定されていない、または一致しない場合のエラーです。
+	ErrUnauthorized = newErrorKind("unauthorized", "認証が必要です")
+	// ErrForbidden は、操作が許可されていない場合のエラーの種類です。
+	ErrForbidden = newErrorKind("forbidden", "この操作は許可されていません")
+	// ErrGone は、指定されたデータが無効になっている場合のエラーの種類です。
+	ErrGone = newErrorKind("gone", "指定されたデータは無効になっています")
+)
+
+// 存在しないデータを指定した場合のエラーです。いずれも ErrNotFound に分類されます。
+var (
+	ErrPlanNotFound         = newError(ErrNotFound, "plan_not_found", "計画が見つかりません")
+	ErrShoppingItemNotFound = newError(ErrNotFound, "shopping_item_not_found", "買い物リストのアイテムが見つかりません")
+	ErrReceiptNotFound      = newError(ErrNotFound, "receipt_not_found", "レシートが見つかりません")
+	ErrStoreLayoutNotFound  = newError(ErrNotFound, "store_layout_not_found", "店舗レイアウトが見つかりません")
+	ErrMenuNotFound         = newError(ErrNotFound, "menu_not_found", "メニューが見つかりません")
+	ErrIngredientNotFound   = newError(ErrNotFound, "ingredient_not_found", "食材が見つかりません")
+	ErrShareTokenNotFound   = newError(ErrNotFound, "share_token_not_found", "共有リンクが見つかりません")
+)
+
+// notFound は、リポジトリが行を見つけられなかった（repository.ErrNotFound）場合に target を返し、それ以外のエラーはそのまま返します。
+func notFound(err error, target *Error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return target
+	}
+	return err
+}
+
+// findPlan は、計画を取得します。計画が存在しない場合は ErrPlanNotFound を返します。
+func findPlan(ctx context.Context, planRepo repository.PlanRepository, planID string) (*model.ShoppingPlan, error) {
+	plan, err := planRepo.FindShoppingPlanByID(ctx, planID)
+	if err != nil {
+		return nil, notFound(err, ErrPlanNotFound)
+	}
+	return plan, nil
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+type SearchIngredientsInput struct {
+	Query string
+	Limit int // 0 以下の場合は DefaultIngredientSearchLimit 件
+}
+
+// IngredientSearchResult は、食材検索の結果1件です。
+// MatchedName は一致した食材名または別名、Score は一致度（0〜1、1が完全一致）です。
+type IngredientSearchResult struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Unit        string   `json:"unit"`
+	Aliases     []string `json:"aliases"`
+	MatchedName string   `json:"matched_name"`
+	Score       float64  `json:"score"`
+}
+
+type AddIngredientAliasesInput struct {
+	IngredientID string
+	Aliases      []string
+}
+
+type IngredientAliasesOutput struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
+}
+
+const (
+	DefaultIngredientSearchLimit = 10
+	MaxIngredientSearchLimit     = 50
+)
+
+var (
+	// ErrEmptySearchQuery は、検索語が空（正規化すると何も残らない）の場合に返されます。
+	ErrEmptySearchQuery = newError(ErrInvalidRequest, "empty_search_query", "検索語が空です")
+	// ErrAliasConflict は、追加しようとした別名が他の食材の名前や別名と重なる場合に返されます。
+	ErrAliasConflict = newError(ErrConflict, "alias_conflict", "別名が他の食材と重複しています")
+)
+
+// --- Usecase Implementation ---
+
+// SearchIngredients は、ひらがな/カタカナ・全角/半角の違いを吸収したうえで、検索語に近い食材を一致度の高い順に返します。
+// 食材名だけでなく別名も検索の対象です。
+func (u *catalogUsecase) SearchIngredients(ctx context.Context, input SearchIngredientsInput) ([]*IngredientSearchResult, error) {
+	if model.NormalizeIngredientName(input.Query) == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultIngredientSearchLimit
+	}
+	limit = min(limit, MaxIngredientSearchLimit)
+
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+
+	matches := newIngredientResolver(ingredients).search(input.Query, limit)
+	results := make([]*IngredientSearchResult, len(matches))
+	for i, m := range matches {
+		results[i] = &IngredientSearchResult{
+			ID:          m.ingredient.ID,
+			Name:        m.ingredient.Name,
+			Type:        m.ingredient.IngredientType.Name,
+			Unit:        m.ingredient.Unit,
+			Aliases:     append([]string{}, toAliasTexts(m.ingredient.Aliases)...),
+			MatchedName: m.label,
+			Score:       roundAmount(m.score),
+		}
+	}
+	return results, nil
+}
+
+// AddIngredientAliases は、食材に別名を追加します。
+// 正規化すると既存の名前・別名と同じになるものは、同じ食材のものなら無視し、他の食材のものなら ErrAliasConflict を返します。
+func (u *catalogUsecase) AddIngredientAliases(ctx context.Context, input AddIngredientAliasesInput) (*IngredientAliasesOutput, error) {
+	target, err := u.ingredientRepo.FindIngredientByID(ctx, input.IngredientID)
+	if err != nil {
+		return nil, notFound(err, ErrIngredientNotFound)
+	}
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	// 対象の食材は、一覧から取得したものと同じポインタで扱う
+	for _, ing := range ingredients {
+		if ing.ID == target.ID {
+			target = ing
+		}
+	}
+
+	resolver := newIngredientResolver(ingredients)
+	var aliases []*model.IngredientAlias
+	for _, alias := range input.Aliases {
+		alias = strings.TrimSpace(alias)
+		if model.NormalizeIngredientName(alias) == "" || resolver.resolve(alias) == target {
+			continue
+		}
+		if owner := resolver.register(alias, target); owner != nil {
+			return nil, fmt.Errorf("%w: 「%s」は食材「%s」で使われています", ErrAliasConflict, alias, owner.Name)
+		}
+		aliases = append(aliases, &model.IngredientAlias{IngredientID: target.ID, Alias: alias})
+	}
+	if len(aliases) > 0 {
+		if err := u.ingredientRepo.CreateIngredientAliases(ctx, aliases); err != nil {
+			return nil, fmt.Errorf("食材の別名の作成に失敗しました: %w", err)
+		}
+	}
+
+	output := &IngredientAliasesOutput{ID: target.ID, Name: target.Name, Aliases: toAliasTexts(target.Aliases)}
+	for _, alias := range aliases {
+		output.Aliases = append(output.Aliases, alias.Alias)
+	}
+	if output.Aliases == nil {
+		output.Aliases = []string{}
+	}
+	return output, nil
+}
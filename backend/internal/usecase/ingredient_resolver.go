@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// ingredientResolver は、食材名や別名を正規化した文字列から、登録済みの食材を引きます。
+// 取り込みや下書きの作成など、利用者が入力した食材名を扱う処理はすべてこれを通して名前を解決します。
+type ingredientResolver struct {
+	byKey   map[string]*resolverEntry
+	entries []*resolverEntry
+	sorted  bool // entries が正規化した名前の長い順に並んでいるか
+}
+
+// resolverEntry は、食材名または別名の1件です。
+type resolverEntry struct {
+	key        string // model.NormalizeIngredientName で正規化した名前
+	label      string // 登録されている表記
+	ingredient *model.Ingredient
+}
+
+func newIngredientResolver(ingredients []*model.Ingredient) *ingredientResolver {
+	r := &ingredientResolver{byKey: make(map[string]*resolverEntry, len(ingredients))}
+	for _, ing := range ingredients {
+		r.register(ing.Name, ing)
+		for _, alias := range ing.Aliases {
+			r.register(alias.Alias, ing)
+		}
+	}
+	return r
+}
+
+// register は、名前を食材に対応付けます。
+// 正規化した名前が別の食材に対応付け済みの場合は登録せず、その食材を返します。
+func (r *ingredientResolver) register(name string, ing *model.Ingredient) *model.Ingredient {
+	key := model.NormalizeIngredientName(name)
+	if key == "" {
+		return nil
+	}
+	if entry, ok := r.byKey[key]; ok {
+		if entry.ingredient != ing {
+			return entry.ingredient
+		}
+		return nil
+	}
+	entry := &resolverEntry{key: key, label: name, ingredient: ing}
+	r.byKey[key] = entry
+	r.entries = append(r.entries, entry)
+	r.sorted = false
+	return nil
+}
+
+// resolve は、正規化した名前が完全に一致する食材を返します。
+func (r *ingredientResolver) resolve(name string) *model.Ingredient {
+	if entry, ok := r.byKey[model.NormalizeIngredientName(name)]; ok {
+		return entry.ingredient
+	}
+	return nil
+}
+
+// resolveContained は、完全一致する食材、なければ食材名・別名を含む最も長い名前の食材を返します。
+// "新玉ねぎ" は "玉ねぎ" に、"豚ロース肉 しょうが焼き用" は "豚ロース肉" に対応付けられます。
+func (r *ingredientResolver) resolveContained(name string) *model.Ingredient {
+	key := model.NormalizeIngredientName(name)
+	if entry, ok := r.byKey[key]; ok {
+		return entry.ingredient
+	}
+	if !r.sorted {
+		sort.SliceStable(r.entries, func(i, j int) bool {
+			return utf8.RuneCountInString(r.entries[i].key) > utf8.RuneCountInString(r.entries[j].key)
+		})
+		r.sorted = true
+	}
+	for _, entry := range r.entries {
+		if strings.Contains(key, entry.key) {
+			return entry.ingredient
+		}
+	}
+	return nil
+}
+
+// ingredientMatch は、検索語に対する食材1件の一致度です。
+type ingredientMatch struct {
+	ingredient *model.Ingredient
+	label      string
+	score      float64
+}
+
+// search は、検索語に近い食材を一致度の高い順に返します。
+// 食材ごとに、食材名と別名のうち最も一致度の高いものを採用します。
+func (r *ingredientResolver) search(query string, limit int) []*ingredientMatch {
+	q := model.NormalizeIngredientName(query)
+	if q == "" {
+		return nil
+	}
+
+	best := make(map[*model.Ingredient]*ingredientMatch)
+	for _, entry := range r.entries {
+		score := matchScore(q, entry.key)
+		if score == 0 {
+			continue
+		}
+		if m, ok := best[entry.ingredient]; !ok || score > m.score {
+			best[entry.ingredient] = &ingredientMatch{ingredient: entry.ingredient, label: entry.label, score: score}
+		}
+	}
+
+	matches := make([]*ingredientMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].ingredient.Name < matches[j].ingredient.Name
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// minFuzzySimilarity は、部分一致しない名前を候補に含める編集距離ベースの類似度の下限です。
+const minFuzzySimilarity = 0.6
+
+// matchScore は、正規化済みの検索語と名前の一致度を 0〜1 で返します。0 は候補外です。
+// 完全一致 > 前方一致 > 部分一致 > 編集距離による類似 の順に高くなり、同じ種類の中では長さの近いものほど高くなります。
+func matchScore(query, key string) float64 {
+	if query == key {
+		return 1
+	}
+	q, k := []rune(query), []rune(key)
+	ratio := float64(min(len(q), len(k))) / float64(max(len(q), len(k)))
+	switch {
+	case strings.HasPrefix(key, query):
+		return 0.7 + 0.2*ratio
+	case strings.Contains(key, query) || strings.Contains(query, key):
+		return 0.5 + 0.2*ratio
+	}
+	similarity := 1 - float64(levenshtein(q, k))/float64(max(len(q), len(k)))
+	if similarity < minFuzzySimilarity {
+		return 0
+	}
+	return 0.5 * similarity
+}
+
+// levenshtein は、2つの文字列の編集距離（挿入・削除・置換の回数）を返します。
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+// MenuVersionOutput は、メニューのある版の内容です。
+type MenuVersionOutput struct {
+	Version     int                   `json:"version"`
+	CreatedAt   string                `json:"created_at"`
+	Name        string                `json:"name"`
+	Servings    int                   `json:"servings"`
+	Steps       []string              `json:"steps"`
+	Tips        *string               `json:"tips"`
+	SourceURL   *string               `json:"source_url"`
+	Ingredients []*MenuIngredientInfo `json:"ingredients"`
+}
+
+// --- Usecase Implementation ---
+
+// GetMenuVersions は、指定されたメニューの版を新しい順にすべて取得します。メニューが存在しない場合は ErrMenuNotFound を返します。
+func (u *catalogUsecase) GetMenuVersions(ctx context.Context, menuID string) ([]*MenuVersionOutput, error) {
+	if _, err := u.menuRepo.FindMenuByID(ctx, menuID); err != nil {
+		return nil, notFound(err, ErrMenuNotFound)
+	}
+	versions, err := u.menuRepo.FindMenuVersions(ctx, menuID)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*MenuVersionOutput, len(versions))
+	for i, v := range versions {
+		output[i] = &MenuVersionOutput{
+			Version:     v.Version,
+			CreatedAt:   v.CreatedAt.Format(time.RFC3339),
+			Name:        v.Name,
+			Servings:    v.Servings,
+			Steps:       toVersionStepTexts(v.Steps),
+			Tips:        v.Tips,
+			SourceURL:   v.SourceURL,
+			Ingredients: toVersionIngredientInfo(v.Ingredients),
+		}
+	}
+	return output, nil
+}
+
+// ensureCurrentVersion は、メニューの現在の内容が最新の版と異なる場合（版がまだ無い場合を含む）に新しい版を作成し、
+// 現在の内容に対応する版を返します。返す版には材料と調理手順が含まれます。
+// menu の MenuIngredientItems（Ingredient を含む）、Steps、CurrentVersion は読み込み済みである必要があります。
+func ensureCurrentVersion(ctx context.Context, menuRepo repository.MenuRepository, menu *model.Menu) (*model.MenuVersion, error) {
+	version := model.NewMenuVersion(menu)
+	current := menu.CurrentVersion
+	if current != nil && current.ContentHash == version.ContentHash {
+		version.ID = current.ID
+		version.Version = current.Version
+		version.CreatedAt = current.CreatedAt
+		return version, nil
+	}
+
+	version.Version = 1
+	if current != nil {
+		version.Version = current.Version + 1
+	}
+	if err := menuRepo.CreateMenuVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	menu.CurrentVersionID = &version.ID
+	menu.CurrentVersion = version
+	return version, nil
+}
+
+func toVersionIngredientInfo(items []model.MenuVersionIngredient) []*MenuIngredientInfo {
+	info := make([]*MenuIngredientInfo, len(items))
+	for i, item := range items {
+		info[i] = &MenuIngredientInfo{
+			Name:   item.IngredientName,
+			Amount: item.Amount,
+			Unit:   item.Unit,
+		}
+	}
+	return info
+}
+
+func toVersionStepTexts(steps []model.MenuVersionStep) []string {
+	texts := make([]string, len(steps))
+	for i, step := range steps {
+		texts[i] = step.Instruction
+	}
+	return texts
+}
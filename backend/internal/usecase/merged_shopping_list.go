@@ -0,0 +1,354 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// MaxMergedPlans は、1回の買い物にまとめられる計画の最大数です。
+const MaxMergedPlans = 10
+
+// ErrInvalidMergedPlans は、まとめる計画の指定が不正な場合のエラーです。
+var ErrInvalidMergedPlans = newError(ErrInvalidRequest, "invalid_merged_plans", "まとめる計画の指定が不正です")
+
+// GetMergedIngredientListInput は、複数の計画の買い物リストをまとめて取得するための入力です。
+type GetMergedIngredientListInput struct {
+	PlanIDs  []string
+	LayoutID string // 省略した場合は食材分類ごとにまとめます
+}
+
+// MergedShoppingListOutput は、複数の計画の買い物リストを1回の買い物のためにまとめたリストです。
+// Lines にはすべての行を Sections と同じ順（店内を歩く順）で入れます。
+type MergedShoppingListOutput struct {
+	PlanIDs  []string                       `json:"plan_ids"`
+	LayoutID *string                        `json:"layout_id"`
+	Lines    []*MergedShoppingLineOutput    `json:"lines"`
+	Sections []*MergedShoppingSectionOutput `json:"sections"`
+}
+
+type MergedShoppingSectionOutput struct {
+	Name  string                      `json:"name"`
+	Lines []*MergedShoppingLineOutput `json:"lines"`
+}
+
+// MergedShoppingLineOutput は、同じ食材（自由入力の場合は同じ名前と単位）のアイテムを計画をまたいでまとめた1行です。
+type MergedShoppingLineOutput struct {
+	Key             string                        `json:"key"` // 行を識別するキー。購入済みにするときに指定する
+	Name            string                        `json:"name"`
+	Type            string                        `json:"type"` // 自由入力のアイテムは空文字
+	Amount          float64                       `json:"amount"`
+	Unit            string                        `json:"unit"`
+	Bought          bool                          `json:"bought"` // すべての計画のアイテムが購入済みかどうか
+	Note            *string                       `json:"note"`
+	PurchasedAmount *float64                      `json:"purchased_amount"`
+	RemainingAmount float64                       `json:"remaining_amount"`
+	ExcessAmount    float64                       `json:"excess_amount"`
+	Sources         []*MergedShoppingSourceOutput `json:"sources"` // 行に含まれる各計画のアイテム
+}
+
+// MergedShoppingSourceOutput は、まとめた行に含まれる、元の計画のアイテムです。
+type MergedShoppingSourceOutput struct {
+	PlanID          string   `json:"plan_id"`
+	ItemID          string   `json:"item_id"`
+	Amount          float64  `json:"amount"`
+	Bought          bool     `json:"bought"`
+	PurchasedAmount *float64 `json:"purchased_amount"`
+	Manual          bool     `json:"manual"`
+	Version         int      `json:"version"`
+	TripDate        string   `json:"trip_date"` // 元の計画でこのアイテムを買いに行く日
+}
+
+// CheckOffMergedLinesInput は、まとめたリストの行を購入済み（または未購入）にするための入力です。
+type CheckOffMergedLinesInput struct {
+	PlanIDs []string
+	Lines   []MergedLineCheck
+}
+
+type MergedLineCheck struct {
+	Key    string
+	Bought bool
+}
+
+// GetMergedIngredientList は、複数の計画の買い物リストを、同じ食材の数量を足し合わせた1つのリストにまとめます。
+// 各行には、どの計画のどのアイテムから来たものかを含めます。手動で追加したアイテムも同じようにまとめます。
+// 計画が存在しない場合は ErrPlanNotFound、店舗レイアウトが存在しない場合は ErrStoreLayoutNotFound を返します。
+func (u *planUsecase) GetMergedIngredientList(ctx context.Context, input GetMergedIngredientListInput) (*MergedShoppingListOutput, error) {
+	planIDs, err := normalizeMergedPlanIDs(input.PlanIDs)
+	if err != nil {
+		return nil, err
+	}
+	var layout *model.StoreLayout
+	if input.LayoutID != "" {
+		if layout, err = u.storeLayoutRepo.FindStoreLayoutByID(ctx, input.LayoutID); err != nil {
+			return nil, notFound(err, ErrStoreLayoutNotFound)
+		}
+	}
+	items, err := u.findMergedItems(ctx, planIDs)
+	if err != nil {
+		return nil, err
+	}
+	return mergeShoppingItems(planIDs, items, layout), nil
+}
+
+// CheckOffMergedLines は、まとめたリストの行を購入済み（または未購入）にし、行に含まれるすべての計画のアイテムに反映します。
+// 1つのトランザクションで更新し、それぞれの計画の版を1つ進めます。更新後の行を返します。
+// 計画が存在しない場合は ErrPlanNotFound を、どの計画にもない行を指定した場合は ErrInvalidShoppingItem を返します。
+func (u *planUsecase) CheckOffMergedLines(ctx context.Context, input CheckOffMergedLinesInput) ([]*MergedShoppingLineOutput, error) {
+	planIDs, err := normalizeMergedPlanIDs(input.PlanIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: 更新する行が指定されていません", ErrInvalidShoppingItem)
+	}
+	if len(input.Lines) > MaxShoppingItemBatchSize {
+		return nil, fmt.Errorf("%w: 一度に更新できる行は%d件までです", ErrInvalidShoppingItem, MaxShoppingItemBatchSize)
+	}
+
+	return retryOnVersionConflict(func() ([]*MergedShoppingLineOutput, error) {
+		items, err := u.findMergedItems(ctx, planIDs)
+		if err != nil {
+			return nil, err
+		}
+		byKey := make(map[string][]*model.ShoppingIngredientItem)
+		for _, item := range items {
+			key := mergedLineKey(item)
+			byKey[key] = append(byKey[key], item)
+		}
+
+		now := time.Now()
+		var updated []*model.ShoppingIngredientItem
+		changedPlans := make(map[string][]*model.ShoppingIngredientItem)
+		seen := make(map[string]bool, len(input.Lines))
+		for _, line := range input.Lines {
+			sources, ok := byKey[line.Key]
+			if !ok {
+				return nil, fmt.Errorf("%w: まとめた買い物リストにない行です: %s", ErrInvalidShoppingItem, line.Key)
+			}
+			if seen[line.Key] {
+				return nil, fmt.Errorf("%w: 同じ行が複数指定されています: %s", ErrInvalidShoppingItem, line.Key)
+			}
+			seen[line.Key] = true
+			for _, item := range sources {
+				if item.Bought == line.Bought {
+					continue
+				}
+				bought := line.Bought
+				if err := applyShoppingItemUpdate(item, UpdateShoppingIngredientItemInput{ItemID: item.ID, Bought: &bought}, now); err != nil {
+					return nil, err
+				}
+				updated = append(updated, item)
+				changedPlans[item.PlanID] = append(changedPlans[item.PlanID], item)
+			}
+		}
+
+		planVersions := make(map[string]int, len(changedPlans))
+		err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+			// 読み込んだ後に他の処理がアイテムを更新していた場合は repository.ErrVersionConflict となり、読み込みからやり直す
+			if err := txRepo.UpdateShoppingIngredientItemStates(ctx, updated); err != nil {
+				return err
+			}
+			for _, planID := range planIDs {
+				if _, ok := changedPlans[planID]; !ok {
+					continue
+				}
+				version, err := txRepo.IncrementShoppingPlanVersion(ctx, planID, 0)
+				if err != nil {
+					return err
+				}
+				planVersions[planID] = version
+			}
+			return nil
+		})
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("アイテムの更新に失敗しました: %w", err)
+		}
+
+		for _, planID := range planIDs {
+			changed, ok := changedPlans[planID]
+			if !ok {
+				continue
+			}
+			outputs := make([]*IngredientListOutput, len(changed))
+			for i, item := range changed {
+				outputs[i] = toSingleIngredientListOutput(item)
+			}
+			u.publish(planID, planVersions[planID], PlanEventItemsUpdated, outputs, nil)
+		}
+
+		merged := mergeShoppingItems(planIDs, items, nil)
+		lines := make(map[string]*MergedShoppingLineOutput, len(merged.Lines))
+		for _, line := range merged.Lines {
+			lines[line.Key] = line
+		}
+		output := make([]*MergedShoppingLineOutput, len(input.Lines))
+		for i, line := range input.Lines {
+			output[i] = lines[line.Key]
+		}
+		return output, nil
+	})
+}
+
+// normalizeMergedPlanIDs は、まとめる計画のIDの空白と重複を取り除き、数を確かめます。
+func normalizeMergedPlanIDs(planIDs []string) ([]string, error) {
+	var ids []string
+	seen := make(map[string]bool, len(planIDs))
+	for _, id := range planIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: 計画が指定されていません", ErrInvalidMergedPlans)
+	}
+	if len(ids) > MaxMergedPlans {
+		return nil, fmt.Errorf("%w: まとめられる計画は%d件までです", ErrInvalidMergedPlans, MaxMergedPlans)
+	}
+	return ids, nil
+}
+
+// findMergedItems は、計画の買い物リストのアイテムをすべて取得します。計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) findMergedItems(ctx context.Context, planIDs []string) ([]*model.ShoppingIngredientItem, error) {
+	var items []*model.ShoppingIngredientItem
+	for _, planID := range planIDs {
+		if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+			return nil, err
+		}
+		planItems, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, planID)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, planItems...)
+	}
+	return items, nil
+}
+
+// mergedLineKey は、アイテムをまとめる行のキーを返します。
+// カタログの食材は食材ごとに、自由入力のアイテムは正規化した名前と単位ごとにまとめます。
+func mergedLineKey(item *model.ShoppingIngredientItem) string {
+	if item.IngredientID != nil {
+		return "ingredient:" + *item.IngredientID
+	}
+	return "item:" + model.NormalizeIngredientName(item.DisplayName()) + "|" + item.DisplayUnit()
+}
+
+// mergeShoppingItems は、アイテムを行ごとにまとめ、groupShoppingList と同じ規則で売り場ごとに並べます。
+// 行は、数量と購入した量を足し合わせたアイテム（IDは行のキー）として並べ替えます。
+func mergeShoppingItems(planIDs []string, items []*model.ShoppingIngredientItem, layout *model.StoreLayout) *MergedShoppingListOutput {
+	type mergedLine struct {
+		item    *model.ShoppingIngredientItem
+		sources []*model.ShoppingIngredientItem
+		notes   []string
+	}
+	var keys []string
+	lines := make(map[string]*mergedLine)
+	for _, item := range items {
+		key := mergedLineKey(item)
+		line, ok := lines[key]
+		if !ok {
+			line = &mergedLine{item: &model.ShoppingIngredientItem{
+				BaseModel:    model.BaseModel{ID: key},
+				IngredientID: item.IngredientID,
+				Name:         item.Name,
+				Unit:         item.Unit,
+				Bought:       true,
+				Ingredient:   item.Ingredient,
+			}}
+			lines[key] = line
+			keys = append(keys, key)
+		}
+		line.sources = append(line.sources, item)
+		line.item.Amount = roundAmount(line.item.Amount + item.Amount)
+		line.item.Bought = line.item.Bought && item.Bought
+		if item.PurchasedAmount != nil || line.item.PurchasedAmount != nil {
+			purchased := 0.0
+			for _, source := range line.sources {
+				purchased += source.Purchased()
+			}
+			purchased = roundAmount(purchased)
+			line.item.PurchasedAmount = &purchased
+		}
+		if item.Note != nil && *item.Note != "" && !slices.Contains(line.notes, *item.Note) {
+			line.notes = append(line.notes, *item.Note)
+		}
+	}
+
+	merged := make([]*model.ShoppingIngredientItem, len(keys))
+	for i, key := range keys {
+		line := lines[key]
+		if len(line.notes) > 0 {
+			note := strings.Join(line.notes, " / ")
+			line.item.Note = &note
+		}
+		// 同じ計画の中では作成順、計画は指定された順に並べる
+		sort.SliceStable(line.sources, func(a, b int) bool {
+			return slices.Index(planIDs, line.sources[a].PlanID) < slices.Index(planIDs, line.sources[b].PlanID)
+		})
+		merged[i] = line.item
+	}
+
+	grouped := groupShoppingList(merged, layout)
+	output := &MergedShoppingListOutput{
+		PlanIDs:  planIDs,
+		LayoutID: grouped.LayoutID,
+		Lines:    []*MergedShoppingLineOutput{},
+		Sections: []*MergedShoppingSectionOutput{},
+	}
+	converted := make(map[string]*MergedShoppingLineOutput, len(keys))
+	for _, ing := range grouped.Ingredients {
+		line := toMergedShoppingLineOutput(ing, lines[ing.ID].sources)
+		converted[ing.ID] = line
+		output.Lines = append(output.Lines, line)
+	}
+	for _, section := range grouped.Sections {
+		s := &MergedShoppingSectionOutput{Name: section.Name}
+		for _, ing := range section.Ingredients {
+			s.Lines = append(s.Lines, converted[ing.ID])
+		}
+		output.Sections = append(output.Sections, s)
+	}
+	return output
+}
+
+func toMergedShoppingLineOutput(ing *IngredientListOutput, sources []*model.ShoppingIngredientItem) *MergedShoppingLineOutput {
+	line := &MergedShoppingLineOutput{
+		Key:             ing.ID,
+		Name:            ing.Name,
+		Type:            ing.Type,
+		Amount:          ing.Amount,
+		Unit:            ing.Unit,
+		Bought:          ing.Bought,
+		Note:            ing.Note,
+		PurchasedAmount: ing.PurchasedAmount,
+		RemainingAmount: ing.RemainingAmount,
+		ExcessAmount:    ing.ExcessAmount,
+		Sources:         make([]*MergedShoppingSourceOutput, len(sources)),
+	}
+	for i, source := range sources {
+		line.Sources[i] = &MergedShoppingSourceOutput{
+			PlanID:          source.PlanID,
+			ItemID:          source.ID,
+			Amount:          source.Amount,
+			Bought:          source.Bought,
+			PurchasedAmount: source.PurchasedAmount,
+			Manual:          source.Manual,
+			Version:         source.Version,
+			TripDate:        formatTripDate(source.TripDate),
+		}
+	}
+	return line
+}
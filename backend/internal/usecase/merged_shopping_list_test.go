@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+func strPtr(v string) *string { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+// catalogItem は、カタログの食材の買い物リストのアイテムを作成します。
+func catalogItem(id, planID string, ing *model.Ingredient, amount float64) *model.ShoppingIngredientItem {
+	return &model.ShoppingIngredientItem{
+		BaseModel:    model.BaseModel{ID: id},
+		PlanID:       planID,
+		IngredientID: &ing.ID,
+		Amount:       amount,
+		TripDate:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local),
+		Version:      1,
+		Ingredient:   *ing,
+	}
+}
+
+// manualItem は、自由入力の買い物リストのアイテムを作成します。
+func manualItem(id, planID, name string, unit *string, amount float64) *model.ShoppingIngredientItem {
+	return &model.ShoppingIngredientItem{
+		BaseModel: model.BaseModel{ID: id},
+		PlanID:    planID,
+		Name:      &name,
+		Unit:      unit,
+		Amount:    amount,
+		TripDate:  time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local),
+		Manual:    true,
+		Version:   1,
+	}
+}
+
+func TestMergedLineKey(t *testing.T) {
+	onion := &model.Ingredient{BaseModel: model.BaseModel{ID: "ing-onion"}, Name: "玉ねぎ", Unit: "個"}
+
+	tests := []struct {
+		name string
+		item *model.ShoppingIngredientItem
+		want string
+	}{
+		{name: "カタログの食材は食材のID", item: catalogItem("a", "p1", onion, 1), want: "ingredient:ing-onion"},
+		{name: "自由入力は名前と単位", item: manualItem("b", "p1", "牛乳", strPtr("本"), 1), want: "item:牛乳|本"},
+		{name: "カタカナはひらがなに揃える", item: manualItem("c", "p1", "トマト", strPtr("個"), 1), want: "item:とまと|個"},
+		{name: "半角カナと空白", item: manualItem("d", "p1", "ﾄﾏ ﾄ", strPtr("個"), 1), want: "item:とまと|個"},
+		{name: "英字は小文字に揃える", item: manualItem("e", "p1", "ＢＢＱソース", nil, 1), want: "item:bbqそーす|"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergedLineKey(tt.item); got != tt.want {
+				t.Errorf("mergedLineKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// mergedLineSummary は、比べやすいようにまとめた行の主な値を抜き出したものです。
+type mergedLineSummary struct {
+	Key             string
+	Name            string
+	Amount          float64
+	Bought          bool
+	Note            string
+	PurchasedAmount *float64
+	RemainingAmount float64
+	ExcessAmount    float64
+	Sources         []string // 計画のID/アイテムのID
+}
+
+func summarizeMergedLines(lines []*MergedShoppingLineOutput) []mergedLineSummary {
+	summaries := make([]mergedLineSummary, len(lines))
+	for i, line := range lines {
+		s := mergedLineSummary{
+			Key:             line.Key,
+			Name:            line.Name,
+			Amount:          line.Amount,
+			Bought:          line.Bought,
+			PurchasedAmount: line.PurchasedAmount,
+			RemainingAmount: line.RemainingAmount,
+			ExcessAmount:    line.ExcessAmount,
+		}
+		if line.Note != nil {
+			s.Note = *line.Note
+		}
+		for _, source := range line.Sources {
+			s.Sources = append(s.Sources, source.PlanID+"/"+source.ItemID)
+		}
+		summaries[i] = s
+	}
+	return summaries
+}
+
+func TestMergeShoppingItems(t *testing.T) {
+	vegetable := model.IngredientType{Name: "野菜"}
+	dairy := model.IngredientType{Name: "乳製品"}
+	onion := &model.Ingredient{BaseModel: model.BaseModel{ID: "ing-onion"}, Name: "玉ねぎ", Unit: "個", IngredientType: vegetable}
+	milk := &model.Ingredient{BaseModel: model.BaseModel{ID: "ing-milk"}, Name: "牛乳", Unit: "ml", IngredientType: dairy}
+
+	withNote := func(item *model.ShoppingIngredientItem, note string) *model.ShoppingIngredientItem {
+		item.Note = &note
+		return item
+	}
+	bought := func(item *model.ShoppingIngredientItem, purchased *float64) *model.ShoppingIngredientItem {
+		item.Bought, item.PurchasedAmount = true, purchased
+		return item
+	}
+
+	tests := []struct {
+		name         string
+		planIDs      []string
+		items        []*model.ShoppingIngredientItem
+		wantLines    []mergedLineSummary
+		wantSections map[string][]string // 売り場の名前ごとの行のキー
+	}{
+		{
+			name:    "同じ食材を計画をまたいで足し合わせ、元のアイテムは計画の指定順に並べる",
+			planIDs: []string{"p1", "p2"},
+			items: []*model.ShoppingIngredientItem{
+				withNote(catalogItem("b", "p2", onion, 1.5), "大きめ"),
+				withNote(catalogItem("a", "p1", onion, 2), "大きめ"),
+				withNote(catalogItem("c", "p2", onion, 0.25), "新玉ねぎ"),
+			},
+			wantLines: []mergedLineSummary{{
+				Key: "ingredient:ing-onion", Name: "玉ねぎ", Amount: 3.75, Note: "大きめ / 新玉ねぎ",
+				RemainingAmount: 3.75, Sources: []string{"p1/a", "p2/b", "p2/c"},
+			}},
+			wantSections: map[string][]string{"野菜": {"ingredient:ing-onion"}},
+		},
+		{
+			name:    "すべて購入済みの場合だけ購入済みにし、購入した量を足し合わせる",
+			planIDs: []string{"p1", "p2"},
+			items: []*model.ShoppingIngredientItem{
+				bought(catalogItem("a", "p1", milk, 200), floatPtr(1000)),
+				bought(catalogItem("b", "p2", milk, 300), nil),
+				catalogItem("c", "p1", onion, 1),
+				bought(catalogItem("d", "p2", onion, 2), nil),
+			},
+			wantLines: []mergedLineSummary{
+				{
+					Key: "ingredient:ing-milk", Name: "牛乳", Amount: 500, Bought: true,
+					PurchasedAmount: floatPtr(1300), ExcessAmount: 800, Sources: []string{"p1/a", "p2/b"},
+				},
+				{
+					Key: "ingredient:ing-onion", Name: "玉ねぎ", Amount: 3,
+					RemainingAmount: 3, Sources: []string{"p1/c", "p2/d"},
+				},
+			},
+			wantSections: map[string][]string{"乳製品": {"ingredient:ing-milk"}, "野菜": {"ingredient:ing-onion"}},
+		},
+		{
+			name:    "自由入力は正規化した名前と単位ごとにまとめ（単位はそのまま）、その他の売り場に入れる",
+			planIDs: []string{"p1", "p2"},
+			items: []*model.ShoppingIngredientItem{
+				manualItem("a", "p1", "トイレットペーパー", strPtr("パック"), 1),
+				manualItem("b", "p2", "といれっとぺーぱー", strPtr("パック"), 1),
+				manualItem("c", "p2", "トイレットペーパー", strPtr("ロール"), 4),
+				catalogItem("d", "p1", onion, 1),
+			},
+			wantLines: []mergedLineSummary{
+				{Key: "ingredient:ing-onion", Name: "玉ねぎ", Amount: 1, RemainingAmount: 1, Sources: []string{"p1/d"}},
+				{Key: "item:といれっとぺーぱー|パック", Name: "トイレットペーパー", Amount: 2, RemainingAmount: 2, Sources: []string{"p1/a", "p2/b"}},
+				{Key: "item:といれっとぺーぱー|ロール", Name: "トイレットペーパー", Amount: 4, RemainingAmount: 4, Sources: []string{"p2/c"}},
+			},
+			wantSections: map[string][]string{
+				"野菜":  {"ingredient:ing-onion"},
+				"その他": {"item:といれっとぺーぱー|パック", "item:といれっとぺーぱー|ロール"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := mergeShoppingItems(tt.planIDs, tt.items, nil)
+			if got := summarizeMergedLines(output.Lines); !reflect.DeepEqual(got, tt.wantLines) {
+				t.Errorf("lines =\n%+v\nwant\n%+v", got, tt.wantLines)
+			}
+
+			sections := make(map[string][]string, len(output.Sections))
+			for _, section := range output.Sections {
+				for _, line := range section.Lines {
+					sections[section.Name] = append(sections[section.Name], line.Key)
+				}
+			}
+			if !reflect.DeepEqual(sections, tt.wantSections) {
+				t.Errorf("sections = %v, want %v", sections, tt.wantSections)
+			}
+			if last := output.Sections[len(output.Sections)-1].Name; tt.wantSections[unassignedSectionName] != nil && last != unassignedSectionName {
+				t.Errorf("last section = %q, want %q", last, unassignedSectionName)
+			}
+		})
+	}
+}
+
+func TestNormalizeMergedPlanIDs(t *testing.T) {
+	tooMany := make([]string, MaxMergedPlans+1)
+	for i := range tooMany {
+		tooMany[i] = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		name    string
+		input   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "空白と重複を取り除く", input: []string{" p1 ", "p2", "p1", ""}, want: []string{"p1", "p2"}},
+		{name: "指定なし", input: []string{" ", ""}, wantErr: true},
+		{name: "多すぎる", input: tooMany, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeMergedPlanIDs(tt.input)
+			if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeMergedPlanIDs() = %v, %v, want %v (error %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// PlanEventType は、計画で起きた変更の種類です。
+type PlanEventType string
+
+const (
+	// PlanEventItemsUpdated は、買い物リストのアイテムの購入状況や数量が更新されたことを表します。
+	PlanEventItemsUpdated PlanEventType = "items.updated"
+	// PlanEventItemsAdded は、買い物リストにアイテムが追加されたことを表します。
+	PlanEventItemsAdded PlanEventType = "items.added"
+	// PlanEventItemsDeleted は、買い物リストからアイテムが削除されたことを表します。
+	PlanEventItemsDeleted PlanEventType = "items.deleted"
+	// PlanEventPlanDeleted は、計画が削除されたことを表します。このイベントの後、配信は終了します。
+	PlanEventPlanDeleted PlanEventType = "plan.deleted"
+	// PlanEventResync は、途中のイベントを再送できない、または順番どおりに配信できなかったため、計画を取得し直す必要があることを表します。
+	PlanEventResync PlanEventType = "resync"
+)
+
+const (
+	// planEventHistorySize は、再接続したクライアントに再送するために計画ごとに残しておくイベントの数です。
+	planEventHistorySize = 256
+	// planEventBufferSize は、購読者ごとに送信待ちにできるイベントの数です。超えた購読者は切断します（再接続すれば続きから受け取れます）。
+	planEventBufferSize = 64
+	// planEventRetention は、購読者のいない計画のイベントを残しておく期間です。
+	planEventRetention = time.Hour
+)
+
+// PlanEvent は、計画で起きた変更の通知です。
+// Seq はサーバー全体で単調に増える番号で、再接続したクライアントはこの番号以降のイベントを受け取れます。
+type PlanEvent struct {
+	Seq            uint64                  `json:"seq"`
+	Type           PlanEventType           `json:"type"`
+	PlanID         string                  `json:"plan_id,omitempty"`          // 共有リンクからの購読では空
+	PlanVersion    int                     `json:"plan_version,omitempty"`     // 変更後の計画の版
+	Items          []*IngredientListOutput `json:"items,omitempty"`            // 追加・更新したアイテム
+	DeletedItemIDs []string                `json:"deleted_item_ids,omitempty"` // 削除したアイテムのID
+	OccurredAt     time.Time               `json:"occurred_at"`
+}
+
+// PlanEventSubscription は、計画のイベントの購読です。
+type PlanEventSubscription struct {
+	// Missed は、購読を始める前に起きていて、再送するイベントです。
+	Missed []*PlanEvent
+	// Events は、購読を始めた後に起きたイベントを受け取るチャネルです。
+	// 送信が追いつかずに購読が打ち切られた場合は閉じられます。
+	Events <-chan *PlanEvent
+	// Cancel は購読をやめます。購読が不要になったら必ず呼び出してください。
+	Cancel func()
+}
+
+// PlanEventBroker は、計画の変更をプロセス内で購読者に配信します。
+// 複数のサーバーで動かす場合は、同じ計画の購読者が同じサーバーに接続している必要があります。
+type PlanEventBroker struct {
+	mu        sync.Mutex
+	seq       uint64
+	plans     map[string]*planEventStream
+	lastPrune time.Time
+}
+
+// planEventStream は、1つの計画のイベントの履歴と購読者です。
+type planEventStream struct {
+	history []*PlanEvent
+	// floor は、この番号以前のイベントは履歴から消えていて再送できないことを表します。
+	floor uint64
+	// version は、配信した計画の版のうち最も新しいものです。
+	version     int
+	subscribers map[chan *PlanEvent]struct{}
+	lastActive  time.Time
+}
+
+// NewPlanEventBroker は新しい PlanEventBroker のインスタンスを生成します。
+// イベントの番号は起動時刻から始めるため、サーバーを再起動しても以前の番号と重なりません。
+func NewPlanEventBroker() *PlanEventBroker {
+	now := time.Now()
+	return &PlanEventBroker{
+		seq:       uint64(now.UnixMilli()),
+		plans:     make(map[string]*planEventStream),
+		lastPrune: now,
+	}
+}
+
+// stream は計画のイベントの履歴を返します。無い場合は作成します。呼び出し側で mu をロックしている必要があります。
+func (b *PlanEventBroker) stream(planID string, now time.Time) *planEventStream {
+	s, ok := b.plans[planID]
+	if !ok {
+		s = &planEventStream{floor: b.seq, subscribers: make(map[chan *PlanEvent]struct{})}
+		b.plans[planID] = s
+	}
+	s.lastActive = now
+	return s
+}
+
+// Publish は、計画のイベントに番号を付けて履歴に残し、購読者に配信します。
+// 更新の処理はコミットした後に並行して通知するため、イベントが計画の版の順に届くとは限りません。
+// 配信済みの版より古い版のイベントは、変更を取りこぼさないよう PlanEventResync に置き換えて配信します。
+func (b *PlanEventBroker) Publish(event *PlanEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.prune(now)
+	s := b.stream(event.PlanID, now)
+	if event.PlanVersion > 0 {
+		if event.PlanVersion <= s.version {
+			event = &PlanEvent{Type: PlanEventResync, PlanID: event.PlanID, OccurredAt: event.OccurredAt}
+		} else {
+			s.version = event.PlanVersion
+		}
+	}
+	b.seq++
+	event.Seq = b.seq
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = now
+	}
+
+	s.history = append(s.history, event)
+	if over := len(s.history) - planEventHistorySize; over > 0 {
+		s.floor = s.history[over-1].Seq
+		s.history = append(s.history[:0:0], s.history[over:]...)
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 受け取りが追いつかない購読者は切断する。再接続すれば履歴から続きを受け取れる
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	if event.Type == PlanEventPlanDeleted {
+		// 削除した計画のイベントは再送しない（購読者は削除の通知を受け取って配信を終える）
+		delete(b.plans, event.PlanID)
+	}
+}
+
+// Subscribe は、計画のイベントの購読を始めます。
+// afterSeq に最後に受け取ったイベントの番号を指定すると、それより後のイベントを Missed で返します（0 の場合は再送しません）。
+// 履歴から消えていて再送できない場合は、Missed に PlanEventResync のイベントだけを入れて返します。
+func (b *PlanEventBroker) Subscribe(planID string, afterSeq uint64) *PlanEventSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := b.stream(planID, time.Now())
+	var missed []*PlanEvent
+	switch {
+	case afterSeq == 0:
+	case afterSeq < s.floor || afterSeq > b.seq:
+		missed = []*PlanEvent{{Seq: b.seq, Type: PlanEventResync, PlanID: planID, OccurredAt: time.Now()}}
+	default:
+		for _, event := range s.history {
+			if event.Seq > afterSeq {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan *PlanEvent, planEventBufferSize)
+	s.subscribers[ch] = struct{}{}
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := s.subscribers[ch]; ok {
+				delete(s.subscribers, ch)
+				close(ch)
+			}
+			s.lastActive = time.Now()
+		})
+	}
+	return &PlanEventSubscription{Missed: missed, Events: ch, Cancel: cancel}
+}
+
+// prune は、購読者がいなくなってから planEventRetention を過ぎた計画の履歴を消します。呼び出し側で mu をロックしている必要があります。
+func (b *PlanEventBroker) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < planEventRetention/4 {
+		return
+	}
+	b.lastPrune = now
+	for planID, s := range b.plans {
+		if len(s.subscribers) == 0 && now.Sub(s.lastActive) > planEventRetention {
+			delete(b.plans, planID)
+		}
+	}
+}
+
+// SubscribePlanEvents は、計画の変更の購読を始めます。計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) SubscribePlanEvents(ctx context.Context, planID string, afterSeq uint64) (*PlanEventSubscription, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
+	return u.events.Subscribe(planID, afterSeq), nil
+}
+
+// publish は、計画の変更を購読者に通知します。planVersion は変更後の計画の版です。
+func (u *planUsecase) publish(planID string, planVersion int, eventType PlanEventType, items []*IngredientListOutput, deletedItemIDs []string) {
+	u.events.Publish(&PlanEvent{Type: eventType, PlanID: planID, PlanVersion: planVersion, Items: items, DeletedItemIDs: deletedItemIDs})
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPlanEventBrokerOrdersByPlanVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int // 通知する順の計画の版（0 は版の無いイベント）
+		want     []string
+	}{
+		{name: "版の順", versions: []int{3, 4, 5}, want: []string{"items.updated:3", "items.updated:4", "items.updated:5"}},
+		{name: "古い版が後から届く", versions: []int{3, 5, 4}, want: []string{"items.updated:3", "items.updated:5", "resync:0"}},
+		{name: "同じ版", versions: []int{3, 3}, want: []string{"items.updated:3", "resync:0"}},
+		{name: "版の無いイベントはそのまま", versions: []int{5, 0}, want: []string{"items.updated:5", "items.updated:0"}},
+	}
+
+	summarize := func(event *PlanEvent) string { return fmt.Sprintf("%s:%d", event.Type, event.PlanVersion) }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewPlanEventBroker()
+			sub := broker.Subscribe("plan-1", 0)
+			defer sub.Cancel()
+
+			for _, version := range tt.versions {
+				broker.Publish(&PlanEvent{Type: PlanEventItemsUpdated, PlanID: "plan-1", PlanVersion: version})
+			}
+			var got []*PlanEvent
+			var summaries []string
+			for range tt.versions {
+				event := <-sub.Events
+				got = append(got, event)
+				summaries = append(summaries, summarize(event))
+			}
+			if !reflect.DeepEqual(summaries, tt.want) {
+				t.Errorf("events = %v, want %v", summaries, tt.want)
+			}
+
+			// 再接続したクライアントにも、配信したときと同じイベントを再送する
+			resub := broker.Subscribe("plan-1", got[0].Seq-1)
+			defer resub.Cancel()
+			if !reflect.DeepEqual(resub.Missed, got) {
+				t.Errorf("missed = %v, want the delivered events", resub.Missed)
+			}
+		})
+	}
+}
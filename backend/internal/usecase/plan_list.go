@@ -0,0 +1,240 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+const (
+	// DefaultPlanListLimit は、件数を指定せずに計画の一覧を取得した場合の件数です。
+	DefaultPlanListLimit = 20
+	// MaxPlanListLimit は、計画の一覧を一度に取得できる件数の上限です。
+	MaxPlanListLimit = 100
+)
+
+// 計画の一覧で絞り込むアーカイブの状態です。
+const (
+	PlanStatusActive   = "active"   // アーカイブしていない計画（既定）
+	PlanStatusArchived = "archived" // アーカイブした計画
+	PlanStatusAll      = "all"      // すべての計画
+)
+
+// ErrInvalidPlanQuery は、計画の一覧の取得条件（期間、アーカイブの状態、カーソル）が不正な場合のエラーです。
+var ErrInvalidPlanQuery = newError(ErrInvalidRequest, "invalid_plan_query", "計画の一覧の取得条件が不正です")
+
+// ListPlansInput は、計画の一覧を取得する条件です。
+type ListPlansInput struct {
+	From   string // 開始日がこの日以降の計画に絞り込みます（YYYY-MM-DD）
+	To     string // 開始日がこの日以前の計画に絞り込みます（YYYY-MM-DD）
+	Status string // PlanStatusActive、PlanStatusArchived、PlanStatusAll のいずれか。省略した場合は PlanStatusActive
+	Cursor string // 前のページの NextCursor。省略した場合は最初のページ
+	Limit  int    // 0 以下の場合は DefaultPlanListLimit 件
+}
+
+// PlanSummaryOutput は、計画のIDと、計画の情報・進み具合の集計です。
+type PlanSummaryOutput struct {
+	ID string `json:"id"`
+	PlanProgressOutput
+}
+
+// PlanProgressOutput は、計画の情報と、食事や買い物の進み具合の集計です。計画のIDは含みません。
+type PlanProgressOutput struct {
+	PeriodStartAt string     `json:"period_start_at"` // 計画の開始日（YYYY-MM-DD）
+	FirstMealDate *string    `json:"first_meal_date"` // 最初の食事の日（食事が無い場合は null）
+	LastMealDate  *string    `json:"last_meal_date"`  // 最後の食事の日（食事が無い場合は null）
+	Version       int        `json:"version"`
+	ArchivedAt    *time.Time `json:"archived_at"` // アーカイブした日時（アーカイブしていない場合は null）
+	MealCount     int        `json:"meal_count"`
+	ItemCount     int        `json:"item_count"`
+	BoughtCount   int        `json:"bought_count"`
+	Progress      float64    `json:"progress"` // 購入済みのアイテムの割合（0〜1、アイテムが無い場合は0）
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// PlanListOutput は、計画の一覧の1ページです。
+type PlanListOutput struct {
+	Plans      []*PlanSummaryOutput `json:"plans"`
+	NextCursor *string              `json:"next_cursor"` // 次のページのカーソル（最後のページでは null）
+}
+
+// PlanDetailOutput は、1つの計画の情報です。
+type PlanDetailOutput struct {
+	PlanSummaryOutput
+	Trips []string `json:"trips"` // 買いに行く日（YYYY-MM-DD）の早い順
+}
+
+// SharedPlanOutput は、共有リンクから見る計画の情報です。
+// 共有リンクの利用者が持ち主のAPIを呼び出せないよう、計画のIDは含みません。
+type SharedPlanOutput struct {
+	PlanProgressOutput
+	Trips []string `json:"trips"` // 買いに行く日（YYYY-MM-DD）の早い順
+}
+
+// ListPlans は、アーカイブの状態で絞り込んだ計画を開始日の新しい順（同じ日は作成日時の新しい順）に、最大 Limit 件ずつ返します。
+// 続きがある場合は NextCursor を ListPlansInput.Cursor に指定して次のページを取得します。
+// 期間、アーカイブの状態、カーソルの指定が不正な場合は ErrInvalidPlanQuery を返します。
+func (u *planUsecase) ListPlans(ctx context.Context, input ListPlansInput) (*PlanListOutput, error) {
+	limit := input.Limit
+	if limit <= 0 {
+		limit = DefaultPlanListLimit
+	}
+	limit = min(limit, MaxPlanListLimit)
+
+	// 次のページがあるかどうかを知るため、1件多く取得する
+	query := repository.ShoppingPlanQuery{Limit: limit + 1}
+	var err error
+	if query.StartFrom, err = parsePlanQueryDate("from", input.From); err != nil {
+		return nil, err
+	}
+	if query.StartTo, err = parsePlanQueryDate("to", input.To); err != nil {
+		return nil, err
+	}
+	if query.StartFrom != nil && query.StartTo != nil && query.StartFrom.After(*query.StartTo) {
+		return nil, fmt.Errorf("%w: from は to 以前の日を指定してください", ErrInvalidPlanQuery)
+	}
+	switch input.Status {
+	case "", PlanStatusActive:
+		archived := false
+		query.Archived = &archived
+	case PlanStatusArchived:
+		archived := true
+		query.Archived = &archived
+	case PlanStatusAll:
+	default:
+		return nil, fmt.Errorf("%w: status は %s、%s、%s のいずれかを指定してください", ErrInvalidPlanQuery, PlanStatusActive, PlanStatusArchived, PlanStatusAll)
+	}
+	if input.Cursor != "" {
+		if query.After, err = decodePlanCursor(input.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	plans, err := u.planRepo.FindShoppingPlans(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("計画の取得に失敗しました: %w", err)
+	}
+	output := &PlanListOutput{Plans: []*PlanSummaryOutput{}}
+	if len(plans) > limit {
+		plans = plans[:limit]
+		cursor := encodePlanCursor(plans[limit-1])
+		output.NextCursor = &cursor
+	}
+
+	planIDs := make([]string, len(plans))
+	for i, plan := range plans {
+		planIDs[i] = plan.ID
+	}
+	summaries, err := u.planRepo.SummarizeShoppingPlans(ctx, planIDs)
+	if err != nil {
+		return nil, fmt.Errorf("計画の集計に失敗しました: %w", err)
+	}
+	for _, plan := range plans {
+		output.Plans = append(output.Plans, toPlanSummaryOutput(plan, summaries[plan.ID]))
+	}
+	return output, nil
+}
+
+// GetPlan は、計画の情報と、食事や買い物の進み具合、買いに行く日を返します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) GetPlan(ctx context.Context, planID string) (*PlanDetailOutput, error) {
+	plan, err := findPlan(ctx, u.planRepo, planID)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := u.planRepo.SummarizeShoppingPlans(ctx, []string{planID})
+	if err != nil {
+		return nil, fmt.Errorf("計画の集計に失敗しました: %w", err)
+	}
+	items, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return &PlanDetailOutput{
+		PlanSummaryOutput: *toPlanSummaryOutput(plan, summaries[planID]),
+		Trips:             tripDates(items),
+	}, nil
+}
+
+// GetSharedPlan は、共有リンクから見る計画の情報を、GetPlan と同じ内容から計画のIDを除いて返します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) GetSharedPlan(ctx context.Context, planID string) (*SharedPlanOutput, error) {
+	detail, err := u.GetPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedPlanOutput{PlanProgressOutput: detail.PlanProgressOutput, Trips: detail.Trips}, nil
+}
+
+// parsePlanQueryDate は、計画の一覧の期間の指定（YYYY-MM-DD）を解析します。空の場合は nil です。
+func parsePlanQueryDate(name, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation(tripDateLayout, s, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s は YYYY-MM-DD の形式で指定してください", ErrInvalidPlanQuery, name)
+	}
+	return &date, nil
+}
+
+// encodePlanCursor は、計画の一覧でこの計画の次から取得するためのカーソルを返します。
+// カーソルは開始日、作成日時、IDを「|」でつないで base64url にしたもので、クライアントは中身に依存しません。
+func encodePlanCursor(plan *model.ShoppingPlan) string {
+	raw := strings.Join([]string{
+		formatTripDate(plan.PeriodStartAt),
+		plan.CreatedAt.Format(time.RFC3339Nano),
+		plan.ID,
+	}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePlanCursor は、encodePlanCursor で作成したカーソルを解析します。
+func decodePlanCursor(cursor string) (*repository.ShoppingPlanCursor, error) {
+	invalid := fmt.Errorf("%w: cursor が不正です", ErrInvalidPlanQuery)
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[2] == "" {
+		return nil, invalid
+	}
+	start, err := time.ParseInLocation(tripDateLayout, parts[0], time.Local)
+	if err != nil {
+		return nil, invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+	return &repository.ShoppingPlanCursor{PeriodStartAt: start, CreatedAt: createdAt, ID: parts[2]}, nil
+}
+
+func toPlanSummaryOutput(plan *model.ShoppingPlan, summary *repository.ShoppingPlanSummary) *PlanSummaryOutput {
+	output := &PlanSummaryOutput{
+		ID: plan.ID,
+		PlanProgressOutput: PlanProgressOutput{
+			PeriodStartAt: formatTripDate(plan.PeriodStartAt),
+			Version:       plan.Version,
+			ArchivedAt:    plan.ArchivedAt,
+			CreatedAt:     plan.CreatedAt,
+			UpdatedAt:     plan.UpdatedAt,
+		},
+	}
+	if summary != nil {
+		output.FirstMealDate = formatOptionalDate(summary.FirstMealDate)
+		output.LastMealDate = formatOptionalDate(summary.LastMealDate)
+		output.MealCount, output.ItemCount, output.BoughtCount = summary.MealCount, summary.ItemCount, summary.BoughtCount
+		if summary.ItemCount > 0 {
+			output.Progress = roundAmount(float64(summary.BoughtCount) / float64(summary.ItemCount))
+		}
+	}
+	return output
+}
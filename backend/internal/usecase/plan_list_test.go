@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+func TestPlanCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *model.ShoppingPlan
+	}{
+		{
+			name: "UUIDの計画",
+			plan: &model.ShoppingPlan{
+				BaseModel:     model.BaseModel{ID: "0b0e8c1a-3f7d-4a52-9a4e-2f1c6d8e9b10", CreatedAt: time.Date(2024, 4, 1, 9, 30, 15, 123456000, time.UTC)},
+				PeriodStartAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+		{
+			name: "作成日時のタイムゾーンと秒未満なし",
+			plan: &model.ShoppingPlan{
+				BaseModel:     model.BaseModel{ID: "plan-1", CreatedAt: time.Date(2023, 12, 31, 23, 59, 59, 0, time.FixedZone("JST", 9*60*60))},
+				PeriodStartAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePlanCursor(encodePlanCursor(tt.plan))
+			if err != nil {
+				t.Fatalf("decodePlanCursor() error = %v", err)
+			}
+			if got.ID != tt.plan.ID || !got.CreatedAt.Equal(tt.plan.CreatedAt) || !got.PeriodStartAt.Equal(tt.plan.PeriodStartAt) {
+				t.Errorf("decodePlanCursor() = %+v, want %s %v %v", got, tt.plan.ID, tt.plan.CreatedAt, tt.plan.PeriodStartAt)
+			}
+		})
+	}
+}
+
+func TestDecodePlanCursorInvalid(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "base64ではない", cursor: "not a cursor!"},
+		{name: "区切りが足りない", cursor: encode("2024-04-01|2024-04-01T09:30:15Z")},
+		{name: "区切りが多い", cursor: encode("2024-04-01|2024-04-01T09:30:15Z|p|x")},
+		{name: "IDが空", cursor: encode("2024-04-01|2024-04-01T09:30:15Z|")},
+		{name: "開始日の形式が不正", cursor: encode("2024/04/01|2024-04-01T09:30:15Z|p")},
+		{name: "作成日時の形式が不正", cursor: encode("2024-04-01|2024-04-01 09:30:15|p")},
+		{name: "空", cursor: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePlanCursor(tt.cursor)
+			if !errors.Is(err, ErrInvalidPlanQuery) {
+				t.Errorf("decodePlanCursor(%q) = %+v, %v, want ErrInvalidPlanQuery", tt.cursor, got, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// retentionBatchSize は、保存期間を過ぎた計画を一度に取得・削除・アーカイブする件数です。
+const retentionBatchSize = 500
+
+// ErrInvalidRetentionPolicy は、計画の保存期間の設定が不正な場合のエラーです。
+var ErrInvalidRetentionPolicy = newError(ErrValidation, "invalid_retention_policy", "計画の保存期間の設定が不正です")
+
+// DeletePlanInput は、計画を削除するための入力です。
+type DeletePlanInput struct {
+	PlanID          string
+	ExpectedVersion *int // 指定した場合は、計画の現在の版と一致するときだけ削除する
+}
+
+// SetPlanArchivedInput は、計画をアーカイブする（または解除する）ための入力です。
+type SetPlanArchivedInput struct {
+	PlanID   string
+	Archived bool
+}
+
+// RetentionPolicy は、古い計画を整理する条件です。日数は計画の開始日から数え、0 の場合はその整理を行いません。
+type RetentionPolicy struct {
+	ArchiveAfterDays int  // 開始日からこの日数が経った計画をアーカイブする
+	DeleteAfterDays  int  // 開始日からこの日数が経った計画を（アーカイブしたかどうかにかかわらず）削除する
+	DryRun           bool // true の場合は何も変更せず、対象の計画だけを返す
+}
+
+// RetentionOutput は、古い計画の整理の結果です。DryRun の場合は、整理の対象になる計画です。
+type RetentionOutput struct {
+	DryRun          bool     `json:"dry_run"`
+	ArchiveBefore   *string  `json:"archive_before"` // 開始日がこの日以前の計画をアーカイブした（アーカイブしない場合は null）
+	DeleteBefore    *string  `json:"delete_before"`  // 開始日がこの日以前の計画を削除した（削除しない場合は null）
+	ArchivedPlanIDs []string `json:"archived_plan_ids"`
+	DeletedPlanIDs  []string `json:"deleted_plan_ids"`
+}
+
+// DeletePlan は、計画を、献立、買い物リスト、記録したレシート、共有リンクとともに削除し、購読者に計画の削除を通知します。
+// input.ExpectedVersion が計画の現在の版と一致しない場合は ErrVersionConflict を返します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) DeletePlan(ctx context.Context, input DeletePlanInput) error {
+	expected := 0
+	if input.ExpectedVersion != nil {
+		expected = *input.ExpectedVersion
+	}
+	err := u.planRepo.DeleteShoppingPlan(ctx, input.PlanID, expected)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return fmt.Errorf("%w（指定された版: %d）", ErrVersionConflict, expected)
+	}
+	if err != nil {
+		return notFound(err, ErrPlanNotFound)
+	}
+	u.publish(input.PlanID, 0, PlanEventPlanDeleted, nil, nil)
+	return nil
+}
+
+// SetPlanArchived は、計画をアーカイブ（または解除）し、計画の情報を返します。アーカイブした計画は、計画の一覧に既定では表示しません。
+// すでにアーカイブした計画をアーカイブしても、アーカイブした日時は変わりません。計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) SetPlanArchived(ctx context.Context, input SetPlanArchivedInput) (*PlanDetailOutput, error) {
+	plan, err := findPlan(ctx, u.planRepo, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Archived != (plan.ArchivedAt != nil) {
+		var archivedAt *time.Time
+		if input.Archived {
+			now := time.Now()
+			archivedAt = &now
+		}
+		if _, err := u.planRepo.SetShoppingPlansArchivedAt(ctx, []string{plan.ID}, archivedAt); err != nil {
+			return nil, fmt.Errorf("計画のアーカイブに失敗しました: %w", err)
+		}
+	}
+	return u.GetPlan(ctx, plan.ID)
+}
+
+// ApplyRetention は、保存期間を過ぎた計画を削除し、アーカイブする期間を過ぎた計画をアーカイブします。
+// 削除を先に行うため、削除する計画をアーカイブすることはありません。policy.DryRun の場合は何も変更せず、対象の計画を返します。
+// 日数が負の場合は ErrInvalidRetentionPolicy を返します。
+func (u *planUsecase) ApplyRetention(ctx context.Context, policy RetentionPolicy) (*RetentionOutput, error) {
+	if policy.ArchiveAfterDays < 0 || policy.DeleteAfterDays < 0 {
+		return nil, fmt.Errorf("%w: 日数は0以上を指定してください", ErrInvalidRetentionPolicy)
+	}
+	today := time.Now()
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.Local)
+	output := &RetentionOutput{DryRun: policy.DryRun, ArchivedPlanIDs: []string{}, DeletedPlanIDs: []string{}}
+
+	deleted := make(map[string]bool)
+	if policy.DeleteAfterDays > 0 {
+		cutoff := today.AddDate(0, 0, -policy.DeleteAfterDays)
+		output.DeleteBefore = formatOptionalDate(&cutoff)
+		err := u.eachRetentionBatch(ctx, repository.ShoppingPlanQuery{StartTo: &cutoff}, func(planIDs []string) error {
+			if !policy.DryRun {
+				if _, err := u.planRepo.DeleteShoppingPlans(ctx, planIDs); err != nil {
+					return fmt.Errorf("計画の削除に失敗しました: %w", err)
+				}
+				for _, planID := range planIDs {
+					u.publish(planID, 0, PlanEventPlanDeleted, nil, nil)
+				}
+			}
+			for _, planID := range planIDs {
+				deleted[planID] = true
+			}
+			output.DeletedPlanIDs = append(output.DeletedPlanIDs, planIDs...)
+			return nil
+		})
+		if err != nil {
+			return output, err
+		}
+	}
+
+	if policy.ArchiveAfterDays > 0 {
+		cutoff := today.AddDate(0, 0, -policy.ArchiveAfterDays)
+		output.ArchiveBefore = formatOptionalDate(&cutoff)
+		archived := false
+		err := u.eachRetentionBatch(ctx, repository.ShoppingPlanQuery{StartTo: &cutoff, Archived: &archived}, func(planIDs []string) error {
+			// DryRun では削除の対象の計画も残っているため、アーカイブの対象から除く
+			var targets []string
+			for _, planID := range planIDs {
+				if !deleted[planID] {
+					targets = append(targets, planID)
+				}
+			}
+			if !policy.DryRun {
+				now := time.Now()
+				if _, err := u.planRepo.SetShoppingPlansArchivedAt(ctx, targets, &now); err != nil {
+					return fmt.Errorf("計画のアーカイブに失敗しました: %w", err)
+				}
+			}
+			output.ArchivedPlanIDs = append(output.ArchivedPlanIDs, targets...)
+			return nil
+		})
+		if err != nil {
+			return output, err
+		}
+	}
+	return output, nil
+}
+
+// eachRetentionBatch は、条件に合う計画のIDを retentionBatchSize 件ずつ fn に渡します。
+// fn が計画を削除・更新しても続きを取りこぼさないよう、前の回の最後の計画をカーソルにして取得します。
+func (u *planUsecase) eachRetentionBatch(ctx context.Context, query repository.ShoppingPlanQuery, fn func(planIDs []string) error) error {
+	query.Limit = retentionBatchSize
+	for {
+		plans, err := u.planRepo.FindShoppingPlans(ctx, query)
+		if err != nil {
+			return fmt.Errorf("計画の取得に失敗しました: %w", err)
+		}
+		if len(plans) == 0 {
+			return nil
+		}
+		planIDs := make([]string, len(plans))
+		for i, plan := range plans {
+			planIDs[i] = plan.ID
+		}
+		if err := fn(planIDs); err != nil {
+			return err
+		}
+		if len(plans) < retentionBatchSize {
+			return nil
+		}
+		last := plans[len(plans)-1]
+		query.After = &repository.ShoppingPlanCursor{PeriodStartAt: last.PeriodStartAt, CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,6 +13,16 @@ import (
 // --- DTO (Data Transfer Object) Definitions ---
 // UsecaseのInput/Outputとして使用する構造体。APIのI/Oに近しい形となる。
 
+type CreatePlanInput struct {
+	Meals []PlannedMealInput
+	// AvoidIngredients は、使いたくない食材の名前です。代用ルールで置き換えられないメニューは計画に使いません。
+	AvoidIngredients []string
+	// OnHandIngredients は、手持ちの食材の名前です。レシピの食材を代用ルールで手持ちの食材に置き換えられる場合は置き換えます。
+	OnHandIngredients []string
+	// TripDays は、買い物に行く日（計画の開始日からの日数）です。省略した場合は、食材を使う日と日持ちから決めます。
+	TripDays []int
+}
+
 type PlannedMealInput struct {
 	DateOffset int
 	MealPeriod string
@@ -21,13 +32,34 @@ type CreatePlanOutput struct {
 	ShoppingPlanID string                  `json:"shopping_plan_id"`
 	Meals          []*MenuOutput           `json:"meals"`
 	Ingredients    []*IngredientListOutput `json:"ingredients"`
+	Trips          []string                `json:"trips"` // 買い物に行く日（早い順）
 }
 
 type MenuOutput struct {
-	Date         string                `json:"date"`
-	MealPeriod   string                `json:"meal_period"`
-	MenuName     string                `json:"menu_name"`
-	Ingredients  []*MenuIngredientInfo `json:"ingredients"`
+	MealID              string                      `json:"meal_id"`
+	Date                string                      `json:"date"`
+	MealPeriod          string                      `json:"meal_period"`
+	MenuID              string                      `json:"menu_id"`
+	MenuVersion         int                         `json:"menu_version"` // 計画作成時のメニューの版。表示する内容はこの版のもの
+	MenuName            string                      `json:"menu_name"`
+	Servings            int                         `json:"servings"`
+	Steps               []string                    `json:"steps"`
+	Tips                *string                     `json:"tips"`
+	SourceURL           *string                     `json:"source_url"`
+	Ingredients         []*MenuIngredientInfo       `json:"ingredients"`
+	Substitutions       []*SubstitutionOutput       `json:"substitutions"`        // この食事で行った食材の代用。Ingredients には代用後の食材が入る
+	InSeason            bool                        `json:"in_season"`            // 食事の日付に旬を迎える食材を使うかどうか（旬バッジの表示用）
+	SeasonalIngredients []*SeasonalIngredientOutput `json:"seasonal_ingredients"` // 食事の日付に旬を迎える食材
+}
+
+type SubstitutionOutput struct {
+	From       string  `json:"from"`
+	FromAmount float64 `json:"from_amount"`
+	FromUnit   string  `json:"from_unit"`
+	To         string  `json:"to"`
+	ToAmount   float64 `json:"to_amount"`
+	ToUnit     string  `json:"to_unit"`
+	Reason     string  `json:"reason"`
 }
 
 type MenuIngredientInfo struct {
@@ -37,76 +69,205 @@ type MenuIngredientInfo struct {
 }
 
 type IngredientListOutput struct {
-	ID     string  `json:"id"`
-	Name   string  `json:"name"`
-	Type   string  `json:"type"`
-	Amount float64 `json:"amount"`
-	Unit   string  `json:"unit"`
-	Bought bool    `json:"bought"`
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Type    string  `json:"type"` // 自由入力のアイテムは空文字
+	Amount  float64 `json:"amount"`
+	Unit    string  `json:"unit"`
+	Bought  bool    `json:"bought"`
+	Manual  bool    `json:"manual"` // 手動で追加したアイテムかどうか
+	Note    *string `json:"note"`
+	Version int     `json:"version"` // 更新のたびに1つ進む版。更新時に If-Match（ETag）として指定する
+
+	TripDate     string  `json:"trip_date"`      // 買いに行く日（この日までに買う）
+	FirstUseDate *string `json:"first_use_date"` // 献立で最初に使う日（手動のアイテムは null）
+
+	PurchasedAmount *float64 `json:"purchased_amount"` // 実際に購入した量（未記録なら null）
+	RemainingAmount float64  `json:"remaining_amount"` // まだ買う必要がある量
+	ExcessAmount    float64  `json:"excess_amount"`    // 必要量を超えて購入した量（残りもの）
 }
 
+// UpdateShoppingIngredientItemInput は、買い物リストのアイテムの更新内容です。nil の項目は変更しません。
 type UpdateShoppingIngredientItemInput struct {
-	ItemID string
-	Bought bool
+	ItemID          string
+	Bought          *bool
+	Amount          *float64 // 必要量
+	PurchasedAmount *float64 // 実際に購入した量
+	ExpectedVersion *int     // 指定した場合は、アイテムの現在の版と一致するときだけ更新する
 }
 
 // --- Usecase Interface ---
 
 // PlanUsecase は、計画に関するビジネスロジックのインターフェースです。
 type PlanUsecase interface {
-	CreatePlan(ctx context.Context, input []PlannedMealInput) (*CreatePlanOutput, error)
+	CreatePlan(ctx context.Context, input CreatePlanInput) (*CreatePlanOutput, error)
 	GetMenuList(ctx context.Context, planID string) ([]*MenuOutput, error)
-	GetIngredientList(ctx context.Context, planID string) ([]*IngredientListOutput, error)
+	GetIngredientList(ctx context.Context, input GetIngredientListInput) (*ShoppingListOutput, error)
 	UpdateShoppingIngredientItem(ctx context.Context, input UpdateShoppingIngredientItemInput) (*IngredientListOutput, error)
+	AddShoppingItem(ctx context.Context, input AddShoppingItemInput) (*IngredientListOutput, error)
+	DeleteShoppingItem(ctx context.Context, itemID string) error
+	BatchUpdateShoppingItems(ctx context.Context, input BatchUpdateShoppingItemsInput) (*BatchUpdateShoppingItemsOutput, error)
+	GetLeftovers(ctx context.Context, planID string) ([]*LeftoverOutput, error)
+	SubscribePlanEvents(ctx context.Context, planID string, afterSeq uint64) (*PlanEventSubscription, error)
+	GetMergedIngredientList(ctx context.Context, input GetMergedIngredientListInput) (*MergedShoppingListOutput, error)
+	CheckOffMergedLines(ctx context.Context, input CheckOffMergedLinesInput) ([]*MergedShoppingLineOutput, error)
+	RecordReceipt(ctx context.Context, input RecordReceiptInput) (*ReceiptOutput, error)
+	GetReceipts(ctx context.Context, planID string) ([]*ReceiptOutput, error)
+	DeleteReceipt(ctx context.Context, planID, receiptID string) error
+	GetPlanSpend(ctx context.Context, planID string) (*PlanSpendOutput, error)
+	GetMonthlySpend(ctx context.Context, input GetMonthlySpendInput) ([]*MonthlySpendOutput, error)
+	ListPlans(ctx context.Context, input ListPlansInput) (*PlanListOutput, error)
+	GetPlan(ctx context.Context, planID string) (*PlanDetailOutput, error)
+	GetSharedPlan(ctx context.Context, planID string) (*SharedPlanOutput, error)
+	DeletePlan(ctx context.Context, input DeletePlanInput) error
+	SetPlanArchived(ctx context.Context, input SetPlanArchivedInput) (*PlanDetailOutput, error)
+	ApplyRetention(ctx context.Context, policy RetentionPolicy) (*RetentionOutput, error)
 }
 
 // --- Usecase Implementation ---
 
 // planUsecase は PlanUsecase インターフェースの実装です。
 type planUsecase struct {
-	planRepo       repository.PlanRepository
-	menuRepo       repository.MenuRepository
-	ingredientRepo repository.IngredientRepository
+	planRepo        repository.PlanRepository
+	menuRepo        repository.MenuRepository
+	ingredientRepo  repository.IngredientRepository
+	storeLayoutRepo repository.StoreLayoutRepository
+	events          *PlanEventBroker
 }
 
 // NewPlanUsecase は新しい planUsecase のインスタンスを生成します。
-func NewPlanUsecase(planRepo repository.PlanRepository, menuRepo repository.MenuRepository, ingredientRepo repository.IngredientRepository) PlanUsecase {
+func NewPlanUsecase(planRepo repository.PlanRepository, menuRepo repository.MenuRepository, ingredientRepo repository.IngredientRepository, storeLayoutRepo repository.StoreLayoutRepository, events *PlanEventBroker) PlanUsecase {
 	return &planUsecase{
-		planRepo:       planRepo,
-		menuRepo:       menuRepo,
-		ingredientRepo: ingredientRepo,
+		planRepo:        planRepo,
+		menuRepo:        menuRepo,
+		ingredientRepo:  ingredientRepo,
+		storeLayoutRepo: storeLayoutRepo,
+		events:          events,
 	}
 }
 
+const (
+	// menuPoolPerMeal は、計画を作るときに食事1回あたり取得する候補のメニューの数です。
+	// 候補の中から旬の度合いで選ぶため、食事の数より多めに取得します。
+	menuPoolPerMeal = 8
+	// maxMenuPool は、候補のメニューの数の上限です。避けたい食材で候補が足りない場合も、これより多くは取得しません。
+	maxMenuPool = 400
+)
+
 // CreatePlan は、新しい食事計画を作成する中心的なビジネスロジックです。
-func (u *planUsecase) CreatePlan(ctx context.Context, input []PlannedMealInput) (*CreatePlanOutput, error) {
-	mealCount := len(input)
+// 避けたい食材や手持ちの食材が指定された場合は、代用ルールに従ってレシピの食材を置き換えます。
+// 条件に合うメニューが食事の数より少ない場合は ErrInsufficientMenus を返します。
+func (u *planUsecase) CreatePlan(ctx context.Context, input CreatePlanInput) (*CreatePlanOutput, error) {
+	mealCount := len(input.Meals)
 	if mealCount == 0 {
-		return nil, fmt.Errorf("自炊する食事が指定されていません")
+		return nil, fmt.Errorf("%w: 自炊する食事が指定されていません", ErrValidation)
 	}
-	menus, err := u.menuRepo.FindRandomMenus(ctx, mealCount)
+	planner, err := u.newSubstitutionPlanner(ctx, input.AvoidIngredients, input.OnHandIngredients)
 	if err != nil {
-		return nil, fmt.Errorf("メニューの取得に失敗しました: %w", err)
+		return nil, err
 	}
-	if len(menus) < mealCount {
-		return nil, fmt.Errorf("十分な数のメニューが登録されていません")
+
+	// 旬の度合いで選びやすさを変えるため、食事の数より多めのメニューをランダムに取得して候補にする。
+	// 代用できずに除外されて候補が足りない場合は、上限まで取得する数を増やして取得し直す
+	var candidates []*seasonalCandidate
+	for poolSize := min(mealCount*menuPoolPerMeal, maxMenuPool); ; poolSize = min(poolSize*2, maxMenuPool) {
+		menus, err := u.menuRepo.FindRandomMenus(ctx, poolSize)
+		if err != nil {
+			return nil, fmt.Errorf("メニューの取得に失敗しました: %w", err)
+		}
+		candidates = candidates[:0]
+		for _, menu := range menus {
+			subs, ok := planner.adapt(menu)
+			if !ok {
+				continue
+			}
+			candidates = append(candidates, newSeasonalCandidate(menu, subs))
+		}
+		// 登録されているメニューをすべて取得した場合も、それ以上は増えない
+		if len(candidates) >= mealCount || len(menus) < poolSize || poolSize == maxMenuPool {
+			break
+		}
+	}
+	if len(candidates) < mealCount {
+		if planner.active() {
+			return nil, fmt.Errorf("%w: 避けたい食材を含まない（代用できる）メニューが足りません", ErrInsufficientMenus)
+		}
+		return nil, fmt.Errorf("%w: 十分な数のメニューが登録されていません", ErrInsufficientMenus)
+	}
+
+	now := time.Now()
+	dates := make([]time.Time, mealCount)
+	for i, mealInput := range input.Meals {
+		dates[i] = now.AddDate(0, 0, mealInput.DateOffset)
+	}
+	// 食事の日付に旬を迎えるメニューほど選ばれやすくする
+	picked := pickSeasonalMenus(candidates, dates)
+	menus := make([]*model.Menu, mealCount)
+	substitutions := make([]map[string]*plannedSubstitution, mealCount)
+	for i, c := range picked {
+		menus[i], substitutions[i] = c.menu, c.substitutions
 	}
 
-	shoppingListItems := make(map[string]*model.ShoppingIngredientItem)
 	// レスポンス生成用に、集計した食材の完全なモデル情報も保持
-	ingredientMap := make(map[string]*model.Ingredient) 
+	ingredientMap := make(map[string]*model.Ingredient)
 
-	for _, menu := range menus {
+	type mealIngredientUse struct {
+		ingredientID string
+		amount       float64
+		use          ingredientUse
+	}
+	var mealUses []mealIngredientUse
+	for i, menu := range menus {
 		for _, item := range menu.MenuIngredientItems {
-			ingredientMap[item.IngredientID] = &item.Ingredient
-			if existingItem, ok := shoppingListItems[item.IngredientID]; ok {
-				existingItem.Amount += item.Amount
-			} else {
-				shoppingListItems[item.IngredientID] = &model.ShoppingIngredientItem{
-					IngredientID: item.IngredientID,
-					Amount:       item.Amount,
-					Bought:       false,
-				}
+			// 代用した食材は、代用先の食材として集計する
+			ingredientID, ingredient, amount := item.IngredientID, &item.Ingredient, item.Amount
+			if sub, ok := substitutions[i][item.IngredientID]; ok {
+				ingredientID, ingredient, amount = sub.ToIngredientID, sub.to, sub.ToAmount
+			}
+			ingredientMap[ingredientID] = ingredient
+			mealUses = append(mealUses, mealIngredientUse{
+				ingredientID: ingredientID,
+				amount:       amount,
+				use:          ingredientUse{day: input.Meals[i].DateOffset, shelfLife: ingredient.ShelfLifeDaysUnopened},
+			})
+		}
+	}
+
+	// 日持ちしない食材は使う日に近い回の買い物で買うよう、食材と買いに行く日ごとに集計する
+	uses := make([]ingredientUse, len(mealUses))
+	for i, mu := range mealUses {
+		uses[i] = mu.use
+	}
+	lastDay := 0
+	for _, mealInput := range input.Meals {
+		lastDay = max(lastDay, mealInput.DateOffset)
+	}
+	tripDays, err := normalizeTripDays(input.TripDays, lastDay, uses)
+	if err != nil {
+		return nil, err
+	}
+	type tripItemKey struct {
+		ingredientID string
+		tripDay      int
+	}
+	shoppingListItems := make(map[tripItemKey]*model.ShoppingIngredientItem)
+	for _, mu := range mealUses {
+		tripDay := assignTrip(tripDays, mu.use)
+		useDate := now.AddDate(0, 0, mu.use.day)
+		key := tripItemKey{ingredientID: mu.ingredientID, tripDay: tripDay}
+		if existingItem, ok := shoppingListItems[key]; ok {
+			existingItem.Amount += mu.amount
+			if useDate.Before(*existingItem.FirstUseDate) {
+				existingItem.FirstUseDate = &useDate
+			}
+		} else {
+			ingredientID := mu.ingredientID
+			shoppingListItems[key] = &model.ShoppingIngredientItem{
+				IngredientID: &ingredientID,
+				Amount:       mu.amount,
+				TripDate:     now.AddDate(0, 0, tripDay),
+				FirstUseDate: &useDate,
+				Bought:       false,
 			}
 		}
 	}
@@ -117,30 +278,58 @@ func (u *planUsecase) CreatePlan(ctx context.Context, input []PlannedMealInput)
 
 	for _, item := range shoppingListItems {
 		// マップに保持したIngredientモデルを関連付ける
-		item.Ingredient = *ingredientMap[item.IngredientID]
+		item.Ingredient = *ingredientMap[*item.IngredientID]
 		newIngredients = append(newIngredients, item)
 	}
 
+	versions := make([]*model.MenuVersion, len(menus))
 	err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
-		newPlan = model.ShoppingPlan{PeriodStartAt: time.Now()}
-		if err := txRepo.CreateShoppingPlan(ctx, &newPlan); err != nil { return err }
+		// 計画には作成時点のメニューの内容を版として固定し、後からレシピが編集されても表示が変わらないようにする。
+		// 計画の保存に失敗した場合に版だけが残らないよう、同じトランザクション内で作成する
+		menuRepo := txRepo.MenuRepository()
+		for i, menu := range menus {
+			version, err := ensureCurrentVersion(ctx, menuRepo, menu)
+			if err != nil {
+				return fmt.Errorf("メニューの版の作成に失敗しました: %w", err)
+			}
+			versions[i] = version
+		}
+
+		newPlan = model.ShoppingPlan{PeriodStartAt: now}
+		if err := txRepo.CreateShoppingPlan(ctx, &newPlan); err != nil {
+			return err
+		}
 
-		for i, mealInput := range input {
+		for i, mealInput := range input.Meals {
+			// 代用の記録は、食事予定とともに保存される
+			var mealSubstitutions []model.PlanningMealSubstitution
+			for _, item := range menus[i].MenuIngredientItems {
+				if sub, ok := substitutions[i][item.IngredientID]; ok {
+					mealSubstitutions = append(mealSubstitutions, sub.PlanningMealSubstitution)
+				}
+			}
 			newMeals = append(newMeals, &model.PlanningMealItem{
-				PlanID: newPlan.ID,
-				MenuID: menus[i].ID,
-				Date: time.Now().AddDate(0, 0, mealInput.DateOffset),
-				MealPeriod: model.MealPeriod(mealInput.MealPeriod),
-				Menu: *menus[i],
+				PlanID:        newPlan.ID,
+				MenuID:        menus[i].ID,
+				MenuVersionID: &versions[i].ID,
+				Date:          dates[i],
+				MealPeriod:    model.MealPeriod(mealInput.MealPeriod),
+				Menu:          *menus[i],
+				MenuVersion:   versions[i],
+				Substitutions: mealSubstitutions,
 			})
 		}
-		if err := txRepo.CreatePlanningMealItems(ctx, newMeals); err != nil { return err }
+		if err := txRepo.CreatePlanningMealItems(ctx, newMeals); err != nil {
+			return err
+		}
 
 		for _, ing := range newIngredients {
 			ing.PlanID = newPlan.ID
 		}
-		if err := txRepo.CreateShoppingIngredientItems(ctx, newIngredients); err != nil { return err }
-		
+		if err := txRepo.CreateShoppingIngredientItems(ctx, newIngredients); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -151,100 +340,280 @@ func (u *planUsecase) CreatePlan(ctx context.Context, input []PlannedMealInput)
 	// DBから再取得せず、作成したモデルからレスポンスを生成
 	return &CreatePlanOutput{
 		ShoppingPlanID: newPlan.ID,
-		Meals:          toMenuOutput(newMeals),
-		Ingredients:    toIngredientListOutput(newIngredients),
+		Meals:          toMenuOutput(newMeals, ingredientMap),
+		Ingredients:    groupShoppingList(newIngredients, nil).Ingredients,
+		Trips:          tripDates(newIngredients),
 	}, nil
 }
 
-
-// GetMenuList は、指定された計画IDのメニューリストを取得します。
+// GetMenuList は、指定された計画IDのメニューリストを取得します。計画が存在しない場合は ErrPlanNotFound を返します。
 func (u *planUsecase) GetMenuList(ctx context.Context, planID string) ([]*MenuOutput, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
 	meals, err := u.planRepo.FindMealsByPlanID(ctx, planID)
 	if err != nil {
 		return nil, err
 	}
-	return toMenuOutput(meals), nil
-}
 
-// GetIngredientList は、指定された計画IDの買い物リストを取得します。
-func (u *planUsecase) GetIngredientList(ctx context.Context, planID string) ([]*IngredientListOutput, error) {
-	ingredients, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, planID)
+	// 旬の判定には、食材マスターの現在の旬の設定を使う
+	var ingredientIDs []string
+	for _, meal := range meals {
+		ingredientIDs = append(ingredientIDs, mealIngredientIDs(meal)...)
+	}
+	ingredients, err := u.ingredientRepo.FindIngredientsByIDs(ctx, ingredientIDs)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	ingredientMap := make(map[string]*model.Ingredient, len(ingredients))
+	for _, ing := range ingredients {
+		ingredientMap[ing.ID] = ing
 	}
-	return toIngredientListOutput(ingredients), nil
+	return toMenuOutput(meals, ingredientMap), nil
 }
 
-// UpdateShoppingIngredientItem は、買い物リストのアイテムの購入済み状態を更新します。
-func (u *planUsecase) UpdateShoppingIngredientItem(ctx context.Context, input UpdateShoppingIngredientItemInput) (*IngredientListOutput, error) {
-	// 1. 更新対象のアイテムを、レスポンスに必要な関連情報を含めて取得します。
-	//    repository側でIngredientとIngredientTypeがPreloadされています。
-	item, err := u.planRepo.FindShoppingIngredientItemByID(ctx, input.ItemID)
+// GetIngredientList は、指定された計画IDの買い物リストを、売り場ごとにまとめて店内を歩く順に取得します。
+// 店舗レイアウトが指定されていない場合は、食材分類ごとにまとめます。
+// 買いに行く日が指定された場合は、その日に買うアイテムだけを返します（形式が不正な場合は ErrInvalidTripDate）。
+// 計画が存在しない場合は ErrPlanNotFound、店舗レイアウトが存在しない場合は ErrStoreLayoutNotFound を返します。
+func (u *planUsecase) GetIngredientList(ctx context.Context, input GetIngredientListInput) (*ShoppingListOutput, error) {
+	var tripDate *time.Time
+	if input.TripDate != "" {
+		date, err := parseTripDate(input.TripDate)
+		if err != nil {
+			return nil, err
+		}
+		tripDate = &date
+	}
+	var layout *model.StoreLayout
+	if input.LayoutID != "" {
+		l, err := u.storeLayoutRepo.FindStoreLayoutByID(ctx, input.LayoutID)
+		if err != nil {
+			return nil, notFound(err, ErrStoreLayoutNotFound)
+		}
+		layout = l
+	}
+	plan, err := findPlan(ctx, u.planRepo, input.PlanID)
 	if err != nil {
-		// このエラーはhandlerで gorm.ErrRecordNotFound として扱われます
 		return nil, err
 	}
+	ingredients, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	trips := tripDates(ingredients)
+	if tripDate != nil {
+		var onTrip []*model.ShoppingIngredientItem
+		for _, ing := range ingredients {
+			if sameTrip(ing, *tripDate) {
+				onTrip = append(onTrip, ing)
+			}
+		}
+		ingredients = onTrip
+	}
+	output := groupShoppingList(ingredients, layout)
+	output.Version = plan.Version
+	output.Trips = trips
+	if tripDate != nil {
+		s := formatTripDate(*tripDate)
+		output.TripDate = &s
+	}
+	return output, nil
+}
 
-	// 2. 状態を更新します。
-	item.Bought = input.Bought
+// UpdateShoppingIngredientItem は、買い物リストのアイテムの購入済み状態、必要量、購入した量を更新します。
+// input.ExpectedVersion がアイテムの現在の版と一致しない場合は ErrVersionConflict を、アイテムが存在しない場合は ErrShoppingItemNotFound を返します。
+func (u *planUsecase) UpdateShoppingIngredientItem(ctx context.Context, input UpdateShoppingIngredientItemInput) (*IngredientListOutput, error) {
+	return retryOnVersionConflict(func() (*IngredientListOutput, error) {
+		// 1. 更新対象のアイテムを、レスポンスに必要な関連情報を含めて取得します。
+		//    repository側でIngredientとIngredientTypeがPreloadされています。
+		item, err := u.planRepo.FindShoppingIngredientItemByID(ctx, input.ItemID)
+		if err != nil {
+			return nil, notFound(err, ErrShoppingItemNotFound)
+		}
+		if err := checkVersion(input.ExpectedVersion, item.Version); err != nil {
+			return nil, err
+		}
 
-	// 3. データベースに保存します。
-	if err := u.planRepo.UpdateShoppingIngredientItem(ctx, item); err != nil {
-		return nil, fmt.Errorf("アイテムの更新に失敗しました: %w", err)
-	}
+		// 2. 状態を更新します。
+		if err := applyShoppingItemUpdate(item, input, time.Now()); err != nil {
+			return nil, err
+		}
 
-	// 4. 再取得は不要。更新したモデルオブジェクトを直接DTOに変換して返します。
-	//    これにより、不要なDBアクセスがなくなり、ロジックもシンプルになります。
-	return toSingleIngredientListOutput(item), nil
-}
+		// 3. データベースに保存します。変更した列だけを、読み込んだときの版のままの場合に更新し、計画の版も進めます。
+		//    他の処理が先に更新していた場合は repository.ErrVersionConflict となり、読み込みからやり直します。
+		var planVersion int
+		err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+			if err := txRepo.UpdateShoppingIngredientItem(ctx, item); err != nil {
+				return err
+			}
+			planVersion, err = txRepo.IncrementShoppingPlanVersion(ctx, item.PlanID, 0)
+			return err
+		})
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return nil, err
+		}
+		if errors.Is(err, repository.ErrNotFound) {
+			// 読み込んだ後に他の処理がアイテムを削除していた
+			return nil, ErrShoppingItemNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("アイテムの更新に失敗しました: %w", err)
+		}
 
+		// 4. 再取得は不要。更新したモデルオブジェクトを直接DTOに変換して返します。
+		//    これにより、不要なDBアクセスがなくなり、ロジックもシンプルになります。
+		output := toSingleIngredientListOutput(item)
+
+		// 5. 同じ計画を見ている他の利用者に通知します。
+		u.publish(item.PlanID, planVersion, PlanEventItemsUpdated, []*IngredientListOutput{output}, nil)
+		return output, nil
+	})
+}
 
 // --- DTO Converters ---
 // ドメインモデルからOutput用のDTOへ変換するヘルパー関数
 
-func toMenuOutput(meals []*model.PlanningMealItem) []*MenuOutput {
+// toMenuOutput は、食事予定を出力用のDTOに変換します。
+// ingredients は旬の判定に使う食材（IDがキー）で、食事で使う食材が含まれている必要があります。
+func toMenuOutput(meals []*model.PlanningMealItem, ingredients map[string]*model.Ingredient) []*MenuOutput {
 	output := make([]*MenuOutput, len(meals))
 	for i, meal := range meals {
-		ingredientsInfo := make([]*MenuIngredientInfo, len(meal.Menu.MenuIngredientItems))
-		for j, item := range meal.Menu.MenuIngredientItems {
-			ingredientsInfo[j] = &MenuIngredientInfo{
-				Name:   item.Ingredient.Name,
-				Amount: item.Amount,
-				Unit:   item.Ingredient.Unit,
+		var used []*model.Ingredient
+		for _, id := range mealIngredientIDs(meal) {
+			if ing, ok := ingredients[id]; ok {
+				used = append(used, ing)
+			}
+		}
+		seasonal := seasonalIngredients(used, meal.Date.Month())
+
+		if v := meal.MenuVersion; v != nil {
+			ingredientsInfo, substitutions := applySubstitutions(toVersionIngredientInfo(v.Ingredients), meal.Substitutions)
+			output[i] = &MenuOutput{
+				MealID:              meal.ID,
+				Date:                meal.Date.Format("2006-01-02"),
+				MealPeriod:          string(meal.MealPeriod),
+				MenuID:              meal.MenuID,
+				MenuVersion:         v.Version,
+				MenuName:            v.Name,
+				Servings:            v.Servings,
+				Steps:               toVersionStepTexts(v.Steps),
+				Tips:                v.Tips,
+				SourceURL:           v.SourceURL,
+				Ingredients:         ingredientsInfo,
+				Substitutions:       substitutions,
+				InSeason:            len(seasonal) > 0,
+				SeasonalIngredients: seasonal,
 			}
+			continue
 		}
+
+		// 版を持たない計画は、現在のメニューの内容で表示する
+		ingredientsInfo := toMenuIngredientInfo(meal.Menu.MenuIngredientItems)
 		output[i] = &MenuOutput{
-			Date:         meal.Date.Format("2006-01-02"),
-			MealPeriod:   string(meal.MealPeriod),
-			MenuName:     meal.Menu.Name,
-			Ingredients:  ingredientsInfo,
+			MealID:              meal.ID,
+			Date:                meal.Date.Format("2006-01-02"),
+			MealPeriod:          string(meal.MealPeriod),
+			MenuID:              meal.Menu.ID,
+			MenuName:            meal.Menu.Name,
+			Servings:            meal.Menu.Servings,
+			Steps:               toStepTexts(meal.Menu.Steps),
+			Tips:                meal.Menu.Tips,
+			SourceURL:           meal.Menu.SourceURL,
+			Ingredients:         ingredientsInfo,
+			Substitutions:       []*SubstitutionOutput{},
+			InSeason:            len(seasonal) > 0,
+			SeasonalIngredients: seasonal,
 		}
 	}
 	return output
 }
 
-func toIngredientListOutput(ingredients []*model.ShoppingIngredientItem) []*IngredientListOutput {
-	output := make([]*IngredientListOutput, len(ingredients))
-	for i, ing := range ingredients {
-		output[i] = &IngredientListOutput{
-			ID:     ing.ID,
-			Name:   ing.Ingredient.Name,
-			Type:   ing.Ingredient.IngredientType.Name,
-			Amount: ing.Amount,
-			Unit:   ing.Ingredient.Unit,
-			Bought: ing.Bought,
+// mealIngredientIDs は、食事で使う（代用後の）食材のIDを返します。
+func mealIngredientIDs(meal *model.PlanningMealItem) []string {
+	substituted := make(map[string]string, len(meal.Substitutions))
+	for _, sub := range meal.Substitutions {
+		substituted[sub.FromIngredientID] = sub.ToIngredientID
+	}
+	var ids []string
+	add := func(id string) {
+		if to, ok := substituted[id]; ok {
+			id = to
 		}
+		ids = append(ids, id)
 	}
-	return output
+	if v := meal.MenuVersion; v != nil {
+		for _, ing := range v.Ingredients {
+			add(ing.IngredientID)
+		}
+	} else {
+		for _, item := range meal.Menu.MenuIngredientItems {
+			add(item.IngredientID)
+		}
+	}
+	return ids
+}
+
+// applySubstitutions は、食事で行った代用をレシピの材料に反映し、代用の一覧とともに返します。
+func applySubstitutions(info []*MenuIngredientInfo, substitutions []model.PlanningMealSubstitution) ([]*MenuIngredientInfo, []*SubstitutionOutput) {
+	output := make([]*SubstitutionOutput, len(substitutions))
+	byName := make(map[string]*model.PlanningMealSubstitution, len(substitutions))
+	for i := range substitutions {
+		sub := &substitutions[i]
+		byName[sub.FromName] = sub
+		output[i] = &SubstitutionOutput{
+			From:       sub.FromName,
+			FromAmount: sub.FromAmount,
+			FromUnit:   sub.FromUnit,
+			To:         sub.ToName,
+			ToAmount:   sub.ToAmount,
+			ToUnit:     sub.ToUnit,
+			Reason:     string(sub.Reason),
+		}
+	}
+	for _, ing := range info {
+		if sub, ok := byName[ing.Name]; ok {
+			ing.Name, ing.Amount, ing.Unit = sub.ToName, sub.ToAmount, sub.ToUnit
+		}
+	}
+	return info, output
+}
+
+func toMenuIngredientInfo(items []model.MenuIngredientItem) []*MenuIngredientInfo {
+	info := make([]*MenuIngredientInfo, len(items))
+	for i, item := range items {
+		info[i] = &MenuIngredientInfo{
+			Name:   item.Ingredient.Name,
+			Amount: item.Amount,
+			Unit:   item.Ingredient.Unit,
+		}
+	}
+	return info
+}
+
+func toStepTexts(steps []model.MenuStep) []string {
+	texts := make([]string, len(steps))
+	for i, step := range steps {
+		texts[i] = step.Instruction
+	}
+	return texts
 }
 
 func toSingleIngredientListOutput(ing *model.ShoppingIngredientItem) *IngredientListOutput {
-    return &IngredientListOutput{
-        ID:     ing.ID,
-        Name:   ing.Ingredient.Name,
-        Type:   ing.Ingredient.IngredientType.Name,
-        Amount: ing.Amount,
-        Unit:   ing.Ingredient.Unit,
-        Bought: ing.Bought,
-    }
-}
\ No newline at end of file
+	return &IngredientListOutput{
+		ID:              ing.ID,
+		Name:            ing.DisplayName(),
+		Type:            ing.Ingredient.IngredientType.Name,
+		Amount:          ing.Amount,
+		Unit:            ing.DisplayUnit(),
+		Bought:          ing.Bought,
+		Manual:          ing.Manual,
+		Note:            ing.Note,
+		PurchasedAmount: ing.PurchasedAmount,
+		RemainingAmount: roundAmount(ing.Remaining()),
+		ExcessAmount:    roundAmount(ing.Excess()),
+		Version:         ing.Version,
+		TripDate:        formatTripDate(ing.TripDate),
+		FirstUseDate:    formatOptionalDate(ing.FirstUseDate),
+	}
+}
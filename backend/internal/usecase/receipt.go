@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// MaxReceiptLines は、1枚のレシートに記録できる行の数の上限です。
+const MaxReceiptLines = 200
+
+// ErrInvalidReceipt は、記録するレシートの内容が不正な場合のエラーです。
+var ErrInvalidReceipt = newError(ErrValidation, "invalid_receipt", "レシートの内容が不正です")
+
+// RecordReceiptInput は、計画に実際の買い物（レシート）を記録するための入力です。
+type RecordReceiptInput struct {
+	PlanID      string
+	StoreName   string
+	PurchasedAt *time.Time // 省略した場合は現在時刻
+	Lines       []ReceiptLineInput
+	// Text は、レシートから貼り付けたテキストです。解析した品物を Lines の後に加えます。
+	Text string
+}
+
+// ReceiptLineInput は、レシートの1行（購入した品物と支払った金額）の入力です。
+type ReceiptLineInput struct {
+	Name     string
+	Quantity *float64 // 購入した量。単位が食材の単位と同じ（または省略した）場合は、買い物リストのアイテムの購入した量に加えます
+	Unit     string
+	Price    int    // 支払った金額（円、値引き後）
+	ItemID   string // 購入済みにする買い物リストのアイテム。省略した場合は品名から探します
+}
+
+type ReceiptOutput struct {
+	ID          string               `json:"id"`
+	PlanID      string               `json:"plan_id"`
+	StoreName   *string              `json:"store_name"`
+	PurchasedAt time.Time            `json:"purchased_at"`
+	Total       int                  `json:"total"`
+	Lines       []*ReceiptLineOutput `json:"lines"`
+	CreatedAt   time.Time            `json:"created_at"`
+
+	BoughtItems   []*IngredientListOutput `json:"bought_items,omitempty"`   // 記録したときに購入済みにした買い物リストのアイテム
+	UnparsedLines []string                `json:"unparsed_lines,omitempty"` // 貼り付けたテキストのうち、品物として読み取れなかった行
+}
+
+type ReceiptLineOutput struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	IngredientID   *string  `json:"ingredient_id"`
+	IngredientName *string  `json:"ingredient_name"`
+	ShoppingItemID *string  `json:"shopping_item_id"`
+	Quantity       *float64 `json:"quantity"`
+	Unit           *string  `json:"unit"`
+	Price          int      `json:"price"`
+	UnitPrice      *float64 `json:"unit_price"` // 食材の単位あたりの価格（量が食材の単位で分からない場合は null）
+}
+
+// RecordReceipt は、計画に実際の買い物（レシート）を記録し、対応する買い物リストのアイテムを購入済みにします。
+// 各行は、ItemID が指定されていればそのアイテムに、なければ品名が食材名（別名・表記ゆれ、品名に含まれる場合を含む）または
+// 自由入力のアイテム名に一致する未購入のアイテム（買いに行く日が早いもの）に対応付けます。対応するアイテムが無い行も記録します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) RecordReceipt(ctx context.Context, input RecordReceiptInput) (*ReceiptOutput, error) {
+	lines := input.Lines
+	var unparsed []string
+	if strings.TrimSpace(input.Text) != "" {
+		parsed, rest := parseReceiptText(input.Text)
+		lines, unparsed = append(lines, parsed...), rest
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: 品物が指定されていません", ErrInvalidReceipt)
+	}
+	if len(lines) > MaxReceiptLines {
+		return nil, fmt.Errorf("%w: 一度に記録できる品物は%d件までです", ErrInvalidReceipt, MaxReceiptLines)
+	}
+	for i, line := range lines {
+		switch {
+		case strings.TrimSpace(line.Name) == "":
+			return nil, fmt.Errorf("%w: %d行目の品名が指定されていません", ErrInvalidReceipt, i+1)
+		case line.Price < 0:
+			return nil, fmt.Errorf("%w: %d行目の金額は0以上の値を指定してください", ErrInvalidReceipt, i+1)
+		case line.Quantity != nil && *line.Quantity <= 0:
+			return nil, fmt.Errorf("%w: %d行目の量は0より大きい値を指定してください", ErrInvalidReceipt, i+1)
+		}
+	}
+	purchasedAt := time.Now()
+	if input.PurchasedAt != nil {
+		purchasedAt = *input.PurchasedAt
+	}
+
+	if _, err := findPlan(ctx, u.planRepo, input.PlanID); err != nil {
+		return nil, err
+	}
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	resolver := newIngredientResolver(ingredients)
+
+	return retryOnVersionConflict(func() (*ReceiptOutput, error) {
+		items, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, input.PlanID)
+		if err != nil {
+			return nil, err
+		}
+
+		receipt := &model.Receipt{PlanID: input.PlanID, PurchasedAt: purchasedAt}
+		if name := strings.TrimSpace(input.StoreName); name != "" {
+			receipt.StoreName = &name
+		}
+		var bought []*model.ShoppingIngredientItem
+		for i, line := range lines {
+			name := strings.TrimSpace(line.Name)
+			ing := resolver.resolveContained(name)
+			item, err := matchReceiptItem(items, line.ItemID, ing, name)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %d行目: %w", ErrInvalidReceipt, i+1, err)
+			}
+
+			receiptLine := model.ReceiptLine{Position: i, Name: name, Quantity: line.Quantity, Price: line.Price, Ingredient: ing}
+			if unit := strings.TrimSpace(line.Unit); unit != "" {
+				receiptLine.Unit = &unit
+			}
+			if item != nil {
+				if err := applyReceiptLine(item, line, purchasedAt); err != nil {
+					return nil, fmt.Errorf("%w: %d行目: %w", ErrInvalidReceipt, i+1, err)
+				}
+				receiptLine.ShoppingItemID = &item.ID
+				if item.IngredientID != nil {
+					// アイテムを指定した場合は、品名より指定したアイテムの食材を優先する
+					receiptLine.Ingredient = &item.Ingredient
+				}
+				if !slices.Contains(bought, item) {
+					bought = append(bought, item)
+				}
+			}
+			if receiptLine.Ingredient != nil {
+				receiptLine.IngredientID = &receiptLine.Ingredient.ID
+			}
+			receipt.Total += line.Price
+			receipt.Lines = append(receipt.Lines, receiptLine)
+		}
+
+		var planVersion int
+		err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+			if err := txRepo.CreateReceipt(ctx, receipt); err != nil {
+				return fmt.Errorf("レシートの保存に失敗しました: %w", err)
+			}
+			if len(bought) == 0 {
+				return nil
+			}
+			// 他の処理が先にアイテムを更新していた場合は repository.ErrVersionConflict となり、読み込みからやり直す
+			if err := txRepo.UpdateShoppingIngredientItemStates(ctx, bought); err != nil {
+				return err
+			}
+			planVersion, err = txRepo.IncrementShoppingPlanVersion(ctx, input.PlanID, 0)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		output := toReceiptOutput(receipt)
+		output.UnparsedLines = unparsed
+		for _, item := range bought {
+			output.BoughtItems = append(output.BoughtItems, toSingleIngredientListOutput(item))
+		}
+		if len(bought) > 0 {
+			u.publish(input.PlanID, planVersion, PlanEventItemsUpdated, output.BoughtItems, nil)
+		}
+		return output, nil
+	})
+}
+
+// GetReceipts は、計画に記録したレシートを買い物をした日時の順に取得します。計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) GetReceipts(ctx context.Context, planID string) ([]*ReceiptOutput, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
+	receipts, err := u.planRepo.FindReceiptsByPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	outputs := make([]*ReceiptOutput, len(receipts))
+	for i, receipt := range receipts {
+		outputs[i] = toReceiptOutput(receipt)
+	}
+	return outputs, nil
+}
+
+// DeleteReceipt は、計画に記録したレシートを削除します。購入済みにしたアイテムは元に戻しません。
+// 計画またはレシートが存在しない場合は ErrReceiptNotFound を返します。
+func (u *planUsecase) DeleteReceipt(ctx context.Context, planID, receiptID string) error {
+	return notFound(u.planRepo.DeleteReceipt(ctx, planID, receiptID), ErrReceiptNotFound)
+}
+
+// matchReceiptItem は、レシートの行に対応する買い物リストのアイテムを返します。対応するアイテムが無い場合は nil です。
+// itemID を指定した場合はそのアイテム（計画の買い物リストにない場合はエラー）、指定しない場合は、
+// 食材 ing（品名が食材に一致しない場合は品名に一致する自由入力のアイテム）の未購入のアイテムのうち、買いに行く日が最も早いものです。
+func matchReceiptItem(items []*model.ShoppingIngredientItem, itemID string, ing *model.Ingredient, name string) (*model.ShoppingIngredientItem, error) {
+	if itemID != "" {
+		for _, item := range items {
+			if item.ID == itemID {
+				return item, nil
+			}
+		}
+		return nil, fmt.Errorf("アイテム %s は計画の買い物リストにありません", itemID)
+	}
+
+	var match *model.ShoppingIngredientItem
+	for _, item := range items {
+		if item.Bought {
+			continue
+		}
+		if ing != nil {
+			if item.IngredientID == nil || *item.IngredientID != ing.ID {
+				continue
+			}
+		} else if item.IngredientID != nil || model.NormalizeIngredientName(item.DisplayName()) != model.NormalizeIngredientName(name) {
+			continue
+		}
+		if match == nil || item.TripDate.Before(match.TripDate) {
+			match = item
+		}
+	}
+	return match, nil
+}
+
+// applyReceiptLine は、レシートの行の内容でアイテムを購入済みにします。
+// 量がアイテムと同じ単位（または単位を省略）で分かる場合は、購入した量に加えます。
+func applyReceiptLine(item *model.ShoppingIngredientItem, line ReceiptLineInput, purchasedAt time.Time) error {
+	bought := true
+	update := UpdateShoppingIngredientItemInput{ItemID: item.ID, Bought: &bought}
+	if line.Quantity != nil && (line.Unit == "" || strings.TrimSpace(line.Unit) == item.DisplayUnit()) {
+		purchased := *line.Quantity
+		if item.PurchasedAmount != nil {
+			purchased += *item.PurchasedAmount
+		}
+		update.PurchasedAmount = &purchased
+	}
+	return applyShoppingItemUpdate(item, update, purchasedAt)
+}
+
+func toReceiptOutput(receipt *model.Receipt) *ReceiptOutput {
+	output := &ReceiptOutput{
+		ID:          receipt.ID,
+		PlanID:      receipt.PlanID,
+		StoreName:   receipt.StoreName,
+		PurchasedAt: receipt.PurchasedAt,
+		Total:       receipt.Total,
+		Lines:       make([]*ReceiptLineOutput, len(receipt.Lines)),
+		CreatedAt:   receipt.CreatedAt,
+	}
+	for i := range receipt.Lines {
+		line := &receipt.Lines[i]
+		lineOutput := &ReceiptLineOutput{
+			ID:             line.ID,
+			Name:           line.Name,
+			IngredientID:   line.IngredientID,
+			ShoppingItemID: line.ShoppingItemID,
+			Quantity:       line.Quantity,
+			Unit:           line.Unit,
+			Price:          line.Price,
+		}
+		if line.Ingredient != nil {
+			lineOutput.IngredientName = &line.Ingredient.Name
+			if unitPrice := line.UnitPrice(); unitPrice != nil {
+				rounded := roundAmount(*unitPrice)
+				lineOutput.UnitPrice = &rounded
+			}
+		}
+		output.Lines[i] = lineOutput
+	}
+	return output
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// receiptSkipWords は、品物ではない行（合計や支払い、税、店舗の情報など）の先頭または末尾に書かれる語です。
+// 品名の一部（「税」「対象」など）で品物の行を読み飛ばさないよう、語全体が一致する場合だけ読み飛ばします。
+var receiptSkipWords = map[string]bool{
+	"合計": true, "小計": true, "総計": true, "現計": true, "税込合計": true,
+	"お釣": true, "お釣り": true, "おつり": true, "釣銭": true, "釣り銭": true,
+	"お預": true, "お預り": true, "お預かり": true, "預り": true, "預り金": true, "お預り金": true,
+	"税": true, "税込": true, "税抜": true, "内税": true, "外税": true, "非課税": true,
+	"消費税": true, "消費税等": true, "内消費税": true, "内消費税等": true, "外消費税": true,
+	"対象": true, "対象額": true, "課税対象額": true, "内税対象額": true, "外税対象額": true,
+	"点数": true, "買上点数": true, "お買上点数": true, "お買上げ点数": true, "買上": true, "お買上": true, "お買上げ": true,
+	"クレジット": true, "電子マネー": true, "ポイント": true, "領収書": true, "領収証": true, "レシート": true,
+	"TEL": true, "電話": true,
+}
+
+// receiptDiscountWords は、直前の品物の値引きを表す行の先頭または末尾に書かれる語です（「2割引」「30%引き」は数字を除いて比べます）。
+// 「引きわり納豆」のような品名を値引きとしないよう、語全体が一致する場合だけ値引きとします。
+var receiptDiscountWords = map[string]bool{
+	"値引": true, "値引き": true, "割引": true, "割引き": true, "引": true, "引き": true,
+	"半額": true, "クーポン": true, "クーポン値引": true,
+}
+
+// receiptTokenTrim は、行の語の前後から除く数字や記号（金額、税率、点数など）です。
+const receiptTokenTrim = "0123456789.,%¥\\+-*※◎●・"
+
+var (
+	// receiptDateTimePattern は、買い物をした日付や時刻の行に一致します。
+	receiptDateTimePattern = regexp.MustCompile(`\d{2,4}\s*[/.年-]\s*\d{1,2}\s*[/.月-]\s*\d{1,2}|\d{1,2}:\d{2}`)
+	// receiptPricePattern は、行末の金額（¥や円、カンマ区切り、軽減税率などの記号を含む）と、その前の品名に一致します。
+	receiptPricePattern = regexp.MustCompile(`^(.*?)\s*(-)?\s*[¥\\]?\s*(-)?(\d{1,3}(?:,\d{3})+|\d+)\s*円?\s*[*※軽外内]*$`)
+	// receiptMultiplierPattern は、品名の末尾の「×2」「x2」などの個数に一致します。
+	receiptMultiplierPattern = regexp.MustCompile(`^(.*?)\s*[x×]\s*(\d+)(?:個|点|コ)?$`)
+	// receiptQuantityPattern は、品名の末尾の「10個」「300g」などの量と単位に一致します。
+	receiptQuantityPattern = regexp.MustCompile(`^(.*?)\s*(\d+(?:\.\d+)?)\s*(個|本|枚|g|kg|ml|L|パック|袋|玉|束|株|丁|切れ|尾|缶|箱|房)$`)
+)
+
+// parseReceiptText は、レシートから貼り付けたテキストを、1行に1品として品物ごとの行に解析します。
+// 行末の金額を支払った金額、品名の末尾の量（「10個」「300g」「×2」）を購入した量とします。
+// 合計や支払い、日時などの品物ではない行は読み飛ばし、値引きの行は直前の品物の金額から差し引きます。
+// 品物の行として読み取れなかった行は unparsed に入れて返します。
+func parseReceiptText(text string) (lines []ReceiptLineInput, unparsed []string) {
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimSpace(strings.ReplaceAll(width.Fold.String(raw), "−", "-"))
+		if line == "" || receiptDateTimePattern.MatchString(line) {
+			continue
+		}
+
+		// 金額の行は金額の前の部分の、それ以外の行は行全体の先頭または末尾の語で、品物ではない行かを判断する
+		m := receiptPricePattern.FindStringSubmatch(line)
+		label := line
+		if m != nil {
+			label = m[1]
+		}
+		if hasEdgeWord(label, receiptSkipWords) {
+			continue
+		}
+		if m == nil {
+			unparsed = append(unparsed, strings.TrimSpace(raw))
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimLeft(m[1], "*※◎●・ "))
+		price, err := strconv.Atoi(strings.ReplaceAll(m[4], ",", ""))
+		if err != nil {
+			unparsed = append(unparsed, strings.TrimSpace(raw))
+			continue
+		}
+
+		if m[2] != "" || m[3] != "" || hasEdgeWord(name, receiptDiscountWords) {
+			// 値引きは直前の品物の金額から差し引く（0円未満にはしない）
+			if len(lines) == 0 {
+				unparsed = append(unparsed, strings.TrimSpace(raw))
+				continue
+			}
+			prev := &lines[len(lines)-1]
+			prev.Price = max(prev.Price-price, 0)
+			continue
+		}
+		if name == "" || strings.Trim(name, "0123456789. ") == "" {
+			unparsed = append(unparsed, strings.TrimSpace(raw))
+			continue
+		}
+
+		item := ReceiptLineInput{Name: name, Price: price}
+		if m := receiptMultiplierPattern.FindStringSubmatch(name); m != nil && strings.TrimSpace(m[1]) != "" {
+			quantity, _ := strconv.ParseFloat(m[2], 64)
+			item.Name, item.Quantity = strings.TrimSpace(m[1]), &quantity
+		} else if m := receiptQuantityPattern.FindStringSubmatch(name); m != nil && strings.TrimSpace(m[1]) != "" {
+			quantity, _ := strconv.ParseFloat(m[2], 64)
+			item.Name, item.Quantity, item.Unit = strings.TrimSpace(m[1]), &quantity, m[3]
+		}
+		lines = append(lines, item)
+	}
+	return lines, unparsed
+}
+
+// hasEdgeWord は、s の先頭または末尾の語が words のいずれかと一致するかどうかを返します。
+// 語は空白と括弧で区切り、前後の数字や記号と末尾の「点」を除いて比べます（「(8%対象」は「対象」、「5点」は空として扱う）。
+func hasEdgeWord(s string, words map[string]bool) bool {
+	var tokens []string
+	for _, field := range strings.FieldsFunc(s, isReceiptTokenSeparator) {
+		token := strings.Trim(strings.TrimSuffix(strings.Trim(field, receiptTokenTrim), "点"), receiptTokenTrim)
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return false
+	}
+	return words[tokens[0]] || words[tokens[len(tokens)-1]]
+}
+
+// isReceiptTokenSeparator は、レシートの行を語に区切る文字（空白と括弧、コロン）かどうかを返します。
+func isReceiptTokenSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("()[]<>:（）［］【】「」＜＞：", r)
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseReceiptText(t *testing.T) {
+	tests := []struct {
+		name         string
+		text         string
+		wantLines    []ReceiptLineInput
+		wantUnparsed []string
+	}{
+		{
+			name: "レシート全体",
+			text: "スーパーまるやま 駅前店\n" +
+				"TEL 03-1234-5678\n" +
+				"2024年4月1日(月) 18:32\n" +
+				"\n" +
+				"牛乳 1L ¥198\n" +
+				"卵 10個 248円*\n" +
+				"玉ねぎ ×3 ¥294\n" +
+				"豚こま肉 300g 598\n" +
+				"  値引 -100\n" +
+				"小計 ¥1,338\n" +
+				"(8%対象 ¥1,338)\n" +
+				"(内消費税等 ¥99)\n" +
+				"合計 ¥1,338\n" +
+				"お預り ¥2,000\n" +
+				"お釣り ¥662\n" +
+				"お買上点数 4点\n",
+			wantLines: []ReceiptLineInput{
+				{Name: "牛乳", Quantity: floatPtr(1), Unit: "L", Price: 198},
+				{Name: "卵", Quantity: floatPtr(10), Unit: "個", Price: 248},
+				{Name: "玉ねぎ", Quantity: floatPtr(3), Price: 294},
+				{Name: "豚こま肉", Quantity: floatPtr(300), Unit: "g", Price: 498},
+			},
+			wantUnparsed: []string{"スーパーまるやま 駅前店"},
+		},
+		{
+			name:      "全角の数字と記号",
+			text:      "ヨーグルト　４００ｇ　￥１５８",
+			wantLines: []ReceiptLineInput{{Name: "ヨーグルト", Quantity: floatPtr(400), Unit: "g", Price: 158}},
+		},
+		{
+			name: "品名の一部が読み飛ばす語や値引きの語でも品物として読む",
+			text: "引きわり納豆 98\n税込ハンバーグ 398\n対象外セール品 100",
+			wantLines: []ReceiptLineInput{
+				{Name: "引きわり納豆", Price: 98},
+				{Name: "税込ハンバーグ", Price: 398},
+				{Name: "対象外セール品", Price: 100},
+			},
+		},
+		{
+			name: "値引きの書き方",
+			text: "食パン 200\n2割引 40\n鮭切り身 300\n半額 150\nバナナ 120\n-20\nりんご 100\nクーポン値引 ¥200",
+			wantLines: []ReceiptLineInput{
+				{Name: "食パン", Price: 160},
+				{Name: "鮭切り身", Price: 150},
+				{Name: "バナナ", Price: 100},
+				{Name: "りんご", Price: 0},
+			},
+		},
+		{
+			name:         "品物より前の値引きと品名の無い金額",
+			text:         "値引 50\n1,000\nお茶 2本",
+			wantUnparsed: []string{"値引 50", "1,000", "お茶 2本"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, unparsed := parseReceiptText(tt.text)
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("lines = %+v, want %+v", lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(unparsed, tt.wantUnparsed) {
+				t.Errorf("unparsed = %q, want %q", unparsed, tt.wantUnparsed)
+			}
+		})
+	}
+}
+
+func TestHasEdgeWord(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "合計", want: true},
+		{input: "(8%対象", want: true},
+		{input: "お買上点数 4点", want: true},
+		{input: "TEL 03-1234-5678", want: true},
+		{input: "【ポイント】", want: true},
+		{input: "税込ハンバーグ", want: false},
+		{input: "国産 対象 外 牛肉", want: false},
+		{input: "5点", want: false},
+		{input: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := hasEdgeWord(tt.input, receiptSkipWords); got != tt.want {
+			t.Errorf("hasEdgeWord(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/width"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+// DraftRecipeInput は、外部のレシピ（schema.org/Recipe など）から読み取った情報です。
+type DraftRecipeInput struct {
+	Name            string
+	SourceURL       string
+	Yield           string   // recipeYield の値。"2人分" のような文字列から人数を読み取ります
+	IngredientLines []string // "玉ねぎ 1/2個" や "醤油 大さじ2" のような材料行
+	Steps           []string
+}
+
+// DraftRecipeOutput は、登録前に確認するためのメニューの下書きです。
+// Lines の分量は1人前・食材マスターの単位に換算済みです。Servings と Steps は元のレシピのままです。
+type DraftRecipeOutput struct {
+	MenuName  string                 `json:"menu_name"`
+	SourceURL string                 `json:"source_url,omitempty"`
+	Servings  int                    `json:"servings"`
+	Steps     []string               `json:"steps"`
+	Lines     []*DraftLineOutput     `json:"lines"`
+	Unmatched []*UnmatchedLineOutput `json:"unmatched"`
+}
+
+type DraftLineOutput struct {
+	Raw            []string `json:"raw"` // 同じ食材の行が複数ある場合は合算するため、元の行はすべて保持する
+	IngredientName string   `json:"ingredient_name"`
+	Amount         float64  `json:"amount"`
+	Unit           string   `json:"unit"`
+}
+
+// UnmatchedLineOutput は、下書きに反映できなかった材料行です。読み捨てずに理由とともに返します。
+type UnmatchedLineOutput struct {
+	Raw            string `json:"raw"`
+	IngredientName string `json:"ingredient_name,omitempty"`
+	Reason         string `json:"reason"`
+}
+
+// Catalog は、下書きを ImportRecipes に渡せる形に変換します。
+func (o *DraftRecipeOutput) Catalog() *Catalog {
+	recipe := &RecipeRecord{MenuName: o.MenuName, Servings: o.Servings, SourceURL: o.SourceURL, Steps: o.Steps}
+	for _, line := range o.Lines {
+		recipe.Lines = append(recipe.Lines, &RecipeLineRecord{
+			IngredientName: line.IngredientName,
+			Amount:         line.Amount,
+			Unit:           line.Unit,
+		})
+	}
+	return &Catalog{Recipes: []*RecipeRecord{recipe}}
+}
+
+// ErrNoIngredientLines は、下書きの元になるレシピに材料行が1つも含まれていない場合に返されます。
+var ErrNoIngredientLines = newError(ErrValidation, "no_ingredient_lines", "レシピに材料が含まれていません")
+
+// DraftRecipe は、外部レシピの材料行を登録済みの食材に対応付け、メニューの下書きを作成します。
+// 下書きは保存せずに返すので、内容を確認したうえで ImportRecipes で登録します。
+func (u *catalogUsecase) DraftRecipe(ctx context.Context, input DraftRecipeInput) (*DraftRecipeOutput, error) {
+	if len(input.IngredientLines) == 0 {
+		return nil, ErrNoIngredientLines
+	}
+
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	resolver := newIngredientResolver(ingredients)
+
+	output := &DraftRecipeOutput{
+		MenuName:  strings.TrimSpace(input.Name),
+		SourceURL: input.SourceURL,
+		Servings:  parseServings(input.Yield),
+		Steps:     append([]string{}, input.Steps...),
+		Lines:     []*DraftLineOutput{},
+		Unmatched: []*UnmatchedLineOutput{},
+	}
+	linesByIngredient := make(map[string]*DraftLineOutput)
+
+	for _, raw := range input.IngredientLines {
+		parsed := parseIngredientLine(raw)
+		if parsed.name == "" {
+			output.Unmatched = append(output.Unmatched, &UnmatchedLineOutput{Raw: raw, Reason: "食材名を読み取れません"})
+			continue
+		}
+
+		ing := resolver.resolveContained(parsed.name)
+		if ing == nil {
+			output.Unmatched = append(output.Unmatched, &UnmatchedLineOutput{Raw: raw, Reason: "該当する食材が登録されていません"})
+			continue
+		}
+		if !parsed.hasAmount {
+			output.Unmatched = append(output.Unmatched, &UnmatchedLineOutput{Raw: raw, IngredientName: ing.Name, Reason: "分量が数値で指定されていません"})
+			continue
+		}
+		amount, ok := convertAmount(parsed.amount, parsed.unit, ing)
+		if !ok {
+			output.Unmatched = append(output.Unmatched, &UnmatchedLineOutput{
+				Raw:            raw,
+				IngredientName: ing.Name,
+				Reason:         fmt.Sprintf("単位「%s」を「%s」に換算できません", parsed.unit, ing.Unit),
+			})
+			continue
+		}
+
+		// 合わせ調味料などで同じ食材が複数回出てくる場合は合算する
+		if line, ok := linesByIngredient[ing.Name]; ok {
+			line.Raw = append(line.Raw, raw)
+			line.Amount += amount
+			continue
+		}
+		line := &DraftLineOutput{Raw: []string{raw}, IngredientName: ing.Name, Amount: amount, Unit: ing.Unit}
+		linesByIngredient[ing.Name] = line
+		output.Lines = append(output.Lines, line)
+	}
+
+	// 登録するレシピは1人前の分量で管理しているため、人数で割る
+	for _, line := range output.Lines {
+		line.Amount = roundAmount(line.Amount / float64(output.Servings))
+	}
+	return output, nil
+}
+
+// --- Ingredient line parsing ---
+
+// parsedIngredientLine は、材料行を食材名と分量に分解した結果です。
+type parsedIngredientLine struct {
+	name      string
+	amount    float64
+	unit      string
+	hasAmount bool
+}
+
+var (
+	// 行頭の記号（・や★など）。合わせ調味料の「A」「B」のような1文字の英字も読み飛ばす
+	lineBulletPattern = regexp.MustCompile(`^(?:[・●○◯◎★☆■□◆◇※*\-]+|[A-Za-z](?:\s|$))\s*`)
+	// （みじん切り）などの補足
+	parenPattern = regexp.MustCompile(`[（(【\[［][^）)】\]］]*[）)】\]］]`)
+	// "2~3個" のような幅のある分量は、少ない方の値を使う
+	rangePattern = regexp.MustCompile(`(\d)\s*[~〜～]\s*\d+(?:\.\d+)?`)
+	// 大さじ2、小さじ1/2、カップ1と1/2 のように単位が先に来る分量
+	prefixQuantityPattern = regexp.MustCompile(`(大さじ|小さじ|カップ)\s*(\d+(?:\.\d+)?(?:と\d+/\d+)?|\d+/\d+)\s*(?:杯)?\s*$`)
+	// 200g、1/2個、1と1/2本 のように数値が先に来る分量
+	suffixQuantityPattern = regexp.MustCompile(`(\d+(?:\.\d+)?(?:と\d+/\d+)?|\d+/\d+)\s*([^\d\s]*)\s*$`)
+	// 食材名と分量の間の区切り文字
+	nameTrailerPattern = regexp.MustCompile(`[\s…‥:：・.。、,]+$`)
+	// 人数の読み取り
+	servingsPattern = regexp.MustCompile(`\d+`)
+)
+
+// vulgarFractions は、"½" のような分数の文字を "1/2" に置き換えます。
+var vulgarFractions = strings.NewReplacer("½", "1/2", "⅓", "1/3", "⅔", "2/3", "¼", "1/4", "¾", "3/4", "⅛", "1/8")
+
+// parseIngredientLine は、"玉ねぎ 1/2個" や "醤油 大さじ2" のような材料行を分解します。
+func parseIngredientLine(raw string) parsedIngredientLine {
+	line := width.Fold.String(raw) // 全角英数字を半角に、半角カナを全角に揃える
+	line = vulgarFractions.Replace(line)
+	line = strings.TrimSpace(lineBulletPattern.ReplaceAllString(strings.TrimSpace(line), ""))
+	line = parenPattern.ReplaceAllString(line, " ")
+	line = rangePattern.ReplaceAllString(line, "$1")
+	line = strings.TrimSpace(line)
+
+	var result parsedIngredientLine
+	if m := prefixQuantityPattern.FindStringSubmatchIndex(line); m != nil {
+		result.unit = line[m[2]:m[3]]
+		result.amount, result.hasAmount = parseNumber(line[m[4]:m[5]])
+		line = line[:m[0]]
+	} else if m := suffixQuantityPattern.FindStringSubmatchIndex(line); m != nil {
+		result.amount, result.hasAmount = parseNumber(line[m[2]:m[3]])
+		result.unit = normalizeUnit(line[m[4]:m[5]])
+		line = line[:m[0]]
+	} else {
+		// "塩 少々" のように数値がない場合は、最後の語を分量とみなして食材名から外す
+		if fields := strings.Fields(line); len(fields) > 1 {
+			line = strings.Join(fields[:len(fields)-1], " ")
+		}
+	}
+	result.name = strings.TrimSpace(nameTrailerPattern.ReplaceAllString(line, ""))
+	return result
+}
+
+// parseNumber は、"1"、"1.5"、"1/2"、"1と1/2" を数値に変換します。
+func parseNumber(s string) (float64, bool) {
+	whole, frac, hasFrac := strings.Cut(s, "と")
+	if !hasFrac {
+		if strings.Contains(s, "/") {
+			whole, frac = "0", s
+		} else {
+			v, err := strconv.ParseFloat(s, 64)
+			return v, err == nil
+		}
+	}
+	w, err := strconv.ParseFloat(whole, 64)
+	if err != nil {
+		return 0, false
+	}
+	num, den, ok := strings.Cut(frac, "/")
+	if !ok {
+		return 0, false
+	}
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if err1 != nil || err2 != nil || d == 0 {
+		return 0, false
+	}
+	return w + n/d, true
+}
+
+// parseServings は、"2人分" や "4 servings" から人数を読み取ります。読み取れない場合は1人前とみなします。
+func parseServings(yield string) int {
+	if m := servingsPattern.FindString(width.Fold.String(yield)); m != "" {
+		if n, err := strconv.Atoi(m); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 1
+}
+
+// --- Unit conversion ---
+
+// unitAliases は、表記ゆれのある単位を食材マスターで使う表記に揃えます。
+var unitAliases = map[string]string{
+	"cc": "ml", "ml": "ml", "mL": "ml", "ミリリットル": "ml",
+	"l": "l", "L": "l", "リットル": "l",
+	"g": "g", "グラム": "g", "kg": "kg", "キロ": "kg",
+	"こ": "個", "コ": "個", "ヶ": "個", "ケ": "個", "ケ所": "個",
+}
+
+func normalizeUnit(unit string) string {
+	if alias, ok := unitAliases[unit]; ok {
+		return alias
+	}
+	return unit
+}
+
+// volumeUnits は、体積の単位をmlに換算する係数です。
+var volumeUnits = map[string]float64{"ml": 1, "l": 1000, "大さじ": 15, "小さじ": 5, "カップ": 200}
+
+// massUnits は、重さの単位をgに換算する係数です。
+var massUnits = map[string]float64{"g": 1, "kg": 1000}
+
+// densities は、体積と重さを換算するための比重 (g/ml) です。載っていない食材は1として扱います。
+var densities = map[string]float64{
+	"砂糖": 0.6, "塩": 1.2, "味噌": 1.2, "小麦粉": 0.6, "片栗粉": 0.6, "パン粉": 0.2,
+	"マヨネーズ": 0.8, "ケチャップ": 1.2, "バター": 0.8, "こしょう": 0.4, "米": 0.85,
+	"コンソメ": 0.6, "鶏がらスープの素": 0.6, "豆板醤": 1.2, "オイスターソース": 1.2,
+}
+
+// convertAmount は、材料行の分量を食材マスターの単位に換算します。
+// 単位が省略されている場合は、食材マスターの単位で書かれているとみなします。
+func convertAmount(amount float64, unit string, ing *model.Ingredient) (float64, bool) {
+	target := normalizeUnit(ing.Unit)
+	if unit == "" || unit == target {
+		return amount, true
+	}
+
+	density, ok := densities[ing.Name]
+	if !ok {
+		density = 1
+	}
+	srcVolume, srcIsVolume := volumeUnits[unit]
+	srcMass, srcIsMass := massUnits[unit]
+	dstVolume, dstIsVolume := volumeUnits[target]
+	dstMass, dstIsMass := massUnits[target]
+
+	switch {
+	case srcIsVolume && dstIsVolume:
+		return amount * srcVolume / dstVolume, true
+	case srcIsMass && dstIsMass:
+		return amount * srcMass / dstMass, true
+	case srcIsVolume && dstIsMass:
+		return amount * srcVolume * density / dstMass, true
+	case srcIsMass && dstIsVolume:
+		return amount * srcMass / density / dstVolume, true
+	}
+	return 0, false
+}
+
+// roundAmount は、DBの decimal(10,2) に合わせて小数第2位までに丸めます。0にはしません。
+func roundAmount(v float64) float64 {
+	rounded := math.Round(v*100) / 100
+	if rounded == 0 && v > 0 {
+		return 0.01
+	}
+	return rounded
+}
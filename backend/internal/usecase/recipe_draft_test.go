@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+func TestParseIngredientLine(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want parsedIngredientLine
+	}{
+		{raw: "玉ねぎ 1/2個", want: parsedIngredientLine{name: "玉ねぎ", amount: 0.5, unit: "個", hasAmount: true}},
+		{raw: "豚バラ肉 200g", want: parsedIngredientLine{name: "豚バラ肉", amount: 200, unit: "g", hasAmount: true}},
+		{raw: "醤油 大さじ2", want: parsedIngredientLine{name: "醤油", amount: 2, unit: "大さじ", hasAmount: true}},
+		{raw: "砂糖 小さじ1/2", want: parsedIngredientLine{name: "砂糖", amount: 0.5, unit: "小さじ", hasAmount: true}},
+		{raw: "牛乳 カップ1と1/2", want: parsedIngredientLine{name: "牛乳", amount: 1.5, unit: "カップ", hasAmount: true}},
+		{raw: "にんじん 1と1/2本", want: parsedIngredientLine{name: "にんじん", amount: 1.5, unit: "本", hasAmount: true}},
+		{raw: "水 100cc", want: parsedIngredientLine{name: "水", amount: 100, unit: "ml", hasAmount: true}},
+		{raw: "じゃがいも 2~3個", want: parsedIngredientLine{name: "じゃがいも", amount: 2, unit: "個", hasAmount: true}},
+		{raw: "バター ½ カップ", want: parsedIngredientLine{name: "バター", amount: 0.5, unit: "カップ", hasAmount: true}},
+		{raw: "・ 卵 ２コ", want: parsedIngredientLine{name: "卵", amount: 2, unit: "個", hasAmount: true}},
+		{raw: "A みりん 大さじ1", want: parsedIngredientLine{name: "みりん", amount: 1, unit: "大さじ", hasAmount: true}},
+		{raw: "長ねぎ（みじん切り） 10cm", want: parsedIngredientLine{name: "長ねぎ", amount: 10, unit: "cm", hasAmount: true}},
+		{raw: "鶏もも肉……300g", want: parsedIngredientLine{name: "鶏もも肉", amount: 300, unit: "g", hasAmount: true}},
+		{raw: "塩 少々", want: parsedIngredientLine{name: "塩"}},
+		{raw: "こしょう", want: parsedIngredientLine{name: "こしょう"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			if got := parseIngredientLine(tt.raw); got != tt.want {
+				t.Errorf("parseIngredientLine(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   float64
+		wantOK bool
+	}{
+		{input: "2", want: 2, wantOK: true},
+		{input: "1.5", want: 1.5, wantOK: true},
+		{input: "1/4", want: 0.25, wantOK: true},
+		{input: "2と1/2", want: 2.5, wantOK: true},
+		{input: "1/0", wantOK: false},
+		{input: "1と", wantOK: false},
+		{input: "少々", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.input)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseServings(t *testing.T) {
+	tests := []struct {
+		yield string
+		want  int
+	}{
+		{yield: "2人分", want: 2},
+		{yield: "４人前", want: 4},
+		{yield: "4 servings", want: 4},
+		{yield: "0人分", want: 1},
+		{yield: "", want: 1},
+	}
+	for _, tt := range tests {
+		if got := parseServings(tt.yield); got != tt.want {
+			t.Errorf("parseServings(%q) = %d, want %d", tt.yield, got, tt.want)
+		}
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	tests := []struct {
+		name       string
+		amount     float64
+		unit       string
+		ingredient model.Ingredient
+		want       float64
+		wantOK     bool
+	}{
+		{name: "単位の省略は食材の単位", amount: 2, unit: "", ingredient: model.Ingredient{Name: "卵", Unit: "個"}, want: 2, wantOK: true},
+		{name: "同じ単位", amount: 150, unit: "g", ingredient: model.Ingredient{Name: "豚肉", Unit: "g"}, want: 150, wantOK: true},
+		{name: "食材の単位の表記ゆれ", amount: 200, unit: "ml", ingredient: model.Ingredient{Name: "牛乳", Unit: "mL"}, want: 200, wantOK: true},
+		{name: "体積どうし", amount: 2, unit: "大さじ", ingredient: model.Ingredient{Name: "醤油", Unit: "ml"}, want: 30, wantOK: true},
+		{name: "体積から別の体積", amount: 1, unit: "カップ", ingredient: model.Ingredient{Name: "だし", Unit: "l"}, want: 0.2, wantOK: true},
+		{name: "重さどうし", amount: 0.5, unit: "kg", ingredient: model.Ingredient{Name: "鶏肉", Unit: "g"}, want: 500, wantOK: true},
+		{name: "体積から重さ（比重あり）", amount: 1, unit: "大さじ", ingredient: model.Ingredient{Name: "砂糖", Unit: "g"}, want: 9, wantOK: true},
+		{name: "体積から重さ（比重なし）", amount: 1, unit: "小さじ", ingredient: model.Ingredient{Name: "酒", Unit: "g"}, want: 5, wantOK: true},
+		{name: "重さから体積", amount: 12, unit: "g", ingredient: model.Ingredient{Name: "塩", Unit: "小さじ"}, want: 2, wantOK: true},
+		{name: "換算できない単位", amount: 1, unit: "本", ingredient: model.Ingredient{Name: "にんじん", Unit: "g"}, wantOK: false},
+		{name: "食材の単位が換算できない", amount: 100, unit: "g", ingredient: model.Ingredient{Name: "卵", Unit: "個"}, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := convertAmount(tt.amount, tt.unit, &tt.ingredient)
+			if ok != tt.wantOK || (ok && math.Abs(got-tt.want) > 1e-9) {
+				t.Errorf("convertAmount(%v, %q, %s/%s) = %v, %v, want %v, %v", tt.amount, tt.unit, tt.ingredient.Name, tt.ingredient.Unit, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRoundAmount(t *testing.T) {
+	tests := []struct {
+		input float64
+		want  float64
+	}{
+		{input: 1.234, want: 1.23},
+		{input: 1.235, want: 1.24},
+		{input: 0.001, want: 0.01},
+		{input: 0, want: 0},
+	}
+	for _, tt := range tests {
+		if got := roundAmount(tt.input); got != tt.want {
+			t.Errorf("roundAmount(%v) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// seasonBoost は、旬の食材だけを使うメニューが選ばれやすくなる倍率です。
+// 旬を外れた食材だけを使うメニューは 1/seasonBoost 倍、旬の設定された食材を使わないメニューは等倍で選ばれます。
+const seasonBoost = 4.0
+
+// SeasonalIngredientOutput は、食事の日付に旬を迎えている食材です。
+type SeasonalIngredientOutput struct {
+	Name            string   `json:"name"`
+	PriceMultiplier *float64 `json:"price_multiplier"` // 旬の時期の価格の目安（通常を1とした倍率）。未設定の場合は null
+}
+
+// seasonScore は、指定された月におけるレシピの食材の旬の度合いを -1〜1 で返します。
+// 旬が設定された食材のうち、旬のものを1、旬を外れたものを-1として平均します。旬が設定された食材がなければ0です。
+func seasonScore(ingredients []*model.Ingredient, month time.Month) float64 {
+	total, seasonal := 0.0, 0
+	for _, ing := range ingredients {
+		if !ing.HasSeason() {
+			continue
+		}
+		seasonal++
+		if ing.InSeason(month) {
+			total++
+		} else {
+			total--
+		}
+	}
+	if seasonal == 0 {
+		return 0
+	}
+	return total / float64(seasonal)
+}
+
+// seasonalIngredients は、指定された月に旬を迎えている食材を返します。
+func seasonalIngredients(ingredients []*model.Ingredient, month time.Month) []*SeasonalIngredientOutput {
+	output := []*SeasonalIngredientOutput{}
+	for _, ing := range ingredients {
+		if ing.InSeason(month) {
+			output = append(output, &SeasonalIngredientOutput{Name: ing.Name, PriceMultiplier: ing.SeasonalPriceMultiplier})
+		}
+	}
+	return output
+}
+
+// seasonalCandidate は、計画に使えるメニューの候補です。
+type seasonalCandidate struct {
+	menu          *model.Menu
+	substitutions map[string]*plannedSubstitution
+	ingredients   []*model.Ingredient // 代用後のレシピの食材
+}
+
+// newSeasonalCandidate は、代用を反映したレシピの食材とともに候補を作成します。
+func newSeasonalCandidate(menu *model.Menu, substitutions map[string]*plannedSubstitution) *seasonalCandidate {
+	c := &seasonalCandidate{menu: menu, substitutions: substitutions}
+	for i := range menu.MenuIngredientItems {
+		item := &menu.MenuIngredientItems[i]
+		if sub, ok := substitutions[item.IngredientID]; ok {
+			c.ingredients = append(c.ingredients, sub.to)
+		} else {
+			c.ingredients = append(c.ingredients, &item.Ingredient)
+		}
+	}
+	return c
+}
+
+// pickSeasonalMenus は、食事の日付ごとに候補から重複なくメニューを選びます。
+// 候補はその日付の月における旬の度合いに応じた重み（seasonBoost の seasonScore 乗）で無作為に選ぶため、
+// 旬のメニューほど選ばれやすく、旬を外れた食材の多いメニューは選ばれにくくなります。
+// 候補が日付の数以上あることを前提とします。
+func pickSeasonalMenus(candidates []*seasonalCandidate, dates []time.Time) []*seasonalCandidate {
+	pool := append([]*seasonalCandidate(nil), candidates...)
+	picked := make([]*seasonalCandidate, len(dates))
+	weights := make([]float64, len(pool))
+	for i, date := range dates {
+		total := 0.0
+		for j, c := range pool {
+			weights[j] = math.Pow(seasonBoost, seasonScore(c.ingredients, date.Month()))
+			total += weights[j]
+		}
+		r := rand.Float64() * total
+		chosen := len(pool) - 1
+		for j := range pool {
+			if r -= weights[j]; r < 0 {
+				chosen = j
+				break
+			}
+		}
+		picked[i] = pool[chosen]
+		pool = append(pool[:chosen], pool[chosen+1:]...)
+		weights = weights[:len(pool)]
+	}
+	return picked
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// seasonalIngredient は、months を旬とする食材を作成します。months が空の場合は旬を設定しません。
+func seasonalIngredient(name string, months ...int) *model.Ingredient {
+	season, err := model.NewSeasonMonths(months)
+	if err != nil {
+		panic(err)
+	}
+	return &model.Ingredient{Name: name, SeasonMonths: season}
+}
+
+func TestSeasonScore(t *testing.T) {
+	var (
+		springCabbage = seasonalIngredient("春キャベツ", 3, 4, 5)
+		bamboo        = seasonalIngredient("たけのこ", 4, 5)
+		saury         = seasonalIngredient("さんま", 9, 10)
+		egg           = seasonalIngredient("卵")
+	)
+
+	tests := []struct {
+		name        string
+		ingredients []*model.Ingredient
+		month       time.Month
+		want        float64
+	}{
+		{name: "食材なし", ingredients: nil, month: time.April, want: 0},
+		{name: "旬が設定された食材なし", ingredients: []*model.Ingredient{egg}, month: time.April, want: 0},
+		{name: "すべて旬", ingredients: []*model.Ingredient{springCabbage, bamboo, egg}, month: time.April, want: 1},
+		{name: "すべて旬を外れる", ingredients: []*model.Ingredient{springCabbage, bamboo}, month: time.October, want: -1},
+		{name: "旬と旬外れが半々", ingredients: []*model.Ingredient{springCabbage, saury}, month: time.April, want: 0},
+		{name: "旬が3つのうち2つ", ingredients: []*model.Ingredient{springCabbage, bamboo, saury, egg}, month: time.May, want: 1.0 / 3},
+		{name: "月の境目", ingredients: []*model.Ingredient{springCabbage, bamboo}, month: time.March, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := seasonScore(tt.ingredients, tt.month); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("seasonScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeasonalIngredients(t *testing.T) {
+	multiplier := 0.8
+	cabbage := seasonalIngredient("春キャベツ", 3, 4, 5)
+	cabbage.SeasonalPriceMultiplier = &multiplier
+	ingredients := []*model.Ingredient{cabbage, seasonalIngredient("さんま", 9, 10), seasonalIngredient("卵")}
+
+	got := seasonalIngredients(ingredients, time.April)
+	if len(got) != 1 || got[0].Name != "春キャベツ" || got[0].PriceMultiplier != &multiplier {
+		t.Errorf("seasonalIngredients(April) = %+v, want only 春キャベツ with its price multiplier", got)
+	}
+	if got := seasonalIngredients(ingredients, time.January); got == nil || len(got) != 0 {
+		t.Errorf("seasonalIngredients(January) = %#v, want an empty slice", got)
+	}
+}
+
+func TestPickSeasonalMenusWeighting(t *testing.T) {
+	april := []time.Time{time.Date(2024, 4, 10, 0, 0, 0, 0, time.Local)}
+	var (
+		inSeason  = &seasonalCandidate{menu: &model.Menu{Name: "旬"}, ingredients: []*model.Ingredient{seasonalIngredient("たけのこ", 4, 5)}}
+		outSeason = &seasonalCandidate{menu: &model.Menu{Name: "旬外れ"}, ingredients: []*model.Ingredient{seasonalIngredient("さんま", 9, 10)}}
+		neutral   = &seasonalCandidate{menu: &model.Menu{Name: "通年"}, ingredients: []*model.Ingredient{seasonalIngredient("卵")}}
+	)
+
+	// 選ばれる割合は重み（seasonBoost の seasonScore 乗）の比になる。無作為に選ぶため、幅を持たせて確かめる
+	tests := []struct {
+		name       string
+		candidates []*seasonalCandidate
+		want       float64 // candidates[0] が選ばれる割合
+	}{
+		{name: "旬と旬外れ", candidates: []*seasonalCandidate{inSeason, outSeason}, want: seasonBoost / (seasonBoost + 1/seasonBoost)},
+		{name: "旬と通年", candidates: []*seasonalCandidate{inSeason, neutral}, want: seasonBoost / (seasonBoost + 1)},
+		{name: "通年と旬外れ", candidates: []*seasonalCandidate{neutral, outSeason}, want: 1 / (1 + 1/seasonBoost)},
+		{name: "通年どうし", candidates: []*seasonalCandidate{neutral, {menu: &model.Menu{Name: "通年2"}}}, want: 0.5},
+	}
+
+	const trials = 4000
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := 0
+			for i := 0; i < trials; i++ {
+				if pickSeasonalMenus(tt.candidates, april)[0] == tt.candidates[0] {
+					first++
+				}
+			}
+			if got := float64(first) / trials; math.Abs(got-tt.want) > 0.05 {
+				t.Errorf("%s was picked %.3f of the time, want about %.3f", tt.candidates[0].menu.Name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickSeasonalMenusPicksEachCandidateOnce(t *testing.T) {
+	candidates := make([]*seasonalCandidate, 5)
+	for i := range candidates {
+		candidates[i] = &seasonalCandidate{menu: &model.Menu{}, ingredients: []*model.Ingredient{seasonalIngredient("食材", i+1)}}
+	}
+	dates := make([]time.Time, len(candidates))
+	for i := range dates {
+		dates[i] = time.Date(2024, time.Month(i+1), 1, 0, 0, 0, 0, time.Local)
+	}
+
+	original := append([]*seasonalCandidate(nil), candidates...)
+	for trial := 0; trial < 100; trial++ {
+		picked := pickSeasonalMenus(candidates, dates)
+		seen := make(map[*seasonalCandidate]bool)
+		for _, c := range picked {
+			if c == nil || seen[c] {
+				t.Fatalf("pickSeasonalMenus() picked a candidate twice or none: %v", picked)
+			}
+			seen[c] = true
+		}
+	}
+	for i := range candidates {
+		if candidates[i] != original[i] {
+			t.Fatalf("pickSeasonalMenus() modified the candidates")
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+const (
+	// DefaultShareTokenTTL は、有効期限を指定せずに発行した共有リンクの有効期間です。
+	DefaultShareTokenTTL = 7 * 24 * time.Hour
+	// MaxShareTokenTTL は、共有リンクに指定できる有効期間の上限です。
+	MaxShareTokenTTL = 90 * 24 * time.Hour
+	// shareTokenBytes は、共有トークンのランダムなバイト数です。
+	shareTokenBytes = 32
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+type CreateShareTokenInput struct {
+	PlanID    string
+	Scope     string
+	ExpiresAt *time.Time // 省略した場合は DefaultShareTokenTTL 後
+}
+
+type ShareTokenOutput struct {
+	ID        string     `json:"id"`
+	Token     string     `json:"token,omitempty"` // 発行時にだけ返します（保存しているのはハッシュのみ）
+	Path      string     `json:"path,omitempty"`  // 共有リンクのAPIのパス。発行時にだけ返します
+	Scope     string     `json:"scope"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	Active    bool       `json:"active"` // 無効にされておらず、期限内かどうか
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ShareAccess は、共有リンクのトークンで許可されている計画と操作の範囲です。
+type ShareAccess struct {
+	PlanID string
+	Scope  model.ShareScope
+}
+
+// ErrInvalidShareToken は、共有リンクの発行内容（範囲や有効期限）が不正な場合に返されます。
+var ErrInvalidShareToken = newError(ErrValidation, "invalid_share_token", "共有リンクの内容が不正です")
+
+// ErrShareTokenExpired は、共有リンクが無効にされたか、有効期限が切れている場合に返されます。
+var ErrShareTokenExpired = newError(ErrGone, "share_token_expired", "共有リンクは無効にされたか、有効期限が切れています")
+
+// ErrShareScopeDenied は、共有リンクの範囲で許可されていない操作をしようとした場合に返されます。
+var ErrShareScopeDenied = newError(ErrForbidden, "share_scope_denied", "共有リンクではこの操作は許可されていません")
+
+// --- Usecase Interface ---
+
+// ShareUsecase は、買い物計画の共有リンクに関するビジネスロジックのインターフェースです。
+type ShareUsecase interface {
+	CreateShareToken(ctx context.Context, input CreateShareTokenInput) (*ShareTokenOutput, error)
+	GetShareTokens(ctx context.Context, planID string) ([]*ShareTokenOutput, error)
+	RevokeShareToken(ctx context.Context, planID, tokenID string) error
+	// AuthorizeShareToken は、トークンが有効で required の操作を許可しているかを確かめ、共有している計画を返します。
+	// トークンが存在しない場合は ErrShareTokenNotFound を返します。
+	AuthorizeShareToken(ctx context.Context, token string, required model.ShareScope) (*ShareAccess, error)
+}
+
+// --- Usecase Implementation ---
+
+// shareUsecase は ShareUsecase インターフェースの実装です。
+type shareUsecase struct {
+	planRepo       repository.PlanRepository
+	shareTokenRepo repository.ShareTokenRepository
+}
+
+// NewShareUsecase は新しい shareUsecase のインスタンスを生成します。
+func NewShareUsecase(planRepo repository.PlanRepository, shareTokenRepo repository.ShareTokenRepository) ShareUsecase {
+	return &shareUsecase{
+		planRepo:       planRepo,
+		shareTokenRepo: shareTokenRepo,
+	}
+}
+
+// CreateShareToken は、計画の共有リンクを発行します。計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *shareUsecase) CreateShareToken(ctx context.Context, input CreateShareTokenInput) (*ShareTokenOutput, error) {
+	scope := model.ShareScope(input.Scope)
+	if scope != model.ShareReadOnly && scope != model.ShareCheckOff {
+		return nil, fmt.Errorf("%w: scope には %s または %s を指定してください", ErrInvalidShareToken, model.ShareReadOnly, model.ShareCheckOff)
+	}
+	now := time.Now()
+	expiresAt := now.Add(DefaultShareTokenTTL)
+	if input.ExpiresAt != nil {
+		expiresAt = *input.ExpiresAt
+		if !expiresAt.After(now) {
+			return nil, fmt.Errorf("%w: 有効期限には未来の日時を指定してください", ErrInvalidShareToken)
+		}
+		if expiresAt.Sub(now) > MaxShareTokenTTL {
+			return nil, fmt.Errorf("%w: 有効期限は%d日以内で指定してください", ErrInvalidShareToken, int(MaxShareTokenTTL.Hours()/24))
+		}
+	}
+	if _, err := findPlan(ctx, u.planRepo, input.PlanID); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("共有トークンの生成に失敗しました: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	shareToken := &model.ShareToken{
+		PlanID:    input.PlanID,
+		TokenHash: hashShareToken(token),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	}
+	if err := u.shareTokenRepo.CreateShareToken(ctx, shareToken); err != nil {
+		return nil, fmt.Errorf("共有リンクの保存に失敗しました: %w", err)
+	}
+
+	output := toShareTokenOutput(shareToken, now)
+	output.Token = token
+	output.Path = "/api/shared/" + token
+	return output, nil
+}
+
+// GetShareTokens は、計画の共有リンクの一覧を返します。トークンそのものは含みません。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *shareUsecase) GetShareTokens(ctx context.Context, planID string) ([]*ShareTokenOutput, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
+	tokens, err := u.shareTokenRepo.FindShareTokensByPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	output := make([]*ShareTokenOutput, len(tokens))
+	for i, token := range tokens {
+		output[i] = toShareTokenOutput(token, now)
+	}
+	return output, nil
+}
+
+// RevokeShareToken は、計画の共有リンクを無効にします。該当するリンクがない場合は ErrShareTokenNotFound を返します。
+func (u *shareUsecase) RevokeShareToken(ctx context.Context, planID, tokenID string) error {
+	return notFound(u.shareTokenRepo.RevokeShareToken(ctx, planID, tokenID, time.Now()), ErrShareTokenNotFound)
+}
+
+func (u *shareUsecase) AuthorizeShareToken(ctx context.Context, token string, required model.ShareScope) (*ShareAccess, error) {
+	shareToken, err := u.shareTokenRepo.FindShareTokenByHash(ctx, hashShareToken(token))
+	if err != nil {
+		return nil, notFound(err, ErrShareTokenNotFound)
+	}
+	if !shareToken.ActiveAt(time.Now()) {
+		return nil, ErrShareTokenExpired
+	}
+	if !shareToken.Scope.Allows(required) {
+		return nil, ErrShareScopeDenied
+	}
+	return &ShareAccess{PlanID: shareToken.PlanID, Scope: shareToken.Scope}, nil
+}
+
+// hashShareToken は、保存・照合に使うトークンのハッシュ（SHA-256の16進）を返します。
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func toShareTokenOutput(token *model.ShareToken, now time.Time) *ShareTokenOutput {
+	return &ShareTokenOutput{
+		ID:        token.ID,
+		Scope:     string(token.Scope),
+		ExpiresAt: token.ExpiresAt,
+		RevokedAt: token.RevokedAt,
+		Active:    token.ActiveAt(now),
+		CreatedAt: token.CreatedAt,
+	}
+}
@@ -0,0 +1,405 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+var (
+	// ErrInvalidShoppingItem は、追加・更新するアイテムの内容が不正な場合のエラーです。
+	ErrInvalidShoppingItem = newError(ErrValidation, "invalid_shopping_item", "買い物リストのアイテムが不正です")
+	// ErrRecipeShoppingItem は、レシピから計算したアイテムを手動のアイテムとして操作しようとした場合のエラーです。
+	ErrRecipeShoppingItem = newError(ErrConflict, "recipe_shopping_item", "レシピから計算したアイテムは削除できません")
+)
+
+// AddShoppingItemInput は、買い物リストに手動でアイテムを追加するための入力です。
+// Name がカタログの食材名（別名・表記ゆれを含む）に一致する場合はその食材として、一致しない場合は自由入力のアイテムとして追加します。
+type AddShoppingItemInput struct {
+	PlanID string
+	Name   string
+	Amount float64
+	Unit   string // 自由入力のアイテムの単位。カタログの食材は食材の単位で数えるため、指定する場合は一致している必要があります
+	Note   string
+	// TripDate は、買いに行く日（YYYY-MM-DD）です。省略した場合は計画の最初の買い物の日にします。
+	TripDate string
+}
+
+// AddShoppingItem は、買い物リストに手動のアイテムを追加します。
+// 同じ買い物の日に同じ食材（自由入力の場合は同じ名前と単位）の手動のアイテムがすでにある場合は、数量を足し合わせます。
+// 計画が存在しない場合は ErrPlanNotFound、買いに行く日の形式が不正な場合は ErrInvalidTripDate を返します。
+func (u *planUsecase) AddShoppingItem(ctx context.Context, input AddShoppingItemInput) (*IngredientListOutput, error) {
+	name, unit := strings.TrimSpace(input.Name), strings.TrimSpace(input.Unit)
+	if name == "" {
+		return nil, fmt.Errorf("%w: アイテム名が指定されていません", ErrInvalidShoppingItem)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("%w: 数量は0より大きい値を指定してください", ErrInvalidShoppingItem)
+	}
+	var tripDate *time.Time
+	if input.TripDate != "" {
+		date, err := parseTripDate(input.TripDate)
+		if err != nil {
+			return nil, err
+		}
+		tripDate = &date
+	}
+	plan, err := findPlan(ctx, u.planRepo, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	ing := newIngredientResolver(ingredients).resolve(name)
+	if ing != nil && unit != "" && unit != ing.Unit {
+		return nil, fmt.Errorf("%w: 食材「%s」は%sで数えます", ErrInvalidShoppingItem, ing.Name, ing.Unit)
+	}
+
+	return retryOnVersionConflict(func() (*IngredientListOutput, error) {
+		items, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, input.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		trip := firstTripDate(plan, items)
+		if tripDate != nil {
+			trip = *tripDate
+		}
+		var existing *model.ShoppingIngredientItem
+		for _, item := range items {
+			if item.Manual && sameTrip(item, trip) && sameManualItem(item, ing, name, unit) {
+				existing = item
+				break
+			}
+		}
+
+		item, eventType := existing, PlanEventItemsUpdated
+		if existing != nil {
+			existing.Amount = roundAmount(existing.Amount + input.Amount)
+			if input.Note != "" {
+				existing.Note = &input.Note
+			}
+		} else {
+			item, eventType = &model.ShoppingIngredientItem{PlanID: input.PlanID, Amount: roundAmount(input.Amount), TripDate: trip, Manual: true}, PlanEventItemsAdded
+			if ing != nil {
+				item.IngredientID = &ing.ID
+			} else {
+				item.Name = &name
+				if unit != "" {
+					item.Unit = &unit
+				}
+			}
+			if input.Note != "" {
+				item.Note = &input.Note
+			}
+		}
+
+		var planVersion int
+		err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+			if existing != nil {
+				// 他の処理が先に数量を変えていた場合は repository.ErrVersionConflict となり、読み込みからやり直す
+				if err := txRepo.UpdateShoppingIngredientItem(ctx, existing); err != nil {
+					return fmt.Errorf("アイテムの更新に失敗しました: %w", err)
+				}
+			} else if err := txRepo.CreateShoppingIngredientItems(ctx, []*model.ShoppingIngredientItem{item}); err != nil {
+				return fmt.Errorf("アイテムの追加に失敗しました: %w", err)
+			}
+			planVersion, err = txRepo.IncrementShoppingPlanVersion(ctx, input.PlanID, 0)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil && ing != nil {
+			item.Ingredient = *ing
+		}
+		output := toSingleIngredientListOutput(item)
+		u.publish(input.PlanID, planVersion, eventType, []*IngredientListOutput{output}, nil)
+		return output, nil
+	})
+}
+
+// DeleteShoppingItem は、手動で追加したアイテムを買い物リストから削除します。
+// レシピから計算したアイテムは削除できません（ErrRecipeShoppingItem）。アイテムが存在しない場合は ErrShoppingItemNotFound を返します。
+func (u *planUsecase) DeleteShoppingItem(ctx context.Context, itemID string) error {
+	item, err := u.planRepo.FindShoppingIngredientItemByID(ctx, itemID)
+	if err != nil {
+		return notFound(err, ErrShoppingItemNotFound)
+	}
+	if !item.Manual {
+		return ErrRecipeShoppingItem
+	}
+	var planVersion int
+	err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+		if err := txRepo.DeleteShoppingIngredientItem(ctx, itemID); err != nil {
+			return err
+		}
+		planVersion, err = txRepo.IncrementShoppingPlanVersion(ctx, item.PlanID, 0)
+		return err
+	})
+	if err != nil {
+		return notFound(err, ErrShoppingItemNotFound)
+	}
+	u.publish(item.PlanID, planVersion, PlanEventItemsDeleted, nil, []string{itemID})
+	return nil
+}
+
+// sameManualItem は、手動のアイテムが追加しようとしているアイテムと同じものかどうかを返します。
+// 自由入力のアイテムは、正規化した名前と単位が一致する場合に同じとみなします。
+func sameManualItem(item *model.ShoppingIngredientItem, ing *model.Ingredient, name, unit string) bool {
+	if ing != nil {
+		return item.IngredientID != nil && *item.IngredientID == ing.ID
+	}
+	return item.IngredientID == nil &&
+		model.NormalizeIngredientName(item.DisplayName()) == model.NormalizeIngredientName(name) &&
+		item.DisplayUnit() == unit
+}
+
+// applyShoppingItemUpdate は、アイテムに更新内容を反映します。
+// 購入済みかどうかが指定されず、購入した量が記録されている場合は、購入した量が必要量に達しているかで購入済みかどうかを決めます。
+// 購入済みを外した場合は、購入した量も同時に指定しない限り購入の記録を消します。
+func applyShoppingItemUpdate(item *model.ShoppingIngredientItem, input UpdateShoppingIngredientItemInput, now time.Time) error {
+	if input.Bought == nil && input.Amount == nil && input.PurchasedAmount == nil {
+		return fmt.Errorf("%w: 更新する項目が指定されていません", ErrInvalidShoppingItem)
+	}
+	if input.Amount != nil && *input.Amount <= 0 {
+		return fmt.Errorf("%w: 必要量は0より大きい値を指定してください", ErrInvalidShoppingItem)
+	}
+	if input.PurchasedAmount != nil && *input.PurchasedAmount < 0 {
+		return fmt.Errorf("%w: 購入した量は0以上の値を指定してください", ErrInvalidShoppingItem)
+	}
+
+	if input.Amount != nil {
+		item.Amount = roundAmount(*input.Amount)
+	}
+	if input.PurchasedAmount != nil {
+		purchased := roundAmount(*input.PurchasedAmount)
+		item.PurchasedAmount = &purchased
+	}
+
+	switch {
+	case input.Bought != nil:
+		item.Bought = *input.Bought
+		if !item.Bought && input.PurchasedAmount == nil {
+			item.PurchasedAmount = nil
+		}
+	case item.PurchasedAmount != nil:
+		item.Bought = *item.PurchasedAmount >= item.Amount
+	}
+
+	if item.Purchased() == 0 {
+		item.PurchasedAt = nil
+	} else if item.PurchasedAt == nil {
+		item.PurchasedAt = &now
+	}
+	return nil
+}
+
+// LeftoverOutput は、必要量を超えて購入した食材（残りもの）です。
+type LeftoverOutput struct {
+	ItemID                string     `json:"item_id"`
+	Name                  string     `json:"name"`
+	Type                  string     `json:"type"`
+	Amount                float64    `json:"amount"` // 必要量を超えて購入した量
+	Unit                  string     `json:"unit"`
+	PurchasedAt           *time.Time `json:"purchased_at"`
+	ShelfLifeDaysUnopened *int       `json:"shelf_life_days_unopened"`
+	ShelfLifeDaysOpened   *int       `json:"shelf_life_days_opened"`
+	UseBy                 *string    `json:"use_by"` // 購入日と未開封での日持ちから見積もった使い切る目安の日付
+}
+
+// GetLeftovers は、計画の買い物で必要量を超えて購入した食材を、使い切る目安の日付が近い順に返します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) GetLeftovers(ctx context.Context, planID string) ([]*LeftoverOutput, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
+	items, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	output := []*LeftoverOutput{}
+	for _, item := range items {
+		excess := roundAmount(item.Excess())
+		if excess == 0 {
+			continue
+		}
+		leftover := &LeftoverOutput{
+			ItemID:      item.ID,
+			Name:        item.DisplayName(),
+			Type:        item.Ingredient.IngredientType.Name,
+			Amount:      excess,
+			Unit:        item.DisplayUnit(),
+			PurchasedAt: item.PurchasedAt,
+		}
+		if item.IngredientID != nil {
+			leftover.ShelfLifeDaysUnopened = item.Ingredient.ShelfLifeDaysUnopened
+			leftover.ShelfLifeDaysOpened = item.Ingredient.ShelfLifeDaysOpened
+		}
+		if item.PurchasedAt != nil && leftover.ShelfLifeDaysUnopened != nil {
+			useBy := item.PurchasedAt.AddDate(0, 0, *leftover.ShelfLifeDaysUnopened).Format("2006-01-02")
+			leftover.UseBy = &useBy
+		}
+		output = append(output, leftover)
+	}
+	// 使い切る目安の日付が分からないものは最後にする
+	sort.SliceStable(output, func(i, j int) bool {
+		a, b := output[i], output[j]
+		if (a.UseBy == nil) != (b.UseBy == nil) {
+			return b.UseBy == nil
+		}
+		if a.UseBy != nil && *a.UseBy != *b.UseBy {
+			return *a.UseBy < *b.UseBy
+		}
+		return a.Name < b.Name
+	})
+	return output, nil
+}
+
+// MaxShoppingItemBatchSize は、一度にまとめて更新できるアイテムの最大数です。
+const MaxShoppingItemBatchSize = 200
+
+// ErrShoppingItemBatchRejected は、まとめて更新するアイテムのいずれかを更新できないため、どのアイテムも更新しなかった場合のエラーです。
+var ErrShoppingItemBatchRejected = newError(ErrValidation, "shopping_items_rejected", "更新できないアイテムがあるため、どのアイテムも更新しませんでした")
+
+// BatchUpdateShoppingItemsInput は、計画の買い物リストのアイテムをまとめて更新するための入力です。
+// アイテムごとの ExpectedVersion のほかに、計画の版（ExpectedPlanVersion）を指定すると、買い物リストが変わっていない場合だけ更新します。
+type BatchUpdateShoppingItemsInput struct {
+	PlanID              string
+	Items               []UpdateShoppingIngredientItemInput
+	ExpectedPlanVersion *int
+}
+
+// BatchUpdateShoppingItemsOutput は、アイテムをまとめて更新した結果です。
+type BatchUpdateShoppingItemsOutput struct {
+	PlanVersion int                   `json:"plan_version"` // 更新後の計画の版（更新しなかった場合は現在の版）
+	Results     []*ShoppingItemResult `json:"results"`
+}
+
+// ShoppingItemResult は、まとめて更新したアイテムごとの結果です。
+type ShoppingItemResult struct {
+	ItemID string                `json:"item_id"`
+	Status string                `json:"status"` // "updated"、"rejected"（他のアイテムのために更新しなかったものは "skipped"）
+	Error  *string               `json:"error"`
+	Item   *IngredientListOutput `json:"item"` // 更新後のアイテム（更新した場合のみ）
+}
+
+const (
+	shoppingItemUpdated  = "updated"
+	shoppingItemRejected = "rejected"
+	shoppingItemSkipped  = "skipped"
+)
+
+// BatchUpdateShoppingItems は、計画の買い物リストのアイテムを1つのトランザクションでまとめて更新します。
+// 計画に属さないアイテムや内容が不正なアイテムが1つでもある場合は、どのアイテムも更新せず、
+// アイテムごとの結果とともに ErrShoppingItemBatchRejected を返します（版が一致しないアイテムがある場合は ErrVersionConflict も返します）。
+// 計画の版が input.ExpectedPlanVersion と一致しない場合は ErrVersionConflict を、計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) BatchUpdateShoppingItems(ctx context.Context, input BatchUpdateShoppingItemsInput) (*BatchUpdateShoppingItemsOutput, error) {
+	if len(input.Items) == 0 {
+		return nil, fmt.Errorf("%w: 更新するアイテムが指定されていません", ErrInvalidShoppingItem)
+	}
+	if len(input.Items) > MaxShoppingItemBatchSize {
+		return nil, fmt.Errorf("%w: 一度に更新できるアイテムは%d件までです", ErrInvalidShoppingItem, MaxShoppingItemBatchSize)
+	}
+	return retryOnVersionConflict(func() (*BatchUpdateShoppingItemsOutput, error) {
+		return u.batchUpdateShoppingItems(ctx, input)
+	})
+}
+
+// batchUpdateShoppingItems は、BatchUpdateShoppingItems の読み込みから保存までを1回行います。
+func (u *planUsecase) batchUpdateShoppingItems(ctx context.Context, input BatchUpdateShoppingItemsInput) (*BatchUpdateShoppingItemsOutput, error) {
+	plan, err := findPlan(ctx, u.planRepo, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(input.ExpectedPlanVersion, plan.Version); err != nil {
+		return nil, err
+	}
+	items, err := u.planRepo.FindShoppingIngredientsByPlanID(ctx, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*model.ShoppingIngredientItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	now := time.Now()
+	results := make([]*ShoppingItemResult, len(input.Items))
+	updated := make([]*model.ShoppingIngredientItem, 0, len(input.Items))
+	seen := make(map[string]bool, len(input.Items))
+	rejected, conflicted := false, false
+	for i, in := range input.Items {
+		results[i] = &ShoppingItemResult{ItemID: in.ItemID}
+		item, ok := byID[in.ItemID]
+		switch {
+		case !ok:
+			err = fmt.Errorf("%w: この計画の買い物リストにないアイテムです", ErrInvalidShoppingItem)
+		case seen[in.ItemID]:
+			err = fmt.Errorf("%w: 同じアイテムが複数指定されています", ErrInvalidShoppingItem)
+		default:
+			if err = checkVersion(in.ExpectedVersion, item.Version); err == nil {
+				err = applyShoppingItemUpdate(item, in, now)
+			}
+		}
+		seen[in.ItemID] = true
+		if err != nil {
+			msg := err.Error()
+			results[i].Status, results[i].Error = shoppingItemRejected, &msg
+			rejected = true
+			conflicted = conflicted || errors.Is(err, ErrVersionConflict)
+			continue
+		}
+		updated = append(updated, item)
+		results[i].Status = shoppingItemUpdated
+	}
+
+	if rejected {
+		for _, r := range results {
+			if r.Status == shoppingItemUpdated {
+				r.Status = shoppingItemSkipped
+			}
+		}
+		output := &BatchUpdateShoppingItemsOutput{PlanVersion: plan.Version, Results: results}
+		if conflicted {
+			return output, fmt.Errorf("%w: %w", ErrShoppingItemBatchRejected, ErrVersionConflict)
+		}
+		return output, ErrShoppingItemBatchRejected
+	}
+
+	// 読み込んだ後に他の処理がアイテム（計画の版を指定した場合は計画）を更新していた場合は
+	// repository.ErrVersionConflict となり、読み込みからやり直す
+	var expectedPlanVersion, planVersion int
+	if input.ExpectedPlanVersion != nil {
+		expectedPlanVersion = plan.Version
+	}
+	err = u.planRepo.Transaction(ctx, func(txRepo repository.PlanRepository) error {
+		if err := txRepo.UpdateShoppingIngredientItemStates(ctx, updated); err != nil {
+			return err
+		}
+		planVersion, err = txRepo.IncrementShoppingPlanVersion(ctx, input.PlanID, expectedPlanVersion)
+		return err
+	})
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("アイテムの更新に失敗しました: %w", err)
+	}
+	outputs := make([]*IngredientListOutput, len(input.Items))
+	for i, in := range input.Items {
+		outputs[i] = toSingleIngredientListOutput(byID[in.ItemID])
+		results[i].Item = outputs[i]
+	}
+	u.publish(input.PlanID, planVersion, PlanEventItemsUpdated, outputs, nil)
+	return &BatchUpdateShoppingItemsOutput{PlanVersion: planVersion, Results: results}, nil
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"sort"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// unassignedSectionName は、店舗レイアウトのどの売り場にも割り当てられていない食材をまとめる売り場の名前です。
+const unassignedSectionName = "その他"
+
+type GetIngredientListInput struct {
+	PlanID   string
+	LayoutID string // 省略した場合は食材分類ごとにまとめます
+	TripDate string // 買いに行く日（YYYY-MM-DD）。指定した場合は、その日に買うアイテムだけを返します
+}
+
+// ShoppingListOutput は、売り場ごとにまとめた買い物リストです。
+// Ingredients にはすべてのアイテムを Sections と同じ順（店内を歩く順）で入れます。
+type ShoppingListOutput struct {
+	LayoutID    *string                      `json:"layout_id"`
+	Version     int                          `json:"version"`   // 計画の版。買い物リストが変わるたびに1つ進む
+	TripDate    *string                      `json:"trip_date"` // 絞り込んだ買いに行く日（絞り込んでいない場合は null）
+	Trips       []string                     `json:"trips"`     // 計画の買い物に行く日（早い順、絞り込みに関係なくすべて）
+	Ingredients []*IngredientListOutput      `json:"ingredients"`
+	Sections    []*ShoppingListSectionOutput `json:"sections"`
+}
+
+type ShoppingListSectionOutput struct {
+	Name        string                  `json:"name"`
+	Ingredients []*IngredientListOutput `json:"ingredients"`
+}
+
+// groupShoppingList は、買い物リストのアイテムを売り場ごとにまとめ、歩く順に並べます。
+// layout が nil の場合は、食材分類を売り場とみなして分類名の順（「その他」は最後）に並べます。
+// 自由入力のアイテムは、どちらの場合も「その他」の売り場にまとめます。
+// 売り場の中では、レイアウトで指定された順、食材名の順、買いに行く日の順に並べます。
+func groupShoppingList(items []*model.ShoppingIngredientItem, layout *model.StoreLayout) *ShoppingListOutput {
+	type placed struct {
+		item *model.ShoppingIngredientItem
+		slot storeSlot
+	}
+	var sectionNames []string
+	var entries []placed
+
+	if layout != nil {
+		idx := newStoreSectionIndex(layout)
+		for _, section := range layout.Sections {
+			sectionNames = append(sectionNames, section.Name)
+		}
+		unassigned := len(sectionNames)
+		for _, item := range items {
+			slot, ok := storeSlot{}, false
+			if item.IngredientID != nil {
+				slot, ok = idx.lookup(&item.Ingredient)
+			}
+			if !ok {
+				slot = storeSlot{section: unassigned}
+			}
+			entries = append(entries, placed{item: item, slot: slot})
+		}
+		sectionNames = append(sectionNames, unassignedSectionName)
+	} else {
+		typeNames := make(map[string]bool)
+		for _, item := range items {
+			typeNames[itemTypeName(item)] = true
+		}
+		for name := range typeNames {
+			sectionNames = append(sectionNames, name)
+		}
+		// 「その他」はレイアウトを使う場合と同じく最後にする
+		sort.Slice(sectionNames, func(i, j int) bool {
+			a, b := sectionNames[i], sectionNames[j]
+			if (a == unassignedSectionName) != (b == unassignedSectionName) {
+				return b == unassignedSectionName
+			}
+			return a < b
+		})
+		sectionIndex := make(map[string]int, len(sectionNames))
+		for i, name := range sectionNames {
+			sectionIndex[name] = i
+		}
+		for _, item := range items {
+			entries = append(entries, placed{item: item, slot: storeSlot{section: sectionIndex[itemTypeName(item)]}})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.slot.section != b.slot.section {
+			return a.slot.section < b.slot.section
+		}
+		if a.slot.position != b.slot.position {
+			return a.slot.position < b.slot.position
+		}
+		if a.item.DisplayName() != b.item.DisplayName() {
+			return a.item.DisplayName() < b.item.DisplayName()
+		}
+		return a.item.TripDate.Before(b.item.TripDate)
+	})
+
+	output := &ShoppingListOutput{Ingredients: []*IngredientListOutput{}, Sections: []*ShoppingListSectionOutput{}}
+	if layout != nil {
+		output.LayoutID = &layout.ID
+	}
+	sections := make([]*ShoppingListSectionOutput, len(sectionNames))
+	for _, e := range entries {
+		ing := toSingleIngredientListOutput(e.item)
+		output.Ingredients = append(output.Ingredients, ing)
+		if sections[e.slot.section] == nil {
+			sections[e.slot.section] = &ShoppingListSectionOutput{Name: sectionNames[e.slot.section]}
+		}
+		sections[e.slot.section].Ingredients = append(sections[e.slot.section].Ingredients, ing)
+	}
+	// 買うものがない売り場は出力しない
+	for _, section := range sections {
+		if section != nil {
+			output.Sections = append(output.Sections, section)
+		}
+	}
+	return output
+}
+
+// itemTypeName は、店舗レイアウトを使わない場合にアイテムをまとめる売り場の名前（食材分類名）を返します。
+// 自由入力のアイテムは分類を持たないため「その他」にまとめます。
+func itemTypeName(item *model.ShoppingIngredientItem) string {
+	if item.IngredientID == nil {
+		return unassignedSectionName
+	}
+	return item.Ingredient.IngredientType.Name
+}
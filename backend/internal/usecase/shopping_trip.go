@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// tripDateLayout は、買いに行く日の表記（YYYY-MM-DD）です。
+const tripDateLayout = "2006-01-02"
+
+// ErrInvalidTripDate は、買いに行く日の指定が不正な場合のエラーです。
+var ErrInvalidTripDate = newError(ErrValidation, "invalid_trip_date", "買いに行く日の指定が不正です")
+
+// ingredientUse は、献立で食材を使う日（計画の開始日からの日数）と、その食材の未開封での日持ちの日数です。
+type ingredientUse struct {
+	day       int
+	shelfLife *int // 日持ちが設定されていない食材は nil
+}
+
+// earliestBuyDay は、使う日に日持ちが間に合う最も早い買い物の日を返します。計画の開始日より前にはしません。
+func (use ingredientUse) earliestBuyDay() int {
+	if use.shelfLife == nil {
+		return 0
+	}
+	return max(use.day-*use.shelfLife, 0)
+}
+
+// scheduleTrips は、食材を使う日と日持ちから、買い物に行く日（計画の開始日からの日数）を昇順で返します。
+// 計画の開始日には必ず買い物に行き、日持ちが間に合わない食材がある場合だけ、その食材を使う日に追加の買い物を入れます。
+// 追加する買い物は、すべての食材の日持ちが間に合う範囲で最も少なくなります。
+func scheduleTrips(uses []ingredientUse) []int {
+	sorted := slices.Clone(uses)
+	slices.SortFunc(sorted, func(a, b ingredientUse) int { return a.day - b.day })
+
+	trips := []int{0}
+	for _, use := range sorted {
+		// 使う日の早い順に見ているため、最後に入れた買い物が使う日までで最も遅い回になる
+		if last := trips[len(trips)-1]; last >= use.earliestBuyDay() {
+			continue
+		}
+		// できるだけ多くの後の食材にも間に合うよう、使う日ちょうどに買いに行く
+		trips = append(trips, use.day)
+	}
+	return trips
+}
+
+// assignTrip は、食材を使う日に対して、その食材を買う回（trips の要素）を返します。
+// trips は昇順で、最初の回が使う日以前である必要があります（normalizeTripDays で確かめます）。
+// 日持ちが間に合う回のうち最も早い回にまとめます。間に合う回が無い場合は使う日以前の最も遅い回にします。
+func assignTrip(trips []int, use ingredientUse) int {
+	latest := trips[0]
+	for _, t := range trips {
+		if t > use.day {
+			break
+		}
+		if t >= use.earliestBuyDay() {
+			return t
+		}
+		latest = t
+	}
+	return latest
+}
+
+// normalizeTripDays は、指定された買い物に行く日（計画の開始日からの日数）を重複を除いて昇順にします。
+// 指定が無い場合は、食材を使う日と日持ちから決めます。
+// 計画の期間（開始日から lastDay 日後まで）の外の日や、最初の回が食材を最初に使う日より後になる指定は ErrInvalidTripDate とします。
+func normalizeTripDays(days []int, lastDay int, uses []ingredientUse) ([]int, error) {
+	if len(days) == 0 {
+		return scheduleTrips(uses), nil
+	}
+	for _, day := range days {
+		if day < 0 || day > lastDay {
+			return nil, fmt.Errorf("%w: 買い物に行く日は計画の期間（0〜%d日後）で指定してください", ErrInvalidTripDate, lastDay)
+		}
+	}
+	days = slices.Clone(days)
+	slices.Sort(days)
+	days = slices.Compact(days)
+
+	// 最初の回より前に使う食材は、どの回で買っても間に合わない
+	for _, use := range uses {
+		if use.day < days[0] {
+			return nil, fmt.Errorf("%w: %d日後に使う食材を買う回がありません（最初の回を%d日後以前にしてください）", ErrInvalidTripDate, use.day, use.day)
+		}
+	}
+	return days, nil
+}
+
+// parseTripDate は、YYYY-MM-DD 形式の買いに行く日を解析します。
+func parseTripDate(s string) (time.Time, error) {
+	date, err := time.ParseInLocation(tripDateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %s（YYYY-MM-DD の形式で指定してください）", ErrInvalidTripDate, s)
+	}
+	return date, nil
+}
+
+// formatTripDate は、買いに行く日を YYYY-MM-DD 形式で返します。
+func formatTripDate(date time.Time) string {
+	return date.Format(tripDateLayout)
+}
+
+// formatOptionalDate は、日付を YYYY-MM-DD 形式で返します。nil の場合は nil です。
+func formatOptionalDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	s := formatTripDate(*date)
+	return &s
+}
+
+// sameTrip は、アイテムを買いに行く日が指定された日と同じかどうかを返します。
+func sameTrip(item *model.ShoppingIngredientItem, date time.Time) bool {
+	return formatTripDate(item.TripDate) == formatTripDate(date)
+}
+
+// tripDates は、アイテムを買いに行く日を、重複を除いて早い順に返します。
+func tripDates(items []*model.ShoppingIngredientItem) []string {
+	dates := []string{}
+	for _, item := range items {
+		dates = append(dates, formatTripDate(item.TripDate))
+	}
+	slices.Sort(dates)
+	return slices.Compact(dates)
+}
+
+// firstTripDate は、計画の最初の買い物の日を返します。アイテムが無い場合は計画の開始日です。
+func firstTripDate(plan *model.ShoppingPlan, items []*model.ShoppingIngredientItem) time.Time {
+	first := plan.PeriodStartAt
+	for i, item := range items {
+		if i == 0 || item.TripDate.Before(first) {
+			first = item.TripDate
+		}
+	}
+	return first
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// shelfLife は、日持ちの日数のポインタを返します。
+func shelfLife(days int) *int { return &days }
+
+func TestScheduleTrips(t *testing.T) {
+	tests := []struct {
+		name string
+		uses []ingredientUse
+		want []int
+	}{
+		{name: "食材なし", uses: nil, want: []int{0}},
+		{name: "日持ちが設定されていない", uses: []ingredientUse{{day: 10}, {day: 20}}, want: []int{0}},
+		{name: "日持ちが間に合う", uses: []ingredientUse{{day: 3, shelfLife: shelfLife(5)}}, want: []int{0}},
+		{name: "ちょうど間に合う", uses: []ingredientUse{{day: 3, shelfLife: shelfLife(3)}}, want: []int{0}},
+		{name: "間に合わない食材を使う日に買い足す", uses: []ingredientUse{{day: 5, shelfLife: shelfLife(2)}}, want: []int{0, 5}},
+		{
+			name: "追加の買い物で後の食材にも間に合う",
+			uses: []ingredientUse{{day: 5, shelfLife: shelfLife(2)}, {day: 6, shelfLife: shelfLife(3)}, {day: 7, shelfLife: shelfLife(2)}},
+			want: []int{0, 5},
+		},
+		{
+			name: "追加の買い物が複数回",
+			uses: []ingredientUse{{day: 9, shelfLife: shelfLife(1)}, {day: 1}, {day: 5, shelfLife: shelfLife(2)}},
+			want: []int{0, 5, 9},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := append([]ingredientUse(nil), tt.uses...)
+			if got := scheduleTrips(tt.uses); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scheduleTrips() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.uses, original) {
+				t.Errorf("scheduleTrips() modified the uses: %v", tt.uses)
+			}
+		})
+	}
+}
+
+func TestAssignTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		trips []int
+		use   ingredientUse
+		want  int
+	}{
+		{name: "日持ちが設定されていない食材は最初の回", trips: []int{0, 5, 9}, use: ingredientUse{day: 7}, want: 0},
+		{name: "間に合う最も早い回", trips: []int{0, 5, 9}, use: ingredientUse{day: 10, shelfLife: shelfLife(6)}, want: 5},
+		{name: "使う日当日の回", trips: []int{0, 5, 9}, use: ingredientUse{day: 9, shelfLife: shelfLife(0)}, want: 9},
+		{name: "使う日より後の回にはしない", trips: []int{0, 5, 9}, use: ingredientUse{day: 4, shelfLife: shelfLife(1)}, want: 0},
+		{name: "間に合う回が無い場合は使う日より前の最も遅い回", trips: []int{0, 5, 9}, use: ingredientUse{day: 8, shelfLife: shelfLife(1)}, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := assignTrip(tt.trips, tt.use); got != tt.want {
+				t.Errorf("assignTrip(%v, %+v) = %d, want %d", tt.trips, tt.use, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTripDays(t *testing.T) {
+	uses := []ingredientUse{{day: 5, shelfLife: shelfLife(2)}}
+
+	tests := []struct {
+		name    string
+		days    []int
+		want    []int
+		wantErr error
+	}{
+		{name: "指定なしは日持ちから決める", days: nil, want: []int{0, 5}},
+		{name: "重複を除いて昇順にする", days: []int{6, 0, 6, 3}, want: []int{0, 3, 6}},
+		{name: "開始日より前", days: []int{0, -1}, wantErr: ErrInvalidTripDate},
+		{name: "計画の期間より後", days: []int{0, 8}, wantErr: ErrInvalidTripDate},
+		{name: "計画の最終日は指定できる", days: []int{0, 7}, want: []int{0, 7}},
+		{name: "最初の回が使う日当日", days: []int{5}, want: []int{5}},
+		{name: "最初に使う日より後の回だけ", days: []int{6, 7}, wantErr: ErrInvalidTripDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTripDays(tt.days, 7, uses)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeTripDays(%v) = %v, %v, want %v, %v", tt.days, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+const (
+	// spendMonthLayout は、支出の集計の月の表記（YYYY-MM）です。
+	spendMonthLayout = "2006-01"
+	// DefaultSpendMonths は、期間を指定せずに月ごとの支出を取得した場合の月数（今月まで）です。
+	DefaultSpendMonths = 12
+	// MaxSpendMonths は、月ごとの支出を一度に取得できる月数の上限です。
+	MaxSpendMonths = 36
+)
+
+// ErrInvalidSpendPeriod は、支出を集計する期間の指定が不正な場合のエラーです。
+var ErrInvalidSpendPeriod = newError(ErrInvalidRequest, "invalid_spend_period", "支出を集計する期間の指定が不正です")
+
+// PlanSpendOutput は、計画に記録したレシートの支出の集計です。
+type PlanSpendOutput struct {
+	PlanID       string                   `json:"plan_id"`
+	Total        int                      `json:"total"` // 支払った金額の合計（円）
+	ReceiptCount int                      `json:"receipt_count"`
+	ByType       []*SpendByTypeOutput     `json:"by_type"`       // 食材分類ごとの支出（食材に対応しない品物は「その他」）
+	ByIngredient []*IngredientSpendOutput `json:"by_ingredient"` // 食材ごとの支出と実際の単価
+}
+
+// SpendByTypeOutput は、食材分類ごとの支出です。
+type SpendByTypeOutput struct {
+	Name  string `json:"name"`
+	Total int    `json:"total"`
+}
+
+// IngredientSpendOutput は、食材ごとの支出と、レシートから求めた実際の単価です。
+type IngredientSpendOutput struct {
+	IngredientID string   `json:"ingredient_id"`
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	Unit         string   `json:"unit"`
+	Total        int      `json:"total"`
+	Quantity     *float64 `json:"quantity"`           // 食材の単位で分かる購入した量の合計（分からない場合は null）
+	AveragePrice *float64 `json:"average_unit_price"` // 食材の単位あたりの平均価格（量が分かる行から求める）
+}
+
+// GetMonthlySpendInput は、月ごとの支出を取得する期間です。
+type GetMonthlySpendInput struct {
+	From string // 最初の月（YYYY-MM）。省略した場合は To の DefaultSpendMonths-1 か月前
+	To   string // 最後の月（YYYY-MM）。省略した場合は今月
+}
+
+// MonthlySpendOutput は、1か月のすべての計画の支出の集計です。
+type MonthlySpendOutput struct {
+	Month        string               `json:"month"` // YYYY-MM
+	Total        int                  `json:"total"`
+	ReceiptCount int                  `json:"receipt_count"`
+	PlanCount    int                  `json:"plan_count"` // レシートを記録した計画の数
+	ByType       []*SpendByTypeOutput `json:"by_type"`
+}
+
+// GetPlanSpend は、計画に記録したレシートの支出を、食材分類ごと・食材ごとに集計します。
+// 計画が存在しない場合は ErrPlanNotFound を返します。
+func (u *planUsecase) GetPlanSpend(ctx context.Context, planID string) (*PlanSpendOutput, error) {
+	if _, err := findPlan(ctx, u.planRepo, planID); err != nil {
+		return nil, err
+	}
+	receipts, err := u.planRepo.FindReceiptsByPlanID(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := newSpendTally()
+	type ingredientTally struct {
+		output        *IngredientSpendOutput
+		quantity      float64
+		quantityPrice int // 量が分かる行の金額の合計
+	}
+	byIngredient := make(map[string]*ingredientTally)
+	for _, receipt := range receipts {
+		tally.addReceipt(receipt)
+		for i := range receipt.Lines {
+			line := &receipt.Lines[i]
+			if line.Ingredient == nil {
+				continue
+			}
+			t, ok := byIngredient[line.Ingredient.ID]
+			if !ok {
+				t = &ingredientTally{output: &IngredientSpendOutput{
+					IngredientID: line.Ingredient.ID,
+					Name:         line.Ingredient.Name,
+					Type:         line.Ingredient.IngredientType.Name,
+					Unit:         line.Ingredient.Unit,
+				}}
+				byIngredient[line.Ingredient.ID] = t
+			}
+			t.output.Total += line.Price
+			if line.UnitPrice() != nil {
+				t.quantity += *line.Quantity
+				t.quantityPrice += line.Price
+			}
+		}
+	}
+
+	output := &PlanSpendOutput{
+		PlanID:       planID,
+		Total:        tally.total,
+		ReceiptCount: len(receipts),
+		ByType:       tally.byTypeOutput(),
+		ByIngredient: []*IngredientSpendOutput{},
+	}
+	for _, t := range byIngredient {
+		if t.quantity > 0 {
+			quantity, average := roundAmount(t.quantity), roundAmount(float64(t.quantityPrice)/t.quantity)
+			t.output.Quantity, t.output.AveragePrice = &quantity, &average
+		}
+		output.ByIngredient = append(output.ByIngredient, t.output)
+	}
+	// 支出の多い順（同じ場合は食材名の順）に並べる
+	sort.Slice(output.ByIngredient, func(i, j int) bool {
+		a, b := output.ByIngredient[i], output.ByIngredient[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Name < b.Name
+	})
+	return output, nil
+}
+
+// GetMonthlySpend は、すべての計画のレシートの支出を、買い物をした月ごとに集計します。
+// レシートの無い月も0円として、期間のすべての月を古い順に返します。期間の指定が不正な場合は ErrInvalidSpendPeriod を返します。
+func (u *planUsecase) GetMonthlySpend(ctx context.Context, input GetMonthlySpendInput) ([]*MonthlySpendOutput, error) {
+	from, to, err := spendPeriod(input, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := u.planRepo.FindReceiptsPurchasedBetween(ctx, from, to.AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	type monthTally struct {
+		tally        *spendTally
+		receiptCount int
+		plans        map[string]bool
+	}
+	months := make(map[string]*monthTally)
+	for _, receipt := range receipts {
+		key := receipt.PurchasedAt.In(time.Local).Format(spendMonthLayout)
+		m, ok := months[key]
+		if !ok {
+			m = &monthTally{tally: newSpendTally(), plans: make(map[string]bool)}
+			months[key] = m
+		}
+		m.tally.addReceipt(receipt)
+		m.receiptCount++
+		m.plans[receipt.PlanID] = true
+	}
+
+	var outputs []*MonthlySpendOutput
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		key := month.Format(spendMonthLayout)
+		output := &MonthlySpendOutput{Month: key, ByType: []*SpendByTypeOutput{}}
+		if m, ok := months[key]; ok {
+			output.Total, output.ReceiptCount, output.PlanCount = m.tally.total, m.receiptCount, len(m.plans)
+			output.ByType = m.tally.byTypeOutput()
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// spendPeriod は、月ごとの支出を集計する期間の最初の月と最後の月（どちらも月初の0時）を返します。
+func spendPeriod(input GetMonthlySpendInput, now time.Time) (from, to time.Time, err error) {
+	to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if input.To != "" {
+		if to, err = time.ParseInLocation(spendMonthLayout, input.To, time.Local); err != nil {
+			return from, to, fmt.Errorf("%w: to は YYYY-MM の形式で指定してください", ErrInvalidSpendPeriod)
+		}
+	}
+	from = to.AddDate(0, -(DefaultSpendMonths - 1), 0)
+	if input.From != "" {
+		if from, err = time.ParseInLocation(spendMonthLayout, input.From, time.Local); err != nil {
+			return from, to, fmt.Errorf("%w: from は YYYY-MM の形式で指定してください", ErrInvalidSpendPeriod)
+		}
+	}
+	switch {
+	case from.After(to):
+		return from, to, fmt.Errorf("%w: from は to 以前の月を指定してください", ErrInvalidSpendPeriod)
+	case from.AddDate(0, MaxSpendMonths, 0).Before(to.AddDate(0, 1, 0)):
+		return from, to, fmt.Errorf("%w: 一度に取得できるのは%dか月分までです", ErrInvalidSpendPeriod, MaxSpendMonths)
+	}
+	return from, to, nil
+}
+
+// spendTally は、レシートの支出の合計と食材分類ごとの内訳を集計します。
+type spendTally struct {
+	total  int
+	byType map[string]int
+}
+
+func newSpendTally() *spendTally {
+	return &spendTally{byType: make(map[string]int)}
+}
+
+// addReceipt は、レシートの行の金額を集計に加えます。食材に対応しない行は「その他」に数えます。
+func (t *spendTally) addReceipt(receipt *model.Receipt) {
+	for i := range receipt.Lines {
+		line := &receipt.Lines[i]
+		typeName := unassignedSectionName
+		if line.Ingredient != nil && line.Ingredient.IngredientType.Name != "" {
+			typeName = line.Ingredient.IngredientType.Name
+		}
+		t.total += line.Price
+		t.byType[typeName] += line.Price
+	}
+}
+
+// byTypeOutput は、食材分類ごとの支出を多い順（同じ場合は分類名の順）に返します。
+func (t *spendTally) byTypeOutput() []*SpendByTypeOutput {
+	outputs := []*SpendByTypeOutput{}
+	for name, total := range t.byType {
+		outputs = append(outputs, &SpendByTypeOutput{Name: name, Total: total})
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].Total != outputs[j].Total {
+			return outputs[i].Total > outputs[j].Total
+		}
+		return outputs[i].Name < outputs[j].Name
+	})
+	return outputs
+}
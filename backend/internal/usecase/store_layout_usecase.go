@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"meal-compass/backend/internal/domain/model"
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// --- DTO (Data Transfer Object) Definitions ---
+
+type SaveStoreLayoutInput struct {
+	LayoutID string // 更新する場合にのみ指定します
+	Name     string
+	Sections []*StoreSectionInput // 歩く順に並べた売り場
+}
+
+// StoreSectionInput は、売り場1つと、そこに置かれている食材分類・食材の名前です。
+// 食材は別名や表記ゆれでも指定できます。
+type StoreSectionInput struct {
+	Name            string
+	IngredientTypes []string
+	Ingredients     []string
+}
+
+type StoreLayoutOutput struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Sections []*StoreSectionOutput `json:"sections"`
+}
+
+type StoreSectionOutput struct {
+	Name            string   `json:"name"`
+	IngredientTypes []string `json:"ingredient_types"`
+	Ingredients     []string `json:"ingredients"`
+}
+
+// ErrInvalidStoreLayout は、店舗レイアウトの内容が不正な場合に返されます。
+var ErrInvalidStoreLayout = newError(ErrValidation, "invalid_store_layout", "店舗レイアウトの内容が不正です")
+
+// ErrStoreLayoutNameConflict は、同じ名前の店舗レイアウトが既に登録されている場合に返されます。
+var ErrStoreLayoutNameConflict = newError(ErrConflict, "store_layout_name_conflict", "同じ名前の店舗レイアウトが登録されています")
+
+// --- Usecase Interface ---
+
+// StoreLayoutUsecase は、店舗の売り場の並びに関するビジネスロジックのインターフェースです。
+type StoreLayoutUsecase interface {
+	CreateStoreLayout(ctx context.Context, input SaveStoreLayoutInput) (*StoreLayoutOutput, error)
+	UpdateStoreLayout(ctx context.Context, input SaveStoreLayoutInput) (*StoreLayoutOutput, error)
+	DeleteStoreLayout(ctx context.Context, layoutID string) error
+	GetStoreLayouts(ctx context.Context) ([]*StoreLayoutOutput, error)
+	GetStoreLayout(ctx context.Context, layoutID string) (*StoreLayoutOutput, error)
+}
+
+// --- Usecase Implementation ---
+
+// storeLayoutUsecase は StoreLayoutUsecase インターフェースの実装です。
+type storeLayoutUsecase struct {
+	storeLayoutRepo repository.StoreLayoutRepository
+	ingredientRepo  repository.IngredientRepository
+}
+
+// NewStoreLayoutUsecase は新しい storeLayoutUsecase のインスタンスを生成します。
+func NewStoreLayoutUsecase(storeLayoutRepo repository.StoreLayoutRepository, ingredientRepo repository.IngredientRepository) StoreLayoutUsecase {
+	return &storeLayoutUsecase{
+		storeLayoutRepo: storeLayoutRepo,
+		ingredientRepo:  ingredientRepo,
+	}
+}
+
+// CreateStoreLayout は、新しい店舗レイアウトを登録します。
+func (u *storeLayoutUsecase) CreateStoreLayout(ctx context.Context, input SaveStoreLayoutInput) (*StoreLayoutOutput, error) {
+	layout, err := u.buildStoreLayout(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.storeLayoutRepo.CreateStoreLayout(ctx, layout); err != nil {
+		return nil, fmt.Errorf("店舗レイアウトの保存に失敗しました: %w", err)
+	}
+	return toStoreLayoutOutput(layout), nil
+}
+
+// UpdateStoreLayout は、店舗レイアウトの名前と売り場を丸ごと置き換えます。レイアウトが存在しない場合は ErrStoreLayoutNotFound を返します。
+func (u *storeLayoutUsecase) UpdateStoreLayout(ctx context.Context, input SaveStoreLayoutInput) (*StoreLayoutOutput, error) {
+	if _, err := u.storeLayoutRepo.FindStoreLayoutByID(ctx, input.LayoutID); err != nil {
+		return nil, notFound(err, ErrStoreLayoutNotFound)
+	}
+	layout, err := u.buildStoreLayout(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	layout.ID = input.LayoutID
+	if err := u.storeLayoutRepo.ReplaceStoreLayout(ctx, layout); err != nil {
+		return nil, fmt.Errorf("店舗レイアウトの保存に失敗しました: %w", err)
+	}
+	return toStoreLayoutOutput(layout), nil
+}
+
+// DeleteStoreLayout は、店舗レイアウトを削除します。レイアウトが存在しない場合は ErrStoreLayoutNotFound を返します。
+func (u *storeLayoutUsecase) DeleteStoreLayout(ctx context.Context, layoutID string) error {
+	return notFound(u.storeLayoutRepo.DeleteStoreLayout(ctx, layoutID), ErrStoreLayoutNotFound)
+}
+
+// GetStoreLayouts は、登録済みの店舗レイアウトをすべて取得します。
+func (u *storeLayoutUsecase) GetStoreLayouts(ctx context.Context) ([]*StoreLayoutOutput, error) {
+	layouts, err := u.storeLayoutRepo.FindStoreLayouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]*StoreLayoutOutput, len(layouts))
+	for i, layout := range layouts {
+		output[i] = toStoreLayoutOutput(layout)
+	}
+	return output, nil
+}
+
+// GetStoreLayout は、指定されたIDの店舗レイアウトを取得します。レイアウトが存在しない場合は ErrStoreLayoutNotFound を返します。
+func (u *storeLayoutUsecase) GetStoreLayout(ctx context.Context, layoutID string) (*StoreLayoutOutput, error) {
+	layout, err := u.storeLayoutRepo.FindStoreLayoutByID(ctx, layoutID)
+	if err != nil {
+		return nil, notFound(err, ErrStoreLayoutNotFound)
+	}
+	return toStoreLayoutOutput(layout), nil
+}
+
+// buildStoreLayout は、入力を検証して店舗レイアウトのモデルを組み立てます。
+// 食材分類・食材は名前からIDに解決し、1つの店舗の中で複数の売り場に割り当てられていないことを確認します。
+func (u *storeLayoutUsecase) buildStoreLayout(ctx context.Context, input SaveStoreLayoutInput) (*model.StoreLayout, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: 店舗レイアウト名が空です", ErrInvalidStoreLayout)
+	}
+	if len(input.Sections) == 0 {
+		return nil, fmt.Errorf("%w: 売り場が1つも指定されていません", ErrInvalidStoreLayout)
+	}
+
+	layouts, err := u.storeLayoutRepo.FindStoreLayouts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("店舗レイアウトの取得に失敗しました: %w", err)
+	}
+	for _, l := range layouts {
+		if l.Name == name && l.ID != input.LayoutID {
+			return nil, fmt.Errorf("%w: %s", ErrStoreLayoutNameConflict, name)
+		}
+	}
+
+	types, err := u.ingredientRepo.FindIngredientTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材分類の取得に失敗しました: %w", err)
+	}
+	typeMap := make(map[string]*model.IngredientType, len(types))
+	for _, t := range types {
+		typeMap[t.Name] = t
+	}
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	resolver := newIngredientResolver(ingredients)
+
+	layout := &model.StoreLayout{Name: name}
+	sectionNames := make(map[string]bool)
+	assigned := make(map[string]string) // 食材分類・食材のID → 割り当て済みの売り場名
+	assign := func(id, label, section string) error {
+		if other, ok := assigned[id]; ok {
+			return fmt.Errorf("%w: 「%s」が売り場「%s」と「%s」に割り当てられています", ErrInvalidStoreLayout, label, other, section)
+		}
+		assigned[id] = section
+		return nil
+	}
+
+	for i, in := range input.Sections {
+		sectionName := strings.TrimSpace(in.Name)
+		if sectionName == "" {
+			return nil, fmt.Errorf("%w: %d番目の売り場の名前が空です", ErrInvalidStoreLayout, i+1)
+		}
+		if sectionNames[sectionName] {
+			return nil, fmt.Errorf("%w: 売り場「%s」が重複しています", ErrInvalidStoreLayout, sectionName)
+		}
+		sectionNames[sectionName] = true
+
+		section := model.StoreSection{Position: i + 1, Name: sectionName}
+		addEntry := func(entry model.StoreSectionEntry) {
+			entry.Position = len(section.Entries) + 1
+			section.Entries = append(section.Entries, entry)
+		}
+		for _, typeName := range in.IngredientTypes {
+			t, ok := typeMap[strings.TrimSpace(typeName)]
+			if !ok {
+				return nil, fmt.Errorf("%w: 食材分類「%s」が登録されていません", ErrInvalidStoreLayout, typeName)
+			}
+			if err := assign(t.ID, t.Name, sectionName); err != nil {
+				return nil, err
+			}
+			addEntry(model.StoreSectionEntry{IngredientTypeID: &t.ID, IngredientType: t})
+		}
+		for _, ingredientName := range in.Ingredients {
+			ing := resolver.resolve(ingredientName)
+			if ing == nil {
+				return nil, fmt.Errorf("%w: 食材「%s」が登録されていません", ErrInvalidStoreLayout, ingredientName)
+			}
+			if err := assign(ing.ID, ing.Name, sectionName); err != nil {
+				return nil, err
+			}
+			addEntry(model.StoreSectionEntry{IngredientID: &ing.ID, Ingredient: ing})
+		}
+		layout.Sections = append(layout.Sections, section)
+	}
+	return layout, nil
+}
+
+// storeSectionIndex は、店舗レイアウトで食材がどの売り場のどの位置に置かれているかを引くための索引です。
+type storeSectionIndex struct {
+	byIngredient map[string]storeSlot
+	byType       map[string]storeSlot
+}
+
+// storeSlot は、売り場の番号（0始まり）と売り場の中での並び順です。
+type storeSlot struct {
+	section  int
+	position int
+}
+
+func newStoreSectionIndex(layout *model.StoreLayout) *storeSectionIndex {
+	idx := &storeSectionIndex{byIngredient: make(map[string]storeSlot), byType: make(map[string]storeSlot)}
+	for i, section := range layout.Sections {
+		for _, entry := range section.Entries {
+			slot := storeSlot{section: i, position: entry.Position}
+			switch {
+			case entry.IngredientID != nil:
+				idx.byIngredient[*entry.IngredientID] = slot
+			case entry.IngredientTypeID != nil:
+				idx.byType[*entry.IngredientTypeID] = slot
+			}
+		}
+	}
+	return idx
+}
+
+// lookup は、食材が置かれている売り場を返します。食材の指定を分類の指定より優先します。
+func (idx *storeSectionIndex) lookup(ing *model.Ingredient) (storeSlot, bool) {
+	if slot, ok := idx.byIngredient[ing.ID]; ok {
+		return slot, true
+	}
+	slot, ok := idx.byType[ing.TypeID]
+	return slot, ok
+}
+
+func toStoreLayoutOutput(layout *model.StoreLayout) *StoreLayoutOutput {
+	output := &StoreLayoutOutput{ID: layout.ID, Name: layout.Name, Sections: make([]*StoreSectionOutput, len(layout.Sections))}
+	for i, section := range layout.Sections {
+		s := &StoreSectionOutput{Name: section.Name, IngredientTypes: []string{}, Ingredients: []string{}}
+		for _, entry := range section.Entries {
+			switch {
+			case entry.Ingredient != nil:
+				s.Ingredients = append(s.Ingredients, entry.Ingredient.Name)
+			case entry.IngredientType != nil:
+				s.IngredientTypes = append(s.IngredientTypes, entry.IngredientType.Name)
+			}
+		}
+		output.Sections[i] = s
+	}
+	return output
+}
@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"meal-compass/backend/internal/domain/model"
+)
+
+// ErrUnknownIngredient は、指定された食材名が登録済みの食材に対応付けられない場合に返されます。
+var ErrUnknownIngredient = newError(ErrValidation, "unknown_ingredient", "登録されていない食材です")
+
+// maxSubstitutionHops は、代用ルールをたどる最大の段数です。
+// "合いびき肉 → 豚ひき肉 → 鶏ひき肉" のように2段までの代用を許し、元の食材からかけ離れたものにならないようにします。
+const maxSubstitutionHops = 2
+
+// substitutionPlanner は、避けたい食材と手持ちの食材に合わせて、レシピの食材を代用ルールに従って置き換えます。
+type substitutionPlanner struct {
+	edges  map[string][]*model.IngredientSubstitution // 代用元の食材ID → ルール
+	avoid  map[string]bool
+	onHand map[string]bool
+}
+
+// newSubstitutionPlanner は、食材名（別名や表記ゆれも可）で指定された条件から substitutionPlanner を作成します。
+func (u *planUsecase) newSubstitutionPlanner(ctx context.Context, avoid, onHand []string) (*substitutionPlanner, error) {
+	p := &substitutionPlanner{
+		edges:  make(map[string][]*model.IngredientSubstitution),
+		avoid:  make(map[string]bool),
+		onHand: make(map[string]bool),
+	}
+	if len(avoid) == 0 && len(onHand) == 0 {
+		return p, nil
+	}
+
+	ingredients, err := u.ingredientRepo.FindIngredients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("食材の取得に失敗しました: %w", err)
+	}
+	resolver := newIngredientResolver(ingredients)
+	resolveAll := func(names []string, set map[string]bool) error {
+		for _, name := range names {
+			ing := resolver.resolve(name)
+			if ing == nil {
+				return fmt.Errorf("%w: %s", ErrUnknownIngredient, strings.TrimSpace(name))
+			}
+			set[ing.ID] = true
+		}
+		return nil
+	}
+	if err := resolveAll(avoid, p.avoid); err != nil {
+		return nil, err
+	}
+	if err := resolveAll(onHand, p.onHand); err != nil {
+		return nil, err
+	}
+
+	substitutions, err := u.ingredientRepo.FindSubstitutions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("代用ルールの取得に失敗しました: %w", err)
+	}
+	for _, sub := range substitutions {
+		p.edges[sub.FromIngredientID] = append(p.edges[sub.FromIngredientID], sub)
+	}
+	return p, nil
+}
+
+// active は、置き換えの条件が指定されているかどうかを返します。
+func (p *substitutionPlanner) active() bool {
+	return len(p.avoid) > 0 || len(p.onHand) > 0
+}
+
+// plannedSubstitution は、レシピの材料1行に対する代用です。
+type plannedSubstitution struct {
+	model.PlanningMealSubstitution
+	to *model.Ingredient // 買い物リストの集計に使う代用先の食材（食材分類を含む）
+}
+
+// adapt は、メニューのレシピに対して行う代用を食材IDごとに返します。
+// 避けたい食材を代用できない場合は false を返し、そのメニューは計画に使いません。
+// 手持ちにない食材は、手持ちの食材で代用できる場合にだけ置き換えます。
+func (p *substitutionPlanner) adapt(menu *model.Menu) (map[string]*plannedSubstitution, bool) {
+	substitutions := make(map[string]*plannedSubstitution)
+	if !p.active() {
+		return substitutions, true
+	}
+
+	// 代用先がレシピ内の他の食材と重ならないよう、使用中の食材を記録する
+	used := make(map[string]bool, len(menu.MenuIngredientItems))
+	for _, item := range menu.MenuIngredientItems {
+		used[item.IngredientID] = true
+	}
+
+	for _, item := range menu.MenuIngredientItems {
+		var reason model.SubstitutionReason
+		switch {
+		case p.avoid[item.IngredientID]:
+			reason = model.SubstitutionAvoid
+		case len(p.onHand) > 0 && !p.onHand[item.IngredientID]:
+			reason = model.SubstitutionOnHand
+		default:
+			continue
+		}
+
+		to, ratio := p.substitute(item.IngredientID, reason, used)
+		if to == nil {
+			if reason == model.SubstitutionAvoid {
+				return nil, false
+			}
+			continue
+		}
+		used[to.ID] = true
+		substitutions[item.IngredientID] = &plannedSubstitution{
+			PlanningMealSubstitution: model.PlanningMealSubstitution{
+				FromIngredientID: item.IngredientID,
+				FromName:         item.Ingredient.Name,
+				FromAmount:       item.Amount,
+				FromUnit:         item.Ingredient.Unit,
+				ToIngredientID:   to.ID,
+				ToName:           to.Name,
+				ToAmount:         roundAmount(item.Amount * ratio),
+				ToUnit:           to.Unit,
+				Reason:           reason,
+			},
+			to: to,
+		}
+	}
+	return substitutions, true
+}
+
+// substitute は、代用ルールを最大 maxSubstitutionHops 段たどって代用先を探し、代用先の食材と換算比率を返します。
+// 候補が複数ある場合は、手持ちの食材、段数の少ないもの、食材名の順に優先します。見つからなければ nil を返します。
+func (p *substitutionPlanner) substitute(fromID string, reason model.SubstitutionReason, used map[string]bool) (*model.Ingredient, float64) {
+	type reach struct {
+		ingredient *model.Ingredient
+		ratio      float64
+		hops       int
+	}
+	visited := map[string]bool{fromID: true}
+	frontier := []reach{{ratio: 1}}
+	frontierIDs := []string{fromID}
+	var candidates []reach
+
+	for hops := 1; hops <= maxSubstitutionHops; hops++ {
+		var next []reach
+		var nextIDs []string
+		for i, id := range frontierIDs {
+			for _, edge := range p.edges[id] {
+				if visited[edge.ToIngredientID] {
+					continue
+				}
+				visited[edge.ToIngredientID] = true
+				r := reach{ingredient: &edge.ToIngredient, ratio: frontier[i].ratio * edge.Ratio, hops: hops}
+				next = append(next, r)
+				nextIDs = append(nextIDs, edge.ToIngredientID)
+
+				toID := edge.ToIngredientID
+				if p.avoid[toID] || used[toID] {
+					continue
+				}
+				if reason == model.SubstitutionOnHand && !p.onHand[toID] {
+					continue
+				}
+				candidates = append(candidates, r)
+			}
+		}
+		frontier, frontierIDs = next, nextIDs
+	}
+	if len(candidates) == 0 {
+		return nil, 0
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if onHandA, onHandB := p.onHand[a.ingredient.ID], p.onHand[b.ingredient.ID]; onHandA != onHandB {
+			return onHandA
+		}
+		if a.hops != b.hops {
+			return a.hops < b.hops
+		}
+		return a.ingredient.Name < b.ingredient.Name
+	})
+	return candidates[0].ingredient, candidates[0].ratio
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"meal-compass/backend/internal/domain/repository"
+)
+
+// ErrVersionConflict は、指定された版（If-Match）が現在の版と一致しない場合のエラーです。
+var ErrVersionConflict = newError(ErrPreconditionFailed, "version_mismatch", "他の利用者が先に更新しました。最新の内容を取得してからやり直してください")
+
+// ErrConcurrentUpdate は、同時に更新されたために、やり直しても更新できなかった場合のエラーです。
+var ErrConcurrentUpdate = newError(ErrConflict, "concurrent_update", "他の処理と同時に更新したため保存できませんでした。時間をおいてやり直してください")
+
+// maxVersionConflictRetries は、読み込んでから保存するまでの間に他の処理が更新していた場合に、読み込みからやり直す回数の上限です。
+const maxVersionConflictRetries = 3
+
+// retryOnVersionConflict は、保存時に版が変わっていた（repository.ErrVersionConflict）場合に、fn を読み込みからやり直します。
+// 上限まで繰り返しても保存できなければ ErrConcurrentUpdate を返します。
+// 利用者が指定した版との比較は fn の中で行うため、やり直しても指定した版が古ければ ErrVersionConflict になります。
+func retryOnVersionConflict[T any](fn func() (T, error)) (T, error) {
+	var (
+		result T
+		err    error
+	)
+	for i := 0; i < maxVersionConflictRetries; i++ {
+		if result, err = fn(); !errors.Is(err, repository.ErrVersionConflict) {
+			return result, err
+		}
+	}
+	return result, ErrConcurrentUpdate
+}
+
+// checkVersion は、指定された版（nil の場合は比較しない）が現在の版と一致するかを確かめます。
+func checkVersion(expected *int, current int) error {
+	if expected != nil && *expected != current {
+		return fmt.Errorf("%w（指定された版: %d、現在の版: %d）", ErrVersionConflict, *expected, current)
+	}
+	return nil
+}
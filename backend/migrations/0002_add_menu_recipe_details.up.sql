@@ -0,0 +1,22 @@
+-- ----------------------------------------------------------------
+-- menus: 調理に必要な情報（出来上がり量、コツ、出典）を追加
+-- ----------------------------------------------------------------
+ALTER TABLE `menus`
+  ADD COLUMN `servings` INT NOT NULL DEFAULT 1 COMMENT '出来上がり量（何人前か）' AFTER `name`,
+  ADD COLUMN `tips` TEXT DEFAULT NULL COMMENT '調理のコツやメモ' AFTER `servings`,
+  ADD COLUMN `source_url` VARCHAR(2048) DEFAULT NULL COMMENT 'レシピの出典URL' AFTER `tips`;
+
+-- ----------------------------------------------------------------
+-- menu_steps: メニューの調理手順を管理
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `menu_steps` (
+  `id` CHAR(36) NOT NULL COMMENT '調理手順ID (UUID)',
+  `menu_id` CHAR(36) NOT NULL COMMENT 'メニューID',
+  `position` INT NOT NULL COMMENT '手順の番号（1始まり）',
+  `instruction` TEXT NOT NULL COMMENT '手順の内容',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_menu_step_position` (`menu_id`, `position`),
+  FOREIGN KEY (`menu_id`) REFERENCES `menus` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
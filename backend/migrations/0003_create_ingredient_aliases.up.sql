@@ -0,0 +1,16 @@
+-- ----------------------------------------------------------------
+-- ingredient_aliases: 食材の別名（表記ゆれ）を管理
+-- normalized_name は、ひらがな/カタカナ・全角/半角の違いを吸収した比較用の文字列
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `ingredient_aliases` (
+  `id` CHAR(36) NOT NULL COMMENT '別名ID (UUID)',
+  `ingredient_id` CHAR(36) NOT NULL COMMENT '食材ID',
+  `alias` VARCHAR(255) NOT NULL COMMENT '別名',
+  `normalized_name` VARCHAR(255) NOT NULL COMMENT '正規化した別名',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_normalized_name` (`normalized_name`),
+  KEY `idx_ingredient_id` (`ingredient_id`),
+  FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+-- ----------------------------------------------------------------
+-- menu_ingredient_items: 食材からメニューを引くための転置インデックス
+-- 「手持ちの食材で作れるメニュー」の検索で、食材IDから該当するレシピ行をテーブルを読まずに取得する
+-- ----------------------------------------------------------------
+ALTER TABLE `menu_ingredient_items`
+  ADD KEY `idx_ingredient_menu` (`ingredient_id`, `menu_id`, `amount`);
@@ -0,0 +1,84 @@
+-- ----------------------------------------------------------------
+-- menu_versions: ある時点のメニューの内容を固定したスナップショット（版）を管理
+-- 食事計画は版を参照するため、後からレシピを編集しても計画の表示は変わらない
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `menu_versions` (
+  `id` CHAR(36) NOT NULL COMMENT '版ID (UUID)',
+  `menu_id` CHAR(36) NOT NULL COMMENT 'メニューID',
+  `version` INT NOT NULL COMMENT '版の番号（メニューごとに1始まり）',
+  `name` VARCHAR(255) NOT NULL COMMENT 'メニュー名',
+  `servings` INT NOT NULL DEFAULT 1 COMMENT '出来上がり量（何人前か）',
+  `tips` TEXT DEFAULT NULL COMMENT '調理のコツやメモ',
+  `source_url` VARCHAR(2048) DEFAULT NULL COMMENT 'レシピの出典URL',
+  `content_hash` CHAR(64) NOT NULL COMMENT '内容のハッシュ（変更の判定用）',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_menu_version` (`menu_id`, `version`),
+  FOREIGN KEY (`menu_id`) REFERENCES `menus` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- ----------------------------------------------------------------
+-- menu_version_ingredients: 版に含まれるレシピの材料（食材名と単位も写し取る）
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `menu_version_ingredients` (
+  `id` CHAR(36) NOT NULL COMMENT 'ID (UUID)',
+  `menu_version_id` CHAR(36) NOT NULL COMMENT '版ID',
+  `position` INT NOT NULL COMMENT '材料の並び順（1始まり）',
+  `ingredient_id` CHAR(36) NOT NULL COMMENT '食材ID',
+  `ingredient_name` VARCHAR(255) NOT NULL COMMENT '版を作成した時点の食材名',
+  `unit` VARCHAR(50) NOT NULL COMMENT '版を作成した時点の単位',
+  `amount` DECIMAL(10, 2) NOT NULL COMMENT '必要量（1人前）',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_menu_version_id` (`menu_version_id`),
+  FOREIGN KEY (`menu_version_id`) REFERENCES `menu_versions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- ----------------------------------------------------------------
+-- menu_version_steps: 版に含まれる調理手順
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `menu_version_steps` (
+  `id` CHAR(36) NOT NULL COMMENT 'ID (UUID)',
+  `menu_version_id` CHAR(36) NOT NULL COMMENT '版ID',
+  `position` INT NOT NULL COMMENT '手順の番号（1始まり）',
+  `instruction` TEXT NOT NULL COMMENT '手順の内容',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_menu_version_id` (`menu_version_id`),
+  FOREIGN KEY (`menu_version_id`) REFERENCES `menu_versions` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE `menus`
+  ADD COLUMN `current_version_id` CHAR(36) DEFAULT NULL COMMENT '最新の版ID' AFTER `source_url`;
+
+ALTER TABLE `planning_meal_items`
+  ADD COLUMN `menu_version_id` CHAR(36) DEFAULT NULL COMMENT '計画作成時のメニューの版ID' AFTER `menu_id`,
+  ADD FOREIGN KEY (`menu_version_id`) REFERENCES `menu_versions` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- ----------------------------------------------------------------
+-- 既存データの移行: 現在のメニューの内容を第1版とし、既存の食事計画もその版を参照させる
+-- content_hash は空にしておき、次にメニューを取り込んだときに改めて版を作成する
+-- ----------------------------------------------------------------
+INSERT INTO `menu_versions` (`id`, `menu_id`, `version`, `name`, `servings`, `tips`, `source_url`, `content_hash`)
+SELECT UUID(), `id`, 1, `name`, `servings`, `tips`, `source_url`, '' FROM `menus`;
+
+INSERT INTO `menu_version_ingredients` (`id`, `menu_version_id`, `position`, `ingredient_id`, `ingredient_name`, `unit`, `amount`)
+SELECT UUID(), v.`id`, ROW_NUMBER() OVER (PARTITION BY mii.`menu_id` ORDER BY mii.`created_at`), mii.`ingredient_id`, i.`name`, i.`unit`, mii.`amount`
+FROM `menu_ingredient_items` mii
+JOIN `menu_versions` v ON v.`menu_id` = mii.`menu_id`
+JOIN `ingredients` i ON i.`id` = mii.`ingredient_id`;
+
+INSERT INTO `menu_version_steps` (`id`, `menu_version_id`, `position`, `instruction`)
+SELECT UUID(), v.`id`, s.`position`, s.`instruction`
+FROM `menu_steps` s
+JOIN `menu_versions` v ON v.`menu_id` = s.`menu_id`;
+
+UPDATE `menus` m JOIN `menu_versions` v ON v.`menu_id` = m.`id`
+SET m.`current_version_id` = v.`id`;
+
+UPDATE `planning_meal_items` p JOIN `menus` m ON m.`id` = p.`menu_id`
+SET p.`menu_version_id` = m.`current_version_id`;
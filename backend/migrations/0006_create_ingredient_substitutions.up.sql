@@ -0,0 +1,38 @@
+-- ----------------------------------------------------------------
+-- ingredient_substitutions: 食材の代用ルール（代用元の分量 × ratio = 代用先の分量）
+-- ルールには向きがあり、相互に代用できる場合は逆向きの行も登録する
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `ingredient_substitutions` (
+  `id` CHAR(36) NOT NULL COMMENT 'ID (UUID)',
+  `from_ingredient_id` CHAR(36) NOT NULL COMMENT '代用元の食材ID',
+  `to_ingredient_id` CHAR(36) NOT NULL COMMENT '代用先の食材ID',
+  `ratio` DECIMAL(10, 4) NOT NULL COMMENT '換算比率',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_substitution` (`from_ingredient_id`, `to_ingredient_id`),
+  FOREIGN KEY (`from_ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`to_ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- ----------------------------------------------------------------
+-- planning_meal_substitutions: 計画された食事で行った食材の代用
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `planning_meal_substitutions` (
+  `id` CHAR(36) NOT NULL COMMENT 'ID (UUID)',
+  `meal_id` CHAR(36) NOT NULL COMMENT '食事予定ID',
+  `from_ingredient_id` CHAR(36) NOT NULL COMMENT '代用元の食材ID',
+  `from_name` VARCHAR(255) NOT NULL COMMENT '代用元の食材名',
+  `from_amount` DECIMAL(10, 2) NOT NULL COMMENT '代用元の分量（1人前）',
+  `from_unit` VARCHAR(50) NOT NULL COMMENT '代用元の単位',
+  `to_ingredient_id` CHAR(36) NOT NULL COMMENT '代用先の食材ID',
+  `to_name` VARCHAR(255) NOT NULL COMMENT '代用先の食材名',
+  `to_amount` DECIMAL(10, 2) NOT NULL COMMENT '代用先の分量（1人前）',
+  `to_unit` VARCHAR(50) NOT NULL COMMENT '代用先の単位',
+  `reason` ENUM('AVOID', 'ON_HAND') NOT NULL COMMENT '代用した理由',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_meal_id` (`meal_id`),
+  FOREIGN KEY (`meal_id`) REFERENCES `planning_meal_items` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+-- ----------------------------------------------------------------
+-- ingredients: 旬の月と、旬の時期の価格の目安を追加
+-- ----------------------------------------------------------------
+ALTER TABLE `ingredients`
+  ADD COLUMN `season_months` SMALLINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '旬の月（1月をbit0、12月をbit11とするビットマスク。0は旬なし）' AFTER `shelf_life_days_opened`,
+  ADD COLUMN `seasonal_price_multiplier` DECIMAL(4, 2) DEFAULT NULL COMMENT '旬の時期の価格の目安（通常を1とした倍率）' AFTER `season_months`;
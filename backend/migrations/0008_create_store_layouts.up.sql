@@ -0,0 +1,50 @@
+-- ----------------------------------------------------------------
+-- store_layouts: 店舗の売り場の並び（買い物リストを歩く順に並べるための設定）
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `store_layouts` (
+  `id` CHAR(36) NOT NULL COMMENT '店舗レイアウトID (UUID)',
+  `name` VARCHAR(255) NOT NULL COMMENT '店舗レイアウト名',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_store_layout_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- ----------------------------------------------------------------
+-- store_sections: 店舗の売り場（position の順に歩く）
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `store_sections` (
+  `id` CHAR(36) NOT NULL COMMENT '売り場ID (UUID)',
+  `layout_id` CHAR(36) NOT NULL COMMENT '店舗レイアウトID',
+  `position` INT NOT NULL COMMENT '歩く順の番号（1始まり）',
+  `name` VARCHAR(255) NOT NULL COMMENT '売り場名',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_store_section_position` (`layout_id`, `position`),
+  FOREIGN KEY (`layout_id`) REFERENCES `store_layouts` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- ----------------------------------------------------------------
+-- store_section_entries: 売り場に置かれている食材分類または食材（どちらか一方）
+-- 食材の指定は分類の指定より優先される
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `store_section_entries` (
+  `id` CHAR(36) NOT NULL COMMENT 'ID (UUID)',
+  `section_id` CHAR(36) NOT NULL COMMENT '売り場ID',
+  `layout_id` CHAR(36) NOT NULL COMMENT '店舗レイアウトID（同じ店舗で複数の売り場に割り当てないための一意制約に使う）',
+  `position` INT NOT NULL COMMENT '売り場の中での並び順（1始まり）',
+  `ingredient_type_id` CHAR(36) DEFAULT NULL COMMENT '食材分類ID',
+  `ingredient_id` CHAR(36) DEFAULT NULL COMMENT '食材ID',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_section_id` (`section_id`),
+  UNIQUE KEY `uq_store_entry_type` (`layout_id`, `ingredient_type_id`),
+  UNIQUE KEY `uq_store_entry_ingredient` (`layout_id`, `ingredient_id`),
+  CHECK ((`ingredient_type_id` IS NULL) <> (`ingredient_id` IS NULL)),
+  FOREIGN KEY (`section_id`) REFERENCES `store_sections` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`layout_id`) REFERENCES `store_layouts` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`ingredient_type_id`) REFERENCES `ingredient_types` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+-- ----------------------------------------------------------------
+-- shopping_ingredient_items: 手動で追加したアイテム（自由入力またはカタログの食材）に対応
+-- ----------------------------------------------------------------
+-- 手動で追加したアイテムは、レシピから計算したアイテムと同じ食材でも別の行として持つ
+ALTER TABLE `shopping_ingredient_items`
+  MODIFY COLUMN `ingredient_id` CHAR(36) NULL COMMENT '食材ID（自由入力のアイテムはNULL）',
+  ADD COLUMN `name` VARCHAR(255) DEFAULT NULL COMMENT '自由入力のアイテム名' AFTER `ingredient_id`,
+  ADD COLUMN `unit` VARCHAR(50) DEFAULT NULL COMMENT '自由入力のアイテムの単位' AFTER `amount`,
+  ADD COLUMN `note` VARCHAR(255) DEFAULT NULL COMMENT 'メモ' AFTER `bought`,
+  ADD COLUMN `manual` BOOLEAN NOT NULL DEFAULT FALSE COMMENT '手動で追加したアイテムか（レシピから計算したアイテムではない）' AFTER `note`,
+  ADD UNIQUE KEY `uq_plan_ingredient_manual` (`plan_id`, `ingredient_id`, `manual`),
+  DROP INDEX `uq_plan_ingredient`;
@@ -0,0 +1,7 @@
+-- ----------------------------------------------------------------
+-- shopping_ingredient_items: 実際に購入した量と購入日時を追加
+-- ----------------------------------------------------------------
+-- purchased_amount が NULL の場合、購入済み（bought）なら必要量（amount）をちょうど購入したものとみなす
+ALTER TABLE `shopping_ingredient_items`
+  ADD COLUMN `purchased_amount` DECIMAL(10, 2) DEFAULT NULL COMMENT '実際に購入した量（必要量を超えた分は残りものになる）' AFTER `unit`,
+  ADD COLUMN `purchased_at` DATETIME(6) DEFAULT NULL COMMENT '購入日時（最初に購入を記録した日時）' AFTER `purchased_amount`;
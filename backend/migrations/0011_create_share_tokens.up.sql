@@ -0,0 +1,18 @@
+-- ----------------------------------------------------------------
+-- share_tokens: 買い物計画を共有するリンクのトークン
+-- ----------------------------------------------------------------
+-- トークンそのものは保存せず、SHA-256のハッシュだけを保存する
+CREATE TABLE IF NOT EXISTS `share_tokens` (
+  `id` CHAR(36) NOT NULL COMMENT '共有トークンID (UUID)',
+  `plan_id` CHAR(36) NOT NULL COMMENT '買い物計画ID',
+  `token_hash` CHAR(64) NOT NULL COMMENT 'トークンのSHA-256ハッシュ（16進）',
+  `scope` ENUM('READ_ONLY', 'CHECK_OFF') NOT NULL COMMENT '共有の範囲（READ_ONLY: 閲覧のみ、CHECK_OFF: 閲覧と購入済みのチェック）',
+  `expires_at` DATETIME(6) NOT NULL COMMENT '有効期限',
+  `revoked_at` DATETIME(6) DEFAULT NULL COMMENT '無効にした日時',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq_share_token_hash` (`token_hash`),
+  KEY `idx_share_token_plan` (`plan_id`),
+  FOREIGN KEY (`plan_id`) REFERENCES `shopping_plans` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,9 @@
+-- ----------------------------------------------------------------
+-- shopping_plans / shopping_ingredient_items: 楽観的排他制御のための版を追加
+-- ----------------------------------------------------------------
+-- 更新のたびに version を1つ進め、読み込んだときの版と一致する場合だけ更新する（APIでは ETag / If-Match として扱う）
+ALTER TABLE `shopping_plans`
+  ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1 COMMENT '版（買い物リストが変わるたびに1つ進む）' AFTER `period_start_at`;
+
+ALTER TABLE `shopping_ingredient_items`
+  ADD COLUMN `version` INT UNSIGNED NOT NULL DEFAULT 1 COMMENT '版（更新のたびに1つ進む）' AFTER `manual`;
@@ -0,0 +1,17 @@
+-- ----------------------------------------------------------------
+-- shopping_ingredient_items: 買い物を複数回に分けるため、買いに行く日（回）と最初に使う日を追加
+-- ----------------------------------------------------------------
+-- 日持ちしない食材は、使う日に近い回の買い物で買う。同じ食材でも回が違えば別の行として持つ
+ALTER TABLE `shopping_ingredient_items`
+  ADD COLUMN `trip_date` DATE DEFAULT NULL COMMENT '買いに行く日（この日までに買う）' AFTER `unit`,
+  ADD COLUMN `first_use_date` DATE DEFAULT NULL COMMENT '献立で最初に使う日（手動で追加したアイテムはNULL）' AFTER `trip_date`;
+
+-- 既存のアイテムは、計画の開始日に1回で買うものとする
+UPDATE `shopping_ingredient_items` AS i
+  JOIN `shopping_plans` AS p ON p.`id` = i.`plan_id`
+  SET i.`trip_date` = p.`period_start_at`;
+
+ALTER TABLE `shopping_ingredient_items`
+  MODIFY COLUMN `trip_date` DATE NOT NULL COMMENT '買いに行く日（この日までに買う）',
+  ADD UNIQUE KEY `uq_plan_ingredient_manual_trip` (`plan_id`, `ingredient_id`, `manual`, `trip_date`),
+  DROP INDEX `uq_plan_ingredient_manual`;
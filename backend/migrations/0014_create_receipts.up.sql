@@ -0,0 +1,37 @@
+-- ----------------------------------------------------------------
+-- receipts / receipt_lines: 買い物計画に記録した実際の買い物（レシート）と、品物ごとに支払った金額
+-- ----------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS `receipts` (
+  `id` CHAR(36) NOT NULL COMMENT 'レシートID (UUID)',
+  `plan_id` CHAR(36) NOT NULL COMMENT '買い物計画ID',
+  `store_name` VARCHAR(255) DEFAULT NULL COMMENT '店舗名',
+  `purchased_at` DATETIME(6) NOT NULL COMMENT '買い物をした日時',
+  `total` INT NOT NULL COMMENT '支払った金額の合計（円）',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_receipt_plan` (`plan_id`),
+  KEY `idx_receipt_purchased_at` (`purchased_at`),
+  FOREIGN KEY (`plan_id`) REFERENCES `shopping_plans` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 食材に対応付けた行は、食材ごとの実際の価格（単価）の集計に使う
+CREATE TABLE IF NOT EXISTS `receipt_lines` (
+  `id` CHAR(36) NOT NULL COMMENT 'レシートの行ID (UUID)',
+  `receipt_id` CHAR(36) NOT NULL COMMENT 'レシートID',
+  `position` INT NOT NULL COMMENT 'レシートでの順番（0から）',
+  `name` VARCHAR(255) NOT NULL COMMENT 'レシートの品名',
+  `ingredient_id` CHAR(36) DEFAULT NULL COMMENT '対応する食材ID（食材でない品物はNULL）',
+  `shopping_item_id` CHAR(36) DEFAULT NULL COMMENT '購入済みにした買い物リストのアイテムID',
+  `quantity` DECIMAL(10,2) DEFAULT NULL COMMENT '購入した量',
+  `unit` VARCHAR(50) DEFAULT NULL COMMENT '購入した量の単位',
+  `price` INT NOT NULL COMMENT '支払った金額（円、値引き後）',
+  `created_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '作成日時',
+  `updated_at` DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '更新日時',
+  PRIMARY KEY (`id`),
+  KEY `idx_receipt_line_receipt` (`receipt_id`),
+  KEY `idx_receipt_line_ingredient` (`ingredient_id`),
+  FOREIGN KEY (`receipt_id`) REFERENCES `receipts` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+  FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients` (`id`) ON DELETE SET NULL ON UPDATE CASCADE,
+  FOREIGN KEY (`shopping_item_id`) REFERENCES `shopping_ingredient_items` (`id`) ON DELETE SET NULL ON UPDATE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
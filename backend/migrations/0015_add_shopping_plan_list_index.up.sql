@@ -0,0 +1,6 @@
+-- ----------------------------------------------------------------
+-- shopping_plans: 計画の一覧のための索引を追加
+-- ----------------------------------------------------------------
+-- 一覧は開始日、作成日時、IDの降順に並べ、前のページの最後の計画より後をカーソルとして取得する
+ALTER TABLE `shopping_plans`
+  ADD KEY `idx_period_start_created` (`period_start_at`, `created_at`, `id`);
@@ -0,0 +1,6 @@
+-- ----------------------------------------------------------------
+-- shopping_plans: 計画のアーカイブ
+-- ----------------------------------------------------------------
+-- アーカイブした計画は一覧に表示しないが、削除はせずに献立や買い物リスト、レシートを残す
+ALTER TABLE `shopping_plans`
+  ADD COLUMN `archived_at` DATETIME(6) NULL COMMENT 'アーカイブした日時（アーカイブしていない場合は NULL）' AFTER `version`;
@@ -9,6 +9,7 @@ import { Header } from './components/layout/Header';
 import { Footer } from './components/layout/Footer';
 import { TopPage } from './pages/TopPage';
 import { ResultPage } from './pages/ResultPage';
+import { LoginPage } from './pages/LoginPage';
 
 const ProtectedResultRoute: React.FC = () => {
   const { shoppingPlanId } = useShoppingPlan();
@@ -33,6 +34,9 @@ const App: React.FC = () => {
         <Routes>
           {/* ルートパス ("/") にはTopPageコンポーネントを割り当て */}
           <Route path="/" element={<TopPage />} />
+
+          {/* 持ち主のセッションが無い場合に、APIの呼び出しからリダイレクトされるログイン画面 */}
+          <Route path="/login" element={<LoginPage />} />
           
           {/*
            * "/plan/:shoppingPlanId" のパスにはResultPageコンポーネントを割り当て。
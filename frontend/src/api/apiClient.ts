@@ -11,8 +11,10 @@ const apiClient = axios.create({
   // デフォルトのヘッダー設定
   headers: {
     'Content-Type': 'application/json',
-    'Accept': 'application/json',
+    'Accept': 'application/json, application/problem+json',
   },
+  // 持ち主のセッション (POST /api/session で発行される HttpOnly のクッキー) を送る
+  withCredentials: true,
   // リクエストタイムアウトをミリ秒で設定 (例: 10秒)
   timeout: 10000,
 });
@@ -23,6 +25,10 @@ apiClient.interceptors.response.use(
   error => {
     // ネットワークエラーやタイムアウトなどのハンドリング
     console.error('API Error:', error.response?.data || error.message);
+    // セッションが無い、または切れている場合はログイン画面に移る
+    if (error.response?.status === 401 && window.location.pathname !== '/login') {
+      window.location.assign('/login');
+    }
     // エラーを呼び出し元に伝播させる
     return Promise.reject(error);
   }
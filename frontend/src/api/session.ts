@@ -0,0 +1,30 @@
+import apiClient from './apiClient';
+
+/**
+ * 持ち主のトークンと引き換えにセッションを開始する
+ * セッションは HttpOnly のクッキーで返されるため、トークンもセッションもブラウザのJavaScriptには残らない
+ * @param token - 計画の持ち主のトークン (バックエンドの OWNER_TOKEN)
+ */
+export const login = async (token: string): Promise<void> => {
+  await apiClient.post('/api/session', { token });
+};
+
+/**
+ * セッションを終了する（クッキーを消す）
+ */
+export const logout = async (): Promise<void> => {
+  await apiClient.delete('/api/session');
+};
+
+/**
+ * クッキーを送れない別の端末やアプリで開くためのURLを返す
+ * URLに付けるトークンは、そのパスへの GET でだけ10分間使える
+ * @param path - APIのパス (例: /api/plans/{id}/calendar.ics)
+ * @param query - 付けるクエリパラメータ
+ * @returns トークンを付けたURL
+ */
+export const createSignedUrl = async (path: string, query: Record<string, string> = {}): Promise<string> => {
+  const response = await apiClient.post<{ access_token: string; expires_at: string }>('/api/url-tokens', { path });
+  const params = new URLSearchParams({ ...query, access_token: response.data.access_token });
+  return `${apiClient.defaults.baseURL ?? ''}${path}?${params.toString()}`;
+};
@@ -4,9 +4,26 @@ import type {
   ShoppingPlanResponse,
   MenuListResponse,
   IngredientListResponse,
-  Ingredient
+  Ingredient,
+  CreateShareTokenRequest,
+  ShareToken,
+  RecordReceiptRequest,
+  Receipt,
+  PlanSpend,
+  PlanListQuery,
+  PlanListResponse,
+  PlanDetail
 } from '../types';
 
+/**
+ * EventSource やリンクで開くAPIのURLを返す。持ち主のセッションはクッキーで送られる
+ * @param path - APIのパス（クエリパラメータを含んでもよい）
+ * @returns APIのURL
+ */
+const apiUrl = (path: string): string => {
+  return `${apiClient.defaults.baseURL ?? ''}${path}`;
+};
+
 /**
  * 新しい買い物計画を作成する
  * @param requestBody - ユーザーが選択した食事の予定
@@ -27,16 +44,47 @@ export const getMenuList = async (shoppingPlanId: string): Promise<MenuListRespo
   return response.data;
 };
 
+/**
+ * 献立のカレンダー（iCalendar）のURLを返す（カレンダーアプリでの購読・取り込み用）
+ * @param shoppingPlanId - 買い物計画のID
+ * @returns カレンダーのURL
+ */
+export const getMealCalendarUrl = (shoppingPlanId: string): string => {
+  return apiUrl(`/api/plans/${shoppingPlanId}/calendar.ics`);
+};
+
+/**
+ * 計画の変更を配信する Server-Sent Events のURLを返す（`new EventSource(url, { withCredentials: true })` での接続用）
+ * @param shoppingPlanId - 買い物計画のID
+ * @returns イベントの配信のURL
+ */
+export const getPlanEventsUrl = (shoppingPlanId: string): string => {
+  return apiUrl(`/api/plans/${shoppingPlanId}/events`);
+};
+
 /**
  * 指定されたIDの買い物リストを取得する
  * @param shoppingPlanId - 買い物計画のID
+ * @param tripDate - 買いに行く日 (YYYY-MM-DD)。指定するとその日に買うアイテムだけを取得する
  * @returns 買い物リスト
  */
-export const getIngredientList = async (shoppingPlanId: string): Promise<IngredientListResponse> => {
-  const response = await apiClient.get<IngredientListResponse>(`/api/ingredient-list/${shoppingPlanId}`);
+export const getIngredientList = async (shoppingPlanId: string, tripDate?: string): Promise<IngredientListResponse> => {
+  const response = await apiClient.get<IngredientListResponse>(`/api/ingredient-list/${shoppingPlanId}`, {
+    params: tripDate ? { trip_date: tripDate } : undefined,
+  });
   return response.data;
 };
 
+/**
+ * 買い物リストを書き出したファイルのURLを返す（印刷やチャットへの貼り付け用）
+ * @param shoppingPlanId - 買い物計画のID
+ * @param format - 書き出す形式 (txt: テキスト, md: Markdown, csv: CSV, pdf: 印刷用のPDF)
+ * @returns ダウンロード用のURL
+ */
+export const getIngredientListExportUrl = (shoppingPlanId: string, format: 'txt' | 'md' | 'csv' | 'pdf'): string => {
+  return apiUrl(`/api/ingredient-list/${shoppingPlanId}?format=${format}`);
+};
+
 /**
  * 買い物リストの材料の購入状況を更新する
  * @param itemId - 材料アイテムのID
@@ -46,4 +94,83 @@ export const getIngredientList = async (shoppingPlanId: string): Promise<Ingredi
 export const updateIngredientStatus = async (itemId: string, bought: boolean): Promise<Ingredient> => {
   const response = await apiClient.patch<Ingredient>(`/api/shopping_ingredient_items/${itemId}`, { bought });
   return response.data;
-};
\ No newline at end of file
+};
+/**
+ * 計画の共有リンクを発行する
+ * @param shoppingPlanId - 買い物計画のID
+ * @param requestBody - 共有リンクの範囲と有効期限
+ * @returns 発行された共有リンク（トークンはこのときだけ返る）
+ */
+export const createShareToken = async (shoppingPlanId: string, requestBody: CreateShareTokenRequest): Promise<ShareToken> => {
+  const response = await apiClient.post<ShareToken>(`/api/plans/${shoppingPlanId}/share-tokens`, requestBody);
+  return response.data;
+};
+
+/**
+ * 計画の共有リンクを無効にする
+ * @param shoppingPlanId - 買い物計画のID
+ * @param tokenId - 共有リンクのID
+ */
+export const revokeShareToken = async (shoppingPlanId: string, tokenId: string): Promise<void> => {
+  await apiClient.delete(`/api/plans/${shoppingPlanId}/share-tokens/${tokenId}`);
+};
+
+/**
+ * 実際の買い物（レシート）を計画に記録し、対応するアイテムを購入済みにする
+ * @param shoppingPlanId - 買い物計画のID
+ * @param requestBody - 品物と支払った金額、またはレシートのテキスト
+ * @returns 記録したレシート
+ */
+export const recordReceipt = async (shoppingPlanId: string, requestBody: RecordReceiptRequest): Promise<Receipt> => {
+  const response = await apiClient.post<Receipt>(`/api/plans/${shoppingPlanId}/receipts`, requestBody);
+  return response.data;
+};
+
+/**
+ * 計画に記録したレシートの支出の集計を取得する
+ * @param shoppingPlanId - 買い物計画のID
+ * @returns 食材分類ごと・食材ごとの支出
+ */
+export const getPlanSpend = async (shoppingPlanId: string): Promise<PlanSpend> => {
+  const response = await apiClient.get<PlanSpend>(`/api/plans/${shoppingPlanId}/spend`);
+  return response.data;
+};
+
+/**
+ * 作成した計画を開始日の新しい順に一覧する
+ * @param query - 開始日の期間、前のページの next_cursor、件数
+ * @returns 計画の一覧の1ページ
+ */
+export const listPlans = async (query: PlanListQuery = {}): Promise<PlanListResponse> => {
+  const response = await apiClient.get<PlanListResponse>('/api/plans', { params: query });
+  return response.data;
+};
+
+/**
+ * 計画の情報を取得する
+ * @param shoppingPlanId - 買い物計画のID
+ * @returns 計画の情報と食事・買い物の進み具合
+ */
+export const getPlan = async (shoppingPlanId: string): Promise<PlanDetail> => {
+  const response = await apiClient.get<PlanDetail>(`/api/plans/${shoppingPlanId}`);
+  return response.data;
+};
+
+/**
+ * 計画をアーカイブする（または解除する）
+ * @param shoppingPlanId - 買い物計画のID
+ * @param archived - true でアーカイブ、false で解除
+ * @returns 更新後の計画の情報
+ */
+export const setPlanArchived = async (shoppingPlanId: string, archived: boolean): Promise<PlanDetail> => {
+  const response = await apiClient.patch<PlanDetail>(`/api/plans/${shoppingPlanId}`, { archived });
+  return response.data;
+};
+
+/**
+ * 計画を献立、買い物リスト、レシート、共有リンクとともに削除する
+ * @param shoppingPlanId - 買い物計画のID
+ */
+export const deletePlan = async (shoppingPlanId: string): Promise<void> => {
+  await apiClient.delete(`/api/plans/${shoppingPlanId}`);
+};
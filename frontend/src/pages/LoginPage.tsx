@@ -0,0 +1,53 @@
+import React, { useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { login } from '../api/session';
+import { Button } from '../components/ui/Button';
+import styles from './TopPage.module.css';
+
+export const LoginPage: React.FC = () => {
+  const navigate = useNavigate();
+  const [token, setToken] = useState('');
+  const [isLoading, setIsLoading] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const handleSubmit = async (event: React.FormEvent) => {
+    event.preventDefault();
+    setIsLoading(true);
+    setError(null);
+    try {
+      // トークンはセッションのクッキーと引き換えるだけで、ブラウザには保存しない
+      await login(token);
+      setToken('');
+      navigate('/', { replace: true });
+    } catch (err) {
+      setError('トークンが正しくありません。');
+      console.error(err);
+    } finally {
+      setIsLoading(false);
+    }
+  };
+
+  return (
+    <div className={styles.container}>
+      <h1 className={styles.title}>ログイン</h1>
+      <p className={styles.description}>
+        計画の持ち主のトークン（サーバーの OWNER_TOKEN）を入力してください。
+      </p>
+
+      <form className={styles.actions} onSubmit={handleSubmit}>
+        <input
+          type="password"
+          autoComplete="current-password"
+          value={token}
+          onChange={e => setToken(e.target.value)}
+          disabled={isLoading}
+          aria-label="トークン"
+        />
+        {error && <p className={styles.error}>{error}</p>}
+        <Button type="submit" isLoading={isLoading} disabled={token === ''}>
+          ログインする
+        </Button>
+      </form>
+    </div>
+  );
+};
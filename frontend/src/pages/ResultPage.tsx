@@ -85,7 +85,17 @@ export const ResultPage: React.FC = () => {
               {dailyMeals.map((meal, index) => (
                 <div key={index} className={styles.mealCard}>
                   <p className={styles.mealPeriod}>{meal.meal_period === 'MORNING' ? '朝' : meal.meal_period === 'LUNCH' ? '昼' : '夜'}ごはん</p>
-                  <p className={styles.menuName}>{meal.menu_name}</p>
+                  <p className={styles.menuName}>
+                    {meal.menu_name}
+                    {meal.in_season && (
+                      <span
+                        className={styles.seasonBadge}
+                        title={meal.seasonal_ingredients.map((ing) => ing.name).join('、')}
+                      >
+                        旬
+                      </span>
+                    )}
+                  </p>
                   <ul className={styles.mealIngredients}>
                     {meal.ingredients.map((ing, i) => (
                       <li key={i}>{ing.name} ({ing.amount}{ing.unit})</li>
@@ -1,3 +1,8 @@
+/**
+ * APIのレスポンスの型
+ * バックエンドのAPI仕様（GET /api/openapi.json）の components.schemas に合わせて書いている
+ */
+
 /**
  * 食事の期間を表す型
  * 仕様書に基づき、"MORNING", "LUNCH", "DINNER" のいずれかを取る
@@ -18,10 +23,37 @@ export interface MenuIngredient {
  * APIレスポンスの "meals" 配列の要素に対応
  */
 export interface Meal {
+  meal_id: string; // UUID
   date: string; // "YYYY-MM-DD" 形式
   meal_period: MealPeriod;
+  menu_id: string; // UUID
+  menu_version: number; // 計画作成時のメニューの版
   menu_name: string;
-  ingredients: MenuIngredient[];
+  servings: number; // 調理手順の出来上がり量（何人前か）
+  steps: string[]; // 調理手順（順番どおり）
+  tips: string | null;
+  source_url: string | null;
+  ingredients: MenuIngredient[]; // 代用後の食材
+  substitutions: Substitution[]; // この食事で行った食材の代用
+  in_season: boolean; // 食事の日付に旬を迎える食材を使うか（旬バッジの表示用）
+  seasonal_ingredients: {
+    name: string;
+    price_multiplier: number | null; // 旬の時期の価格の目安（通常を1とした倍率）
+  }[];
+}
+
+/**
+ * 食材の代用の型
+ * "AVOID" は避けたい食材の置き換え、"ON_HAND" は手持ちの食材への置き換え
+ */
+export interface Substitution {
+  from: string;
+  from_amount: number;
+  from_unit: string;
+  to: string;
+  to_amount: number;
+  to_unit: string;
+  reason: "AVOID" | "ON_HAND";
 }
 
 /**
@@ -31,14 +63,285 @@ export interface Meal {
 export interface Ingredient {
   id: string; // UUID
   name: string;
-  type: string;
+  type: string; // 自由入力のアイテムは空文字
   amount: number;
   unit: string;
   bought: boolean;
+  manual: boolean; // 手動で追加したアイテムかどうか
+  note: string | null;
+  purchased_amount: number | null; // 実際に購入した量（未記録なら null）
+  remaining_amount: number; // まだ買う必要がある量
+  excess_amount: number; // 必要量を超えて購入した量（残りもの）
+  version: number; // 更新のたびに1つ進む版（更新時に If-Match で指定する）
+  trip_date: string; // 買いに行く日 (YYYY-MM-DD)
+  first_use_date: string | null; // 献立で最初に使う日（手動のアイテムは null）
+}
+
+/**
+ * 買い物リストのアイテムのまとめて更新API (PATCH /api/ingredient-list/{shopping_plan_id}/items) の型
+ */
+export interface BatchUpdateShoppingItemsRequest {
+  items: {
+    id: string;
+    bought?: boolean;
+    amount?: number;
+    purchased_amount?: number;
+    version?: number; // 指定した場合は、アイテムの版が一致するときだけ更新する
+  }[];
+}
+
+export interface BatchUpdateShoppingItemsResponse {
+  plan_version: number; // 更新後の計画の版
+  results: ShoppingItemResult[];
+}
+
+export interface ShoppingItemResult {
+  item_id: string;
+  status: "updated" | "rejected" | "skipped";
+  error: string | null;
+  item: Ingredient | null; // 更新後のアイテム（更新した場合のみ）
+}
+
+/**
+ * まとめて更新できなかった場合 (code が shopping_items_rejected) のエラーレスポンスの型
+ */
+export interface ShoppingItemsRejectedProblem extends Problem {
+  plan_version: number; // 現在の計画の版
+  results: ShoppingItemResult[];
+}
+
+/**
+ * レシート記録API (POST /api/plans/{shopping_plan_id}/receipts) のレスポンスの型
+ */
+export interface Receipt {
+  id: string;
+  plan_id: string;
+  store_name: string | null;
+  purchased_at: string; // ISO 8601
+  total: number; // 支払った金額の合計（円）
+  lines: {
+    id: string;
+    name: string; // レシートの品名
+    ingredient_id: string | null;
+    ingredient_name: string | null;
+    shopping_item_id: string | null; // 購入済みにしたアイテム
+    quantity: number | null;
+    unit: string | null;
+    price: number; // 支払った金額（円、値引き後）
+    unit_price: number | null; // 食材の単位あたりの価格
+  }[];
+  created_at: string;
+  bought_items?: Ingredient[]; // 記録したときに購入済みにしたアイテム
+  unparsed_lines?: string[]; // 貼り付けたテキストのうち読み取れなかった行
+}
+
+/**
+ * 計画の支出取得API (GET /api/plans/{shopping_plan_id}/spend) のレスポンスの型
+ */
+export interface PlanSpend {
+  plan_id: string;
+  total: number;
+  receipt_count: number;
+  by_type: SpendByType[]; // 食材分類ごとの支出（多い順）
+  by_ingredient: {
+    ingredient_id: string;
+    name: string;
+    type: string;
+    unit: string;
+    total: number;
+    quantity: number | null;
+    average_unit_price: number | null; // 単位あたりの平均価格
+  }[];
+}
+
+export interface SpendByType {
+  name: string; // 食材分類名（食材でない品物は「その他」）
+  total: number;
+}
+
+/**
+ * 月ごとの支出取得API (GET /api/spend/monthly) のレスポンスの要素の型
+ */
+export interface MonthlySpend {
+  month: string; // YYYY-MM
+  total: number;
+  receipt_count: number;
+  plan_count: number;
+  by_type: SpendByType[];
+}
+
+/**
+ * 計画の一覧API (GET /api/plans) の計画の要素の型
+ */
+export interface PlanSummary {
+  id: string;
+  period_start_at: string; // YYYY-MM-DD
+  first_meal_date: string | null;
+  last_meal_date: string | null;
+  version: number;
+  archived_at: string | null; // アーカイブした日時（アーカイブしていない場合は null）
+  meal_count: number;
+  item_count: number;
+  bought_count: number;
+  progress: number; // 購入済みのアイテムの割合（0〜1）
+  created_at: string;
+  updated_at: string;
+}
+
+/**
+ * 計画の一覧API (GET /api/plans) のレスポンスの型
+ */
+export interface PlanListResponse {
+  plans: PlanSummary[];
+  next_cursor: string | null; // 次のページのカーソル（最後のページでは null）
+}
+
+export interface PlanListQuery {
+  from?: string; // YYYY-MM-DD
+  to?: string; // YYYY-MM-DD
+  status?: "active" | "archived" | "all"; // 省略時は active（アーカイブしていない計画）
+  cursor?: string;
+  limit?: number;
+}
+
+/**
+ * 計画の情報取得API (GET /api/plans/{shopping_plan_id}) のレスポンスの型
+ */
+export interface PlanDetail extends PlanSummary {
+  trips: string[]; // 買いに行く日（YYYY-MM-DD）
+}
+
+/**
+ * 残りもの取得API (GET /api/leftovers/{shopping_plan_id}) のレスポンスの要素の型
+ */
+export interface Leftover {
+  item_id: string;
+  name: string;
+  type: string;
+  amount: number; // 必要量を超えて購入した量
+  unit: string;
+  purchased_at: string | null;
+  shelf_life_days_unopened: number | null;
+  shelf_life_days_opened: number | null;
+  use_by: string | null; // 使い切る目安の日付 (YYYY-MM-DD)
+}
+
+/**
+ * 計画の変更の配信 (GET /api/plans/{shopping_plan_id}/events) のイベントの型
+ */
+export interface PlanEvent {
+  seq: number;
+  type: "items.updated" | "items.added" | "items.deleted" | "plan.deleted" | "resync";
+  plan_id: string;
+  plan_version?: number; // 変更後の計画の版
+  items?: Ingredient[]; // 追加・更新したアイテム
+  deleted_item_ids?: string[]; // 削除したアイテムのID
+  occurred_at: string;
+}
+
+/**
+ * 共有リンクの範囲 (READ_ONLY: 閲覧のみ, CHECK_OFF: 閲覧と購入済みのチェック)
+ */
+export type ShareScope = "READ_ONLY" | "CHECK_OFF";
+
+/**
+ * 共有リンク発行API (POST /api/plans/{shopping_plan_id}/share-tokens) のレスポンスの型
+ */
+export interface ShareToken {
+  id: string;
+  token?: string; // 発行時のみ
+  path?: string; // 共有リンクのAPIのパス（発行時のみ）
+  scope: ShareScope;
+  expires_at: string;
+  revoked_at: string | null;
+  active: boolean;
+  created_at: string;
+}
+
+/**
+ * まとめた買い物リスト取得API (GET /api/merged-ingredient-list) の行の型
+ */
+export interface MergedShoppingLine {
+  key: string; // 行のキー（購入済みにするときに指定する）
+  name: string;
+  type: string;
+  amount: number; // 計画をまたいで足し合わせた必要量
+  unit: string;
+  bought: boolean; // すべての計画のアイテムが購入済みかどうか
+  note: string | null;
+  purchased_amount: number | null;
+  remaining_amount: number;
+  excess_amount: number;
+  sources: {
+    plan_id: string;
+    item_id: string;
+    amount: number;
+    bought: boolean;
+    purchased_amount: number | null;
+    manual: boolean;
+    version: number;
+    trip_date: string; // 元の計画でこのアイテムを買いに行く日
+  }[];
+}
+
+export interface MergedIngredientListResponse {
+  plan_ids: string[];
+  layout_id: string | null;
+  lines: MergedShoppingLine[];
+  sections: {
+    name: string;
+    lines: MergedShoppingLine[];
+  }[];
 }
 
 // --- API Request Types ---
 
+/**
+ * 買い物リストへのアイテム追加API (POST /api/ingredient-list/{shopping_plan_id}/items) のリクエストBodyの型
+ */
+export interface AddShoppingItemRequest {
+  name: string; // カタログの食材名（別名も可）または自由入力の名前
+  amount: number;
+  unit?: string;
+  note?: string;
+  trip_date?: string; // 買いに行く日 (YYYY-MM-DD)。省略時は最初の買い物の日
+}
+
+/**
+ * 共有リンク発行API (POST /api/plans/{shopping_plan_id}/share-tokens) のリクエストBodyの型
+ */
+export interface CreateShareTokenRequest {
+  scope: ShareScope;
+  expires_at?: string; // 省略した場合は7日後
+}
+
+/**
+ * まとめた買い物リストの購入済みチェックAPI (PATCH /api/merged-ingredient-list/lines) のリクエストBodyの型
+ */
+export interface CheckOffMergedLinesRequest {
+  plan_ids: string[];
+  lines: {
+    key: string;
+    bought: boolean;
+  }[];
+}
+
+/**
+ * レシート記録API (POST /api/plans/{shopping_plan_id}/receipts) のリクエストBodyの型
+ */
+export interface RecordReceiptRequest {
+  store_name?: string;
+  purchased_at?: string; // RFC 3339。省略時は現在時刻
+  lines?: {
+    name: string;
+    price: number; // 支払った金額（円、値引き後）
+    quantity?: number;
+    unit?: string;
+    item_id?: string; // 購入済みにするアイテム。省略時は品名から探す
+  }[];
+  text?: string; // レシートから貼り付けたテキスト（1行に1品）
+}
+
 /**
  * 買い物計画作成API (POST /api/create-new-plan) のリクエストBodyの型
  */
@@ -47,6 +350,9 @@ export interface CreateShoppingPlanRequest {
     date_offset: number;
     meal_period: MealPeriod;
   }[];
+  avoid_ingredients?: string[]; // 使いたくない食材
+  on_hand_ingredients?: string[]; // 手持ちの食材
+  trip_offsets?: number[]; // 買い物に行く日（何日後か）。省略時は食材の日持ちから決める
 }
 
 /**
@@ -66,6 +372,7 @@ export interface ShoppingPlanResponse {
   shopping_plan_id: string;
   meals: Meal[];
   ingredients: Ingredient[];
+  trips: string[]; // 買い物に行く日（早い順）
 }
 
 /**
@@ -75,9 +382,100 @@ export interface MenuListResponse {
   meals: Meal[];
 }
 
+/**
+ * メニュー取得API (GET /api/menus/{menu_id}) のレスポンスの型
+ */
+export interface MenuDetailResponse {
+  id: string; // UUID
+  version: number; // 最新の版
+  name: string;
+  servings: number;
+  steps: string[];
+  tips: string | null;
+  source_url: string | null;
+  ingredients: MenuIngredient[];
+}
+
 /**
  * 買い物リスト取得API (GET /api/ingredient-list/{shopping_plan_id}) のレスポンスの型
+ * ingredients と sections は、どちらも店内を歩く順（layout_id 省略時は食材分類名の順）に並ぶ
  */
 export interface IngredientListResponse {
+  layout_id: string | null; // 並び順に使った店舗レイアウト
+  version: number; // 計画の版（買い物リストが変わるたびに1つ進む）
+  trip_date: string | null; // 絞り込んだ買いに行く日
+  trips: string[]; // 計画の買い物に行く日（早い順）
   ingredients: Ingredient[];
-}
\ No newline at end of file
+  sections: {
+    name: string; // 売り場名（layout_id 省略時は食材分類名）
+    ingredients: Ingredient[];
+  }[];
+}
+
+/**
+ * 店舗レイアウトAPI (/api/store-layouts) の型
+ */
+export interface StoreLayout {
+  id: string; // UUID
+  name: string;
+  sections: {
+    name: string;
+    ingredient_types: string[];
+    ingredients: string[];
+  }[];
+}
+/**
+ * 食材検索API (GET /api/ingredients/search) の結果1件の型
+ */
+export interface IngredientSearchResult {
+  id: string; // UUID
+  name: string;
+  type: string;
+  unit: string;
+  aliases: string[];
+  matched_name: string; // 一致した食材名または別名
+  score: number; // 一致度 (0〜1)
+}
+
+/**
+ * 食材検索API (GET /api/ingredients/search) のレスポンスの型
+ */
+export interface IngredientSearchResponse {
+  ingredients: IngredientSearchResult[];
+}
+
+/**
+ * 作れるメニュー検索API (POST /api/menus/cookable) の結果1件の型
+ */
+export interface CookableMenu {
+  menu_id: string; // UUID
+  menu_name: string;
+  coverage: number; // 手持ちの食材で賄える割合 (0〜1)
+  have: string[];
+  missing: {
+    name: string;
+    amount: number; // 必要な量
+    shortage: number; // 不足している量
+    unit: string;
+  }[];
+}
+
+/**
+ * 作れるメニュー検索API (POST /api/menus/cookable) のレスポンスの型
+ */
+export interface CookableMenusResponse {
+  menus: CookableMenu[];
+  unknown: string[];
+}
+
+/**
+ * APIのエラーレスポンス (application/problem+json) の型
+ */
+export interface Problem {
+  type: string;
+  title: string;
+  status: number;
+  detail: string;
+  instance: string;
+  code: string; // エラーを識別する文字列 (plan_not_found, version_mismatch など)
+}